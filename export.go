@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/dataset"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/majsoul"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/mjai"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/record"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/tenhou"
+)
+
+// export runs the export command: game logs in, training samples out.
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("o", "samples", "directory to write the .npy shards to")
+	shard := fs.Int("shard", dataset.DefaultShardSize, "samples per shard")
+	format := fs.String("format", "auto", "log format: auto, events, tenhou, mjlog, majsoul, mjai or record")
+	seats := fs.String("seats", "0123", "seats whose decisions are exported")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return errors.New("usage: export [flags] LOG...")
+	}
+	var keep [4]bool
+	for _, c := range *seats {
+		if c < '0' || c > '3' {
+			return fmt.Errorf("invalid seat %q in -seats", c)
+		}
+		keep[c-'0'] = true
+	}
+
+	w, err := dataset.NewWriter(*out, *shard)
+	if err != nil {
+		return err
+	}
+	games, samples := 0, 0
+	for _, path := range fs.Args() {
+		logs, err := loadGames(path, *format)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for i, events := range logs {
+			ss, err := dataset.Extract(events)
+			if err != nil {
+				return fmt.Errorf("%s: game %d: %w", path, i, err)
+			}
+			for j := range ss {
+				if !keep[ss[j].Labels[dataset.LabelSeat]] {
+					continue
+				}
+				if err := w.Write(&ss[j]); err != nil {
+					return err
+				}
+				samples++
+			}
+			games++
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d samples from %d games in %d shards\n", samples, games, w.Shards())
+	return nil
+}
+
+// loadGames reads the games in a log. The auto format goes by the file's
+// extension, and by content where extensions are shared.
+func loadGames(path, format string) ([][]engine.Event, error) {
+	one := func(events []engine.Event, err error) ([][]engine.Event, error) {
+		if err != nil {
+			return nil, err
+		}
+		return [][]engine.Event{events}, nil
+	}
+	switch format {
+	case "events":
+		return one(engine.LoadEventLog(path))
+	case "tenhou":
+		return one(tenhou.LoadJSON(path))
+	case "mjlog":
+		return one(tenhou.LoadMJLog(path))
+	case "majsoul":
+		return one(majsoul.LoadPaipu(path))
+	case "mjai":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return one(mjai.ReadLog(f))
+	case "record":
+		return loadRecord(path)
+	case "auto":
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("MJRC")) {
+		return loadRecord(path)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl":
+		if games, err := one(engine.LoadEventLog(path)); err == nil {
+			return games, nil
+		}
+		return loadGames(path, "mjai")
+	case ".json":
+		if games, err := one(tenhou.LoadJSON(path)); err == nil {
+			return games, nil
+		}
+		return loadGames(path, "majsoul")
+	case ".xml", ".mjlog":
+		return loadGames(path, "mjlog")
+	}
+	return nil, fmt.Errorf("cannot tell the log format; use -format")
+}
+
+func loadRecord(path string) ([][]engine.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := record.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var games [][]engine.Event
+	for {
+		events, err := r.ReadGame()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, events)
+	}
+}
@@ -1,4 +1,9 @@
 module github.com/KevinHaeusler/go-mahjong-engine
 
-go 1.26
+go 1.26.0
 
+require (
+	github.com/BurntSushi/toml v1.6.0
+	golang.org/x/image v0.46.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,311 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Reason is one piece of evidence about how dangerous a tile is.
+type Reason uint8
+
+const (
+	// ReasonGenbutsu: the opponent discarded the kind, or let it pass
+	// after declaring riichi, so they cannot ron on it.
+	ReasonGenbutsu Reason = iota
+	// ReasonSuji: every two-sided wait on the tile is ruled out by
+	// genbutsu three ranks away.
+	ReasonSuji
+	// ReasonHalfSuji: one of the two two-sided waits on a 4, 5 or 6 is
+	// ruled out.
+	ReasonHalfSuji
+	// ReasonNoChance: every two-sided wait on the tile needs a kind whose
+	// four copies are all in view (kabe).
+	ReasonNoChance
+	// ReasonOneChance: as ReasonNoChance, with three copies in view.
+	ReasonOneChance
+	// ReasonEarlyCut: the opponent cut a tile within two ranks early on,
+	// before they had a reason to keep shapes around it.
+	ReasonEarlyCut
+	// ReasonRiichiTile: the tile is within two ranks of the riichi
+	// declaration tile, where waits straddling it are common.
+	ReasonRiichiTile
+	// ReasonHonorSeen: two copies of the honor are in view.
+	ReasonHonorSeen
+	// ReasonHonorLast: three copies are in view; only a single wait on the
+	// last copy remains.
+	ReasonHonorLast
+	// ReasonYakuhai: the honor would be a yaku for the opponent.
+	ReasonYakuhai
+)
+
+var reasonNames = [...]string{
+	ReasonGenbutsu:   "genbutsu",
+	ReasonSuji:       "suji",
+	ReasonHalfSuji:   "half suji",
+	ReasonNoChance:   "no chance",
+	ReasonOneChance:  "one chance",
+	ReasonEarlyCut:   "early cut nearby",
+	ReasonRiichiTile: "next to riichi tile",
+	ReasonHonorSeen:  "two seen",
+	ReasonHonorLast:  "three seen",
+	ReasonYakuhai:    "yakuhai",
+}
+
+func (r Reason) String() string {
+	if int(r) < len(reasonNames) {
+		return reasonNames[r]
+	}
+	return fmt.Sprintf("Reason(%d)", r)
+}
+
+// TileDanger is the estimated chance, in percent, that discarding a tile
+// deals into one opponent's riichi, with the evidence behind it.
+type TileDanger struct {
+	Tile     engine.Tile
+	Opponent int
+	Danger   float64
+	Reasons  []Reason
+}
+
+func (d TileDanger) String() string {
+	s := fmt.Sprintf("%s: %.1f%% vs seat %d", d.Tile, d.Danger, d.Opponent)
+	if len(d.Reasons) == 0 {
+		return s
+	}
+	var reasons []string
+	for _, r := range d.Reasons {
+		reasons = append(reasons, r.String())
+	}
+	return s + " (" + strings.Join(reasons, ", ") + ")"
+}
+
+// Rough deal-in rates, in percent, of a tile against a riichi by rank
+// (1 to 5; 6 to 9 mirror them), with and without suji, of a 4, 5 or 6
+// with half suji, and of an honor by how many copies are in view.
+var (
+	plainDanger    = [6]float64{0, 6, 8, 9, 12, 12}
+	sujiDanger     = [6]float64{0, 2, 3, 5, 4, 4}
+	halfSujiDanger = 8.0
+	honorDanger    = [4]float64{6, 4, 2, 0.5}
+)
+
+// Multipliers for the weaker tells.
+const (
+	noChanceFactor   = 0.4
+	oneChanceFactor  = 0.7
+	earlyCutFactor   = 0.8
+	riichiTileFactor = 1.3
+	yakuhaiFactor    = 1.5
+)
+
+// earlyDiscards is how many of an opponent's first discards count as
+// early cuts.
+const earlyDiscards = 6
+
+// Danger rates every tile kind, indexed by kind, as a discard from seat
+// against opp. Only what seat can see is used: the rivers, the melds,
+// the dora indicators and its own hand.
+func Danger(r *engine.Round, seat, opp int) ([]TileDanger, error) {
+	if opp == seat || opp < 0 || opp > 3 {
+		return nil, fmt.Errorf("invalid opponent %d for seat %d", opp, seat)
+	}
+	hand, seen, err := Visible(r, seat)
+	if err != nil {
+		return nil, err
+	}
+	var visible [engine.NumKinds]int
+	for i := range visible {
+		visible[i] = int(hand.Kinds[i] + seen.Kinds[i])
+	}
+	safe := genbutsu(r, opp)
+
+	var early [engine.NumKinds]bool
+	riichiTile := -1
+	for i, d := range r.Discards[opp] {
+		if d.Riichi {
+			riichiTile = d.Tile.Index()
+		} else if i < earlyDiscards && riichiTile < 0 {
+			early[d.Tile.Index()] = true
+		}
+	}
+	seatWind := engine.Wind((opp - r.Dealer + 4) % 4).Tile().Index()
+	roundWind := r.Wind.Tile().Index()
+	yakuhai := func(i int) bool {
+		return i >= 31 || i == seatWind || i == roundWind // 31-33 are the dragons
+	}
+
+	out := make([]TileDanger, engine.NumKinds)
+	for i := range out {
+		d := TileDanger{Tile: engine.TileAt(i), Opponent: opp}
+		add := func(reason Reason) { d.Reasons = append(d.Reasons, reason) }
+		switch {
+		case safe[i]:
+			add(ReasonGenbutsu)
+		case i >= 27:
+			n := min(visible[i], 3)
+			d.Danger = honorDanger[n]
+			switch n {
+			case 2:
+				add(ReasonHonorSeen)
+			case 3:
+				add(ReasonHonorLast)
+			}
+			if yakuhai(i) {
+				d.Danger *= yakuhaiFactor
+				add(ReasonYakuhai)
+			}
+		default:
+			d.Danger = numberDanger(i, &safe, &visible, add)
+			near := func(j int) bool { return j >= 0 && j < 27 && j/9 == i/9 && j-i <= 2 && i-j <= 2 }
+			for j := range early {
+				if early[j] && near(j) {
+					d.Danger *= earlyCutFactor
+					add(ReasonEarlyCut)
+					break
+				}
+			}
+			if riichiTile != i && near(riichiTile) {
+				d.Danger *= riichiTileFactor
+				add(ReasonRiichiTile)
+			}
+		}
+		out[i] = d
+	}
+	return out, nil
+}
+
+// numberDanger rates a suited kind i from suji and kabe.
+func numberDanger(i int, safe *[engine.NumKinds]bool, visible *[engine.NumKinds]int, add func(Reason)) float64 {
+	rank := i%9 + 1
+	level := min(rank, 10-rank)
+
+	// The two-sided shapes that wait on i: below (i-2, i-1) with the
+	// other wait at i-3, and above (i+1, i+2) with the other at i+3.
+	type shape struct{ a, b, other int }
+	var shapes []shape
+	if rank >= 4 {
+		shapes = append(shapes, shape{i - 2, i - 1, i - 3})
+	}
+	if rank <= 6 {
+		shapes = append(shapes, shape{i + 1, i + 2, i + 3})
+	}
+
+	covered, blocked, thin := 0, 0, 0
+	for _, s := range shapes {
+		if safe[s.other] {
+			covered++
+		}
+		switch max(visible[s.a], visible[s.b]) {
+		case 4:
+			blocked++
+		case 3:
+			thin++
+		}
+	}
+
+	danger := plainDanger[level]
+	switch {
+	case covered == len(shapes):
+		danger = sujiDanger[level]
+		add(ReasonSuji)
+	case covered > 0:
+		danger = halfSujiDanger
+		add(ReasonHalfSuji)
+	}
+	switch {
+	case blocked == len(shapes):
+		danger *= noChanceFactor
+		add(ReasonNoChance)
+	case blocked+thin == len(shapes):
+		danger *= oneChanceFactor
+		add(ReasonOneChance)
+	}
+	return danger
+}
+
+// genbutsu marks the kinds opp cannot ron on: those in their own river
+// and, once they are in riichi, those any seat discarded after their
+// riichi tile.
+func genbutsu(r *engine.Round, opp int) [engine.NumKinds]bool {
+	var safe [engine.NumKinds]bool
+	riichiSeq := -1
+	for _, d := range r.Discards[opp] {
+		safe[d.Tile.Index()] = true
+		if d.Riichi {
+			riichiSeq = d.Seq
+		}
+	}
+	if riichiSeq < 0 {
+		return safe
+	}
+	for _, pond := range r.Discards {
+		for _, d := range pond {
+			if d.Seq > riichiSeq {
+				safe[d.Tile.Index()] = true
+			}
+		}
+	}
+	return safe
+}
+
+// DangerRanking rates each distinct tile in seat's hand against the
+// opponents in riichi, or every opponent when nobody is, keeping the
+// worst opponent for each tile. The most dangerous tile comes first.
+func DangerRanking(r *engine.Round, seat int) ([]TileDanger, error) {
+	if seat < 0 || seat > 3 {
+		return nil, fmt.Errorf("invalid seat %d", seat)
+	}
+	var threats []int
+	for opp := range 4 {
+		if opp != seat && r.Riichi[opp] {
+			threats = append(threats, opp)
+		}
+	}
+	if len(threats) == 0 {
+		for opp := range 4 {
+			if opp != seat {
+				threats = append(threats, opp)
+			}
+		}
+	}
+
+	var worst []TileDanger
+	for _, opp := range threats {
+		dangers, err := Danger(r, seat, opp)
+		if err != nil {
+			return nil, err
+		}
+		if worst == nil {
+			worst = dangers
+			continue
+		}
+		for i, d := range dangers {
+			if d.Danger > worst[i].Danger {
+				worst[i] = d
+			}
+		}
+	}
+
+	var out []TileDanger
+	held := map[engine.Tile]bool{}
+	for _, t := range r.Hands[seat] {
+		if held[t] {
+			continue
+		}
+		held[t] = true
+		d := worst[t.Index()]
+		d.Tile = t
+		out = append(out, d)
+	}
+	slices.SortStableFunc(out, func(a, b TileDanger) int {
+		return cmp.Or(
+			cmp.Compare(b.Danger, a.Danger),
+			cmp.Compare(a.Tile.Index(), b.Tile.Index()),
+		)
+	})
+	return out, nil
+}
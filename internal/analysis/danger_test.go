@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func dangerRound(t *testing.T) *engine.Round {
+	t.Helper()
+	tile := func(s string) engine.Tile {
+		tt, err := engine.ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tt
+	}
+	hand, err := engine.ParseHandCompact("1457m588889p19s26z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &engine.Round{Dealer: 0, DoraIndicators: []engine.Tile{tile("3z")}, TilesLeft: 50}
+	r.Hands[0] = hand
+	r.Riichi[1] = true
+	r.Discards[1] = []engine.Discard{
+		{Tile: tile("4m"), Seq: 0},
+		{Tile: tile("1z"), Seq: 1},
+		{Tile: tile("7p"), Seq: 2, Riichi: true},
+	}
+	r.Discards[2] = []engine.Discard{{Tile: tile("9s"), Seq: 3}}
+	return r
+}
+
+func TestDanger(t *testing.T) {
+	r := dangerRound(t)
+	dangers, err := Danger(r, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tile    string
+		danger  float64
+		reasons []Reason
+	}{
+		{"1m", 2, []Reason{ReasonSuji}},
+		{"4m", 0, []Reason{ReasonGenbutsu}},
+		{"5m", 9.6, []Reason{ReasonEarlyCut}},
+		{"7m", 5, []Reason{ReasonSuji}},
+		{"5p", 15.6, []Reason{ReasonRiichiTile}},
+		{"9p", 3.12, []Reason{ReasonNoChance, ReasonRiichiTile}},
+		{"1s", 6, nil},
+		{"9s", 0, []Reason{ReasonGenbutsu}}, // passed after the riichi
+		{"1z", 0, []Reason{ReasonGenbutsu}},
+		{"2z", 6, []Reason{ReasonYakuhai}}, // the opponent's seat wind
+		{"4z", 6, nil},
+		{"6z", 6, []Reason{ReasonYakuhai}},
+	}
+	for _, tt := range tests {
+		tile, _ := engine.ParseTile(tt.tile)
+		d := dangers[tile.Index()]
+		if math.Abs(d.Danger-tt.danger) > 1e-9 || !slices.Equal(d.Reasons, tt.reasons) {
+			t.Errorf("%s: %v, want %.2f %v", tt.tile, d, tt.danger, tt.reasons)
+		}
+	}
+
+	if _, err := Danger(r, 0, 0); err == nil {
+		t.Error("Danger against the seat itself: no error")
+	}
+}
+
+func TestDangerHonorsSeen(t *testing.T) {
+	r := dangerRound(t)
+	north, _ := engine.ParseTile("4z")
+	r.Discards[3] = []engine.Discard{{Tile: north, Seq: 4}, {Tile: north, Seq: 5}}
+	r.Melds[2] = []engine.Meld{{Kind: engine.MeldPon, Tiles: []engine.Tile{north, north, north}, Called: north, From: 3}}
+	r.Discards[3][1].Called = true
+	dangers, err := Danger(r, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One in seat 3's river and three in the pon: four seen, but 4z is
+	// seat 3's own discard, not genbutsu against seat 2.
+	if d := dangers[north.Index()]; d.Danger != 0.5 || !slices.Equal(d.Reasons, []Reason{ReasonHonorLast}) {
+		t.Errorf("4z = %v", d)
+	}
+}
+
+func TestDangerRanking(t *testing.T) {
+	ranking, err := DangerRanking(dangerRound(t), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range ranking {
+		if d.Opponent != 1 {
+			t.Errorf("%s rated against seat %d, want the riichi seat 1", d.Tile, d.Opponent)
+		}
+		got = append(got, d.Tile.String())
+	}
+	want := []string{"5p", "8p", "5m", "1s", "2z", "6z", "7m", "9p", "1m", "4m", "9s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ranking = %v, want %v", got, want)
+	}
+
+	for _, seat := range []int{-1, 4, 7} {
+		if _, err := DangerRanking(dangerRound(t), seat); err == nil {
+			t.Errorf("DangerRanking for seat %d: no error", seat)
+		}
+	}
+}
+
+func TestDangerReplay(t *testing.T) {
+	events, err := engine.LoadEventLog("../engine/testdata/events.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := engine.Replay(events, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Round.Riichi[3] {
+		t.Fatal("seat 3 is not in riichi")
+	}
+	dangers, err := Danger(g.Round, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	riichiTile, _ := engine.ParseTile("8m")
+	if d := dangers[riichiTile.Index()]; d.Danger != 0 || !slices.Equal(d.Reasons, []Reason{ReasonGenbutsu}) {
+		t.Errorf("riichi tile 8m = %v", d)
+	}
+}
@@ -0,0 +1,429 @@
+package analysis
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/score"
+)
+
+// DefaultRollouts is the number of rollouts per discard when EVConfig
+// leaves it at zero.
+const DefaultRollouts = 500
+
+// EVConfig sets the budget of EvaluateDiscards.
+type EVConfig struct {
+	Rollouts int    // simulated rest-of-hands per discard; 0 means DefaultRollouts
+	Workers  int    // goroutines running rollouts; 0 means GOMAXPROCS
+	Seed     uint64 // the same seed gives the same estimates for any Workers
+}
+
+// Estimate is a sample mean with its 95% confidence interval.
+type Estimate struct {
+	Mean, Low, High float64
+}
+
+func (e Estimate) String() string {
+	return fmt.Sprintf("%.3g [%.3g, %.3g]", e.Mean, e.Low, e.High)
+}
+
+// DiscardEV is the simulated outcome of one discard. Points leave out
+// honba, riichi sticks and noten payments.
+type DiscardEV struct {
+	Discard engine.Tile
+	WinRate Estimate // share of rollouts the seat wins
+	Value   Estimate // points per win
+	DealIn  Estimate // share of rollouts the seat deals into a ron
+	EV      Estimate // points won less points paid, per rollout
+}
+
+func (d DiscardEV) String() string {
+	return fmt.Sprintf("%s: win %s, value %s, deal-in %s, ev %s", d.Discard, d.WinRate, d.Value, d.DealIn, d.EV)
+}
+
+// EvaluateDiscards plays out the rest of the round after every distinct
+// discard of a seat that has just drawn, and sorts the discards by EV,
+// best first.
+//
+// Each rollout deals the tiles the seat cannot see at random: opponents
+// get concealed hands (a riichi opponent's hand is traded with the wall
+// until it is in tenpai), the rest becomes the live wall and the ura
+// indicators. Every seat then draws and discards the tile that keeps its
+// shanten lowest, declaring riichi in closed tenpai and winning by tsumo
+// or ron when the hand has a yaku and is not furiten on its own river.
+// Nobody calls.
+func EvaluateDiscards(r *engine.Round, seat int, rules engine.Rules, cfg EVConfig) ([]DiscardEV, error) {
+	hand, seen, err := Visible(r, seat)
+	if err != nil {
+		return nil, err
+	}
+	if hand.Len()%3 != 2 {
+		return nil, fmt.Errorf("seat %d holds %d tiles, want one drawn", seat, hand.Len())
+	}
+	pool, err := unseen(&hand, &seen, rules)
+	if err != nil {
+		return nil, err
+	}
+	rollouts := cmp.Or(cfg.Rollouts, DefaultRollouts)
+	workers := cmp.Or(cfg.Workers, runtime.GOMAXPROCS(0))
+
+	var discards []engine.Tile
+	for _, o := range Discards(&hand, &seen) {
+		discards = append(discards, o.Discard)
+	}
+	outcomes := make([][]outcome, len(discards))
+	for d := range outcomes {
+		outcomes[d] = make([]outcome, rollouts)
+	}
+	errs := make([]error, len(discards)*rollouts)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for job := range jobs {
+				d, i := job/rollouts, job%rollouts
+				rng := rand.New(rand.NewPCG(cfg.Seed, uint64(d)<<32|uint64(i)))
+				outcomes[d][i], errs[job] = rollout(r, seat, rules, &hand, pool, discards[d], rng)
+			}
+		})
+	}
+	for job := range len(discards) * rollouts {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+	for job, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("rollout %d of %s: %w", job%rollouts, discards[job/rollouts], err)
+		}
+	}
+
+	out := make([]DiscardEV, len(discards))
+	for d, outs := range outcomes {
+		out[d] = summarize(discards[d], outs)
+	}
+	slices.SortStableFunc(out, func(a, b DiscardEV) int {
+		return cmp.Compare(b.EV.Mean, a.EV.Mean)
+	})
+	return out, nil
+}
+
+// unseen returns the tiles of a full wall that are neither in hand nor
+// seen, red fives kept apart from plain ones.
+func unseen(hand, seen *engine.TileCounts, rules engine.Rules) ([]engine.Tile, error) {
+	wall, err := engine.BuildWall(rules)
+	if err != nil {
+		return nil, err
+	}
+	known := *hand
+	for _, t := range seen.Tiles() {
+		if err := known.Add(t); err != nil {
+			return nil, err
+		}
+	}
+	var pool []engine.Tile
+	for _, t := range wall {
+		if known.Remove(t) != nil {
+			pool = append(pool, t)
+		}
+	}
+	if n := known.Len(); n > 0 {
+		return nil, fmt.Errorf("%d tiles in view are not in the wall", n)
+	}
+	return pool, nil
+}
+
+// outcome is the result of one rollout for the evaluated seat.
+type outcome struct {
+	win, dealIn bool
+	value       int // points of the seat's win
+	delta       int // points won less points paid
+}
+
+func summarize(discard engine.Tile, outs []outcome) DiscardEV {
+	var wins, dealIns int
+	var values, deltas []float64
+	for _, o := range outs {
+		if o.win {
+			wins++
+			values = append(values, float64(o.value))
+		}
+		if o.dealIn {
+			dealIns++
+		}
+		deltas = append(deltas, float64(o.delta))
+	}
+	return DiscardEV{
+		Discard: discard,
+		WinRate: rate(wins, len(outs)),
+		Value:   mean(values),
+		DealIn:  rate(dealIns, len(outs)),
+		EV:      mean(deltas),
+	}
+}
+
+// z95 is the normal quantile of a two-sided 95% interval.
+const z95 = 1.96
+
+// rate estimates a proportion with the normal approximation, clamped to
+// [0, 1].
+func rate(k, n int) Estimate {
+	if n == 0 {
+		return Estimate{}
+	}
+	p := float64(k) / float64(n)
+	half := z95 * math.Sqrt(p*(1-p)/float64(n))
+	return Estimate{Mean: p, Low: max(0, p-half), High: min(1, p+half)}
+}
+
+// mean estimates the mean of xs from the sample standard deviation.
+func mean(xs []float64) Estimate {
+	if len(xs) == 0 {
+		return Estimate{}
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	m := sum / float64(len(xs))
+	if len(xs) == 1 {
+		return Estimate{Mean: m, Low: m, High: m}
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	half := z95 * math.Sqrt(ss/float64(len(xs)-1)/float64(len(xs)))
+	return Estimate{Mean: m, Low: m - half, High: m + half}
+}
+
+// simSeat is one player's side of a rollout.
+type simSeat struct {
+	hand   engine.TileCounts // concealed tiles
+	melds  []engine.Meld
+	river  [engine.NumKinds]bool // kinds discarded, for furiten
+	riichi bool
+	closed bool
+}
+
+type sim struct {
+	r     *engine.Round
+	rules engine.Rules
+	seats [4]simSeat
+	wall  []engine.Tile // live wall, drawn from the end
+	ura   []engine.Tile
+}
+
+// riichiTrades caps the wall trades that bring a riichi opponent's
+// random hand to tenpai.
+const riichiTrades = 100
+
+// rollout plays one rest of the round after seat discards discard. The
+// tile counts cannot overflow, since hands and wall are dealt from one
+// wall, but an error is reported if they do.
+func rollout(r *engine.Round, seat int, rules engine.Rules, hand *engine.TileCounts, pool []engine.Tile, discard engine.Tile, rng *rand.Rand) (outcome, error) {
+	pool = slices.Clone(pool)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	take := func() engine.Tile {
+		t := pool[len(pool)-1]
+		pool = pool[:len(pool)-1]
+		return t
+	}
+
+	s := &sim{r: r, rules: rules}
+	for p := range 4 {
+		st := &s.seats[p]
+		st.melds = r.Melds[p]
+		st.riichi = r.Riichi[p]
+		st.closed = !slices.ContainsFunc(st.melds, engine.Meld.IsOpen)
+		for _, d := range r.Discards[p] {
+			st.river[d.Tile.Index()] = true
+		}
+		if p == seat {
+			st.hand = *hand
+			continue
+		}
+		for range 13 - 3*len(st.melds) {
+			if err := st.hand.Add(take()); err != nil {
+				return outcome{}, err
+			}
+		}
+		for range riichiTrades {
+			if !st.riichi || Shanten(&st.hand) <= Tenpai {
+				break
+			}
+			if err := st.hand.Add(take()); err != nil {
+				return outcome{}, err
+			}
+			t, err := throw(&st.hand)
+			if err != nil {
+				return outcome{}, err
+			}
+			pool = append(pool, t)
+			i := rng.IntN(len(pool))
+			pool[i], pool[len(pool)-1] = pool[len(pool)-1], pool[i]
+		}
+	}
+	for range r.DoraIndicators {
+		if len(pool) > 0 {
+			s.ura = append(s.ura, take())
+		}
+	}
+	s.wall = pool[max(0, len(pool)-r.TilesLeft):]
+
+	var o outcome
+	settle := func(winner, discarder int, res score.Result) {
+		switch {
+		case winner == seat:
+			o.win, o.value, o.delta = true, res.Points, res.Points
+		case discarder == seat:
+			o.dealIn, o.delta = true, -res.Ron
+		case discarder < 0 && s.dealer(seat):
+			o.delta = -res.TsumoDealer
+		case discarder < 0:
+			o.delta = -res.TsumoOther
+		}
+	}
+
+	turn := seat
+	if err := s.seats[seat].hand.Remove(discard); err != nil {
+		return outcome{}, err
+	}
+	for {
+		if winner, res, ok := s.discard(turn, discard); ok {
+			settle(winner, turn, res)
+			return o, nil
+		}
+		if len(s.wall) == 0 {
+			return o, nil // exhaustive draw
+		}
+		turn = (turn + 1) % 4
+		drawn := s.wall[len(s.wall)-1]
+		s.wall = s.wall[:len(s.wall)-1]
+		if res, ok := s.win(turn, drawn, true); ok {
+			settle(turn, -1, res)
+			return o, nil
+		}
+		st := &s.seats[turn]
+		discard = drawn
+		if !st.riichi {
+			if err := st.hand.Add(drawn); err != nil {
+				return outcome{}, err
+			}
+			var err error
+			if discard, err = throw(&st.hand); err != nil {
+				return outcome{}, err
+			}
+		}
+	}
+}
+
+func (s *sim) dealer(p int) bool {
+	return p == s.r.Dealer
+}
+
+// discard puts a tile in p's river, declares riichi if it leaves p in
+// closed tenpai, and offers it to the other seats in turn order.
+func (s *sim) discard(p int, t engine.Tile) (winner int, res score.Result, ok bool) {
+	st := &s.seats[p]
+	st.river[t.Index()] = true
+	if !st.riichi && st.closed && len(s.wall) >= 4 && Shanten(&st.hand) == Tenpai {
+		st.riichi = true
+	}
+	for i := 1; i < 4; i++ {
+		q := (p + i) % 4
+		if res, ok := s.win(q, t, false); ok {
+			return q, res, true
+		}
+	}
+	return 0, score.Result{}, false
+}
+
+// win scores p's hand completed by t, or reports false when it is not a
+// win: incomplete, without yaku, or furiten on a ron.
+func (s *sim) win(p int, t engine.Tile, tsumo bool) (score.Result, bool) {
+	st := &s.seats[p]
+	h := st.hand
+	if h.Add(t) != nil || Shanten(&h) != Complete {
+		return score.Result{}, false
+	}
+	if !tsumo {
+		for _, w := range Accept(&st.hand, &engine.TileCounts{}).Tiles {
+			if st.river[w.Index()] {
+				return score.Result{}, false
+			}
+		}
+	}
+	hand := engine.Hand{
+		Concealed: st.hand.Tiles(),
+		Melds:     st.melds,
+		Winning:   &t,
+		Dora:      s.r.DoraIndicators,
+		Ura:       s.ura,
+		RoundWind: s.r.Wind,
+		SeatWind:  engine.Wind((p - s.r.Dealer + 4) % 4),
+		Tsumo:     tsumo,
+		Riichi:    st.riichi,
+		Haitei:    tsumo && len(s.wall) == 0,
+		Houtei:    !tsumo && len(s.wall) == 0,
+	}
+	res, err := score.Score(hand, s.rules)
+	return res, err == nil
+}
+
+// throw removes the tile pickDiscard chooses from hand and returns it.
+func throw(hand *engine.TileCounts) (engine.Tile, error) {
+	t, err := pickDiscard(hand)
+	if err != nil {
+		return 0, err
+	}
+	return t, hand.Remove(t)
+}
+
+// pickDiscard returns the discard that keeps the shanten lowest, breaking
+// ties by throwing the tile with the fewest neighbours. A plain five goes
+// before a red one.
+func pickDiscard(hand *engine.TileCounts) (engine.Tile, error) {
+	best, bestShanten, bestLinks := -1, 0, 0
+	for i, n := range hand.Kinds {
+		if n == 0 {
+			continue
+		}
+		hand.Kinds[i]--
+		sh := Shanten(hand)
+		hand.Kinds[i]++
+		l := links(hand, i)
+		if best < 0 || sh < bestShanten || sh == bestShanten && l < bestLinks {
+			best, bestShanten, bestLinks = i, sh, l
+		}
+	}
+	if best < 0 {
+		return 0, errors.New("no tile to discard")
+	}
+	t := engine.TileAt(best)
+	if best < 27 && best%9 == 4 && hand.Red[best/9] == hand.Kinds[best] {
+		t, _ = engine.NewRedFive(t.Suit())
+	}
+	return t, nil
+}
+
+// links counts the other tiles within two ranks of kind i in its suit, or
+// the other copies of an honor.
+func links(hand *engine.TileCounts, i int) int {
+	n := -1
+	if i >= 27 {
+		return int(hand.Kinds[i]) + n
+	}
+	for j := max(i-2, i/9*9); j <= min(i+2, i/9*9+8); j++ {
+		n += int(hand.Kinds[j])
+	}
+	return n
+}
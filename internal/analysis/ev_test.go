@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestEvaluateDiscards(t *testing.T) {
+	hand, err := engine.ParseHandCompact("234m456p678s55s46m1z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dora, _ := engine.ParseTile("1p")
+	r := &engine.Round{Dealer: 1, DoraIndicators: []engine.Tile{dora}, TilesLeft: 60}
+	r.Hands[0] = hand
+	for s := 1; s < 4; s++ {
+		r.Hands[s] = make([]engine.Tile, 13) // unseen; only the count matters
+	}
+
+	cfg := EVConfig{Rollouts: 200, Workers: 1, Seed: 7}
+	evs, err := EvaluateDiscards(r, 0, engine.DefaultRules(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 12 {
+		t.Fatalf("got %d discards, want 12", len(evs))
+	}
+	byTile := map[string]DiscardEV{}
+	for _, ev := range evs {
+		byTile[ev.Discard.String()] = ev
+		for _, e := range []Estimate{ev.WinRate, ev.Value, ev.DealIn, ev.EV} {
+			if e.Low > e.Mean || e.Mean > e.High {
+				t.Errorf("%s: interval %v does not hold its mean", ev.Discard, e)
+			}
+		}
+		if ev.WinRate.Low < 0 || ev.WinRate.High > 1 {
+			t.Errorf("%s: win rate %v outside [0, 1]", ev.Discard, ev.WinRate)
+		}
+	}
+	// Cutting the honor keeps a riichi on 5m; breaking the pair does not.
+	if tenpai, broken := byTile["1z"], byTile["5s"]; tenpai.WinRate.Mean <= broken.WinRate.Mean {
+		t.Errorf("win rate after 1z = %v, after 5s = %v; want 1z ahead", tenpai.WinRate, broken.WinRate)
+	}
+	if evs[0].EV.Mean < evs[len(evs)-1].EV.Mean {
+		t.Errorf("results not sorted by EV: %v", evs)
+	}
+
+	cfg.Workers = 4
+	again, err := EvaluateDiscards(r, 0, engine.DefaultRules(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(evs, again) {
+		t.Errorf("estimates depend on the worker count:\n%v\n%v", evs, again)
+	}
+}
+
+func TestEvaluateDiscardsReplay(t *testing.T) {
+	events, err := engine.LoadEventLog("../engine/testdata/events.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range events {
+		g, err := engine.Replay(events, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Round == nil || g.Round.Ended || len(g.Round.Hands[g.Round.Turn])%3 != 2 {
+			continue
+		}
+		seat := g.Round.Turn
+		evs, err := EvaluateDiscards(g.Round, seat, g.Rules, EVConfig{Rollouts: 20, Seed: 1})
+		if err != nil {
+			t.Fatalf("event %d: %v", n, err)
+		}
+		if len(evs) == 0 {
+			t.Errorf("event %d: no discards for seat %d", n, seat)
+		}
+		return
+	}
+	t.Fatal("no seat waiting to discard in the log")
+}
+
+func TestEstimates(t *testing.T) {
+	if got := rate(0, 10); got != (Estimate{}) {
+		t.Errorf("rate(0, 10) = %v", got)
+	}
+	if got := rate(5, 100); got.Mean != 0.05 || got.Low >= 0.05 || got.High <= 0.05 {
+		t.Errorf("rate(5, 100) = %v", got)
+	}
+	if got := mean([]float64{1000, 3000}); got.Mean != 2000 || got.Low >= 2000 || got.High <= 2000 {
+		t.Errorf("mean = %v", got)
+	}
+	if got := mean(nil); got != (Estimate{}) {
+		t.Errorf("mean(nil) = %v", got)
+	}
+}
+
+func TestPickDiscard_Empty(t *testing.T) {
+	if got, err := pickDiscard(&engine.TileCounts{}); err == nil {
+		t.Errorf("pickDiscard(empty) = %s, want an error", got)
+	}
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Lookahead extends a discard option with what happens over the next draw.
+type Lookahead struct {
+	Option
+
+	// NextCount is the average live count accepted after drawing one of
+	// the accepted tiles and making the best discard, weighted by the
+	// live copies of each draw. It is 0 in tenpai, where those draws win.
+	NextCount float64
+
+	// GoodTenpai is the chance, on the same weighting, that the next
+	// improving draw reaches tenpai on a good wait (see GoodWait). Only a
+	// 1-shanten hand can get there in one draw; it is 0 otherwise.
+	GoodTenpai float64
+
+	// Shape lists the kinds that raise the count accepted without
+	// lowering the shanten, and ShapeCount their live copies.
+	Shape      []engine.Tile
+	ShapeCount int
+}
+
+func (l Lookahead) String() string {
+	return fmt.Sprintf("%s, next %.1f, good tenpai %.0f%%, shape %s (%d)",
+		l.Option, l.NextCount, 100*l.GoodTenpai, engine.FormatHandCompact(l.Shape), l.ShapeCount)
+}
+
+// LookaheadDiscards is Discards with a second step: for every discard it
+// also weighs the draws that follow. Results are sorted like Discards,
+// with ties broken by NextCount and then GoodTenpai.
+func LookaheadDiscards(hand, seen *engine.TileCounts) []Lookahead {
+	opts := Discards(hand, seen)
+	out := make([]Lookahead, len(opts))
+	for i, o := range opts {
+		h, s := *hand, *seen
+		k := o.Discard.Index()
+		h.Kinds[k]--
+		s.Kinds[k]++
+		out[i] = lookahead(o, &h, &s)
+	}
+	slices.SortStableFunc(out, func(a, b Lookahead) int {
+		return cmp.Or(
+			cmp.Compare(a.Shanten, b.Shanten),
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(b.NextCount, a.NextCount),
+			cmp.Compare(b.GoodTenpai, a.GoodTenpai),
+		)
+	})
+	return out
+}
+
+// lookahead fills in the second step for a hand waiting for a draw.
+func lookahead(o Option, hand, seen *engine.TileCounts) Lookahead {
+	l := Lookahead{Option: o}
+	var weight, next, good int
+	for i := range engine.NumKinds {
+		n := live(hand, seen, i)
+		if n == 0 {
+			continue
+		}
+		h := *hand
+		h.Kinds[i]++
+		if Shanten(&h) < o.Shanten {
+			if o.Shanten == Tenpai {
+				continue // a win, nothing to look ahead to
+			}
+			best := Discards(&h, seen)[0]
+			weight += n
+			next += n * best.Count
+			if best.Shanten == Tenpai {
+				h.Kinds[best.Discard.Index()]--
+				if GoodWait(&h, best.Tiles) {
+					good += n
+				}
+			}
+			continue
+		}
+		if best := Discards(&h, seen)[0]; best.Shanten == o.Shanten && best.Count > o.Count {
+			l.Shape = append(l.Shape, engine.TileAt(i))
+			l.ShapeCount += n
+		}
+	}
+	if weight > 0 {
+		l.NextCount = float64(next) / float64(weight)
+		l.GoodTenpai = float64(good) / float64(weight)
+	}
+	return l
+}
+
+// GoodWait reports whether a tenpai hand waiting on waits has a good
+// shape: two or more kinds, other than a wait on two pairs (shanpon).
+func GoodWait(hand *engine.TileCounts, waits []engine.Tile) bool {
+	if len(waits) < 2 {
+		return false
+	}
+	if len(waits) == 2 && hand.Count(waits[0]) >= 2 && hand.Count(waits[1]) >= 2 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestLookahead(t *testing.T) {
+	// 1-shanten on 23s: 1s and 4s make tenpai on a single wait (three
+	// live), 1z and 5p make a pair and a 14s wait (eight live). Two 5p
+	// are in hand, so only two are live.
+	hand, seen := mustCounts(t, "123m456p789s23s5p1z"), mustCounts(t, "")
+	l := lookahead(Option{Acceptance: Accept(&hand, &seen)}, &hand, &seen)
+
+	if l.Shanten != 1 || l.Count != 13 {
+		t.Fatalf("acceptance = %+v", l.Acceptance)
+	}
+	if want := (4*3 + 4*3 + 3*8 + 2*8) / 13.0; math.Abs(l.NextCount-want) > 1e-9 {
+		t.Errorf("NextCount = %v, want %v", l.NextCount, want)
+	}
+	if want := 5 / 13.0; math.Abs(l.GoodTenpai-want) > 1e-9 {
+		t.Errorf("GoodTenpai = %v, want %v", l.GoodTenpai, want)
+	}
+}
+
+func TestLookaheadDiscardsTenpai(t *testing.T) {
+	hand, seen := mustCounts(t, "123m456p789s11z457s"), mustCounts(t, "")
+	ls := LookaheadDiscards(&hand, &seen)
+
+	best := ls[0]
+	if best.Discard.String() != "7s" || best.NextCount != 0 || best.GoodTenpai != 0 || len(best.Shape) != 0 {
+		t.Errorf("best = %s", best)
+	}
+	// Keeping the 57s closed wait, a 4s or 8s turns it two-sided.
+	for _, l := range ls {
+		if l.Discard.String() != "4s" {
+			continue
+		}
+		if got := engine.FormatHandCompact(l.Shape); got != "48s" || l.ShapeCount != 6 {
+			t.Errorf("discard 4s: shape %s (%d), want 48s (6)", got, l.ShapeCount)
+		}
+	}
+	for i := 1; i < len(ls); i++ {
+		a, b := ls[i-1], ls[i]
+		if a.Shanten == b.Shanten && a.Count == b.Count && a.NextCount < b.NextCount {
+			t.Errorf("ties out of order: %s before %s", a, b)
+		}
+	}
+}
+
+func TestGoodWait(t *testing.T) {
+	tests := []struct {
+		hand, waits string
+		want        bool
+	}{
+		{"23m", "14m", true},
+		{"1234m", "14m", true},
+		{"3456m", "258m", true},
+		{"13m", "2m", false},
+		{"12m", "3m", false},
+		{"11m55p", "1m5p", false},
+		{"1z", "1z", false},
+	}
+	for _, tt := range tests {
+		hand := mustCounts(t, tt.hand)
+		waits := mustCounts(t, tt.waits).Tiles()
+		if got := GoodWait(&hand, waits); got != tt.want {
+			t.Errorf("GoodWait(%s, %s) = %v, want %v", tt.hand, tt.waits, got, tt.want)
+		}
+	}
+}
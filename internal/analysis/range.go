@@ -0,0 +1,316 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/score"
+)
+
+// DefaultSamples is the number of hands InferRange draws when RangeConfig
+// leaves it at zero.
+const DefaultSamples = 1000
+
+// RangeConfig sets the budget of InferRange.
+type RangeConfig struct {
+	Samples int // consistent hands to draw; 0 means DefaultSamples
+	Seed    uint64
+}
+
+// WaitOdds is the chance that a tile kind is one of the waits.
+type WaitOdds struct {
+	Tile engine.Tile
+	P    float64
+}
+
+func (o WaitOdds) String() string {
+	return fmt.Sprintf("%s %.0f%%", o.Tile, 100*o.P)
+}
+
+// YakuOdds is the chance that a yaku is in the hand.
+type YakuOdds struct {
+	Yaku engine.Yaku
+	P    float64
+}
+
+func (o YakuOdds) String() string {
+	return fmt.Sprintf("%s %.0f%%", o.Yaku, 100*o.P)
+}
+
+// Range is what an opponent's tenpai hand is likely to be.
+type Range struct {
+	Opponent int
+	Samples  int        // consistent hands drawn
+	Waits    []WaitOdds // chance each kind is a wait they can ron on
+	Yaku     []YakuOdds // chance each yaku is in their hand, dora left out
+}
+
+// Tuning of the sampler.
+const (
+	// chiitoiShare is how often a closed hand is drawn as seven pairs.
+	chiitoiShare = 0.1
+	// connectedCutFactor weighs a hand down for each tile the opponent
+	// cut from their hand (not tsumogiri) within two ranks of a tile
+	// they are still holding, which they would rather have kept.
+	connectedCutFactor = 0.7
+	// maxTriesPerSample bounds the draws rejected per accepted hand.
+	maxTriesPerSample = 50
+)
+
+// InferRange estimates the waits and yaku of opp's hand, as seen from
+// seat, assuming opp is in tenpai.
+//
+// It draws complete hands from the tiles seat cannot see (a pair and the
+// sets opp still needs beside their melds, or seven pairs), takes one
+// tile out at random and keeps the tenpai hand when it fits what is on
+// the table: it is not furiten on opp's river, and at least one wait
+// wins with a yaku. Waits without a yaku do not count. Each kept hand is
+// then weighted down for the tiles opp cut that it would have used, which
+// is how a river full of two suits points at a flush in the third.
+func InferRange(r *engine.Round, seat, opp int, rules engine.Rules, cfg RangeConfig) (Range, error) {
+	if opp == seat || opp < 0 || opp > 3 {
+		return Range{}, fmt.Errorf("invalid opponent %d for seat %d", opp, seat)
+	}
+	hand, seen, err := Visible(r, seat)
+	if err != nil {
+		return Range{}, err
+	}
+	pool, err := unseen(&hand, &seen, rules)
+	if err != nil {
+		return Range{}, err
+	}
+	var unseenKinds [engine.NumKinds]uint8
+	for _, t := range pool {
+		unseenKinds[t.Index()]++
+	}
+
+	samples := cmp.Or(cfg.Samples, DefaultSamples)
+	rng := rand.New(rand.NewPCG(cfg.Seed, uint64(opp)))
+	sets := 4 - len(r.Melds[opp])
+	closed := !slices.ContainsFunc(r.Melds[opp], engine.Meld.IsOpen)
+	var river [engine.NumKinds]bool
+	for _, d := range r.Discards[opp] {
+		river[d.Tile.Index()] = true
+	}
+
+	var waits [engine.NumKinds]float64
+	yakuWeight := map[engine.Yaku]float64{}
+	var total float64
+	found := 0
+	for try := 0; found < samples && try < samples*maxTriesPerSample; try++ {
+		var h [engine.NumKinds]uint8
+		var ok bool
+		if sets == 4 && rng.Float64() < chiitoiShare {
+			h, ok = samplePairs(unseenKinds, rng)
+		} else {
+			h, ok = sampleComplete(unseenKinds, sets, rng)
+		}
+		if !ok {
+			continue
+		}
+		dropRandom(&h, rng)
+
+		counts := engine.TileCounts{Kinds: h}
+		tenpai := Accept(&counts, &engine.TileCounts{}).Tiles
+		if slices.ContainsFunc(tenpai, func(t engine.Tile) bool { return river[t.Index()] }) {
+			continue // furiten
+		}
+		var won []engine.Tile
+		yaku := map[engine.Yaku]bool{}
+		for _, w := range tenpai {
+			res, err := score.Score(engine.Hand{
+				Concealed: counts.Tiles(),
+				Melds:     r.Melds[opp],
+				Winning:   &w,
+				RoundWind: r.Wind,
+				SeatWind:  engine.Wind((opp - r.Dealer + 4) % 4),
+				Riichi:    r.Riichi[opp] && closed,
+			}, rules)
+			if err != nil {
+				continue
+			}
+			won = append(won, w)
+			for _, y := range res.Yaku {
+				if !y.Yaku.IsDora() {
+					yaku[y.Yaku] = true
+				}
+			}
+		}
+		if len(won) == 0 {
+			continue
+		}
+
+		found++
+		weight := riverWeight(r.Discards[opp], &h)
+		total += weight
+		for _, w := range won {
+			waits[w.Index()] += weight
+		}
+		for y := range yaku {
+			yakuWeight[y] += weight
+		}
+	}
+	if found == 0 {
+		return Range{}, fmt.Errorf("no tenpai hand for seat %d fits the table", opp)
+	}
+
+	rg := Range{Opponent: opp, Samples: found}
+	for i, w := range waits {
+		if w > 0 {
+			rg.Waits = append(rg.Waits, WaitOdds{engine.TileAt(i), w / total})
+		}
+	}
+	for y, w := range yakuWeight {
+		rg.Yaku = append(rg.Yaku, YakuOdds{y, w / total})
+	}
+	slices.SortStableFunc(rg.Waits, func(a, b WaitOdds) int {
+		return cmp.Compare(b.P, a.P)
+	})
+	slices.SortFunc(rg.Yaku, func(a, b YakuOdds) int {
+		return cmp.Or(cmp.Compare(b.P, a.P), cmp.Compare(a.Yaku, b.Yaku))
+	})
+	return rg, nil
+}
+
+// sampleComplete draws a pair and sets from the unseen kinds, each group
+// picked with weight equal to the number of ways to take its tiles.
+func sampleComplete(unseen [engine.NumKinds]uint8, sets int, rng *rand.Rand) ([engine.NumKinds]uint8, bool) {
+	var h [engine.NumKinds]uint8
+	c := unseen
+	var weights [engine.NumKinds + 27]float64 // triplets, then runs by lowest kind
+
+	total := 0.0
+	for i, n := range c {
+		weights[i] = choose(n, 2)
+		total += weights[i]
+	}
+	i, ok := pick(weights[:engine.NumKinds], total, rng)
+	if !ok {
+		return h, false
+	}
+	c[i] -= 2
+	h[i] += 2
+
+	for range sets {
+		total = 0
+		for i, n := range c {
+			weights[i] = choose(n, 3)
+			total += weights[i]
+		}
+		for i := range 27 {
+			weights[engine.NumKinds+i] = 0
+			if i%9 <= 6 {
+				weights[engine.NumKinds+i] = float64(c[i]) * float64(c[i+1]) * float64(c[i+2])
+			}
+			total += weights[engine.NumKinds+i]
+		}
+		g, ok := pick(weights[:], total, rng)
+		if !ok {
+			return h, false
+		}
+		if g < engine.NumKinds {
+			c[g] -= 3
+			h[g] += 3
+			continue
+		}
+		for k := g - engine.NumKinds; k < g-engine.NumKinds+3; k++ {
+			c[k]--
+			h[k]++
+		}
+	}
+	return h, true
+}
+
+// samplePairs draws seven distinct pairs from the unseen kinds.
+func samplePairs(unseen [engine.NumKinds]uint8, rng *rand.Rand) ([engine.NumKinds]uint8, bool) {
+	var h [engine.NumKinds]uint8
+	var weights [engine.NumKinds]float64
+	for range 7 {
+		total := 0.0
+		for i, n := range unseen {
+			weights[i] = 0
+			if h[i] == 0 {
+				weights[i] = choose(n, 2)
+			}
+			total += weights[i]
+		}
+		i, ok := pick(weights[:], total, rng)
+		if !ok {
+			return h, false
+		}
+		h[i] = 2
+	}
+	return h, true
+}
+
+// dropRandom takes one tile, chosen uniformly, out of a complete hand.
+func dropRandom(h *[engine.NumKinds]uint8, rng *rand.Rand) {
+	n := 0
+	for _, k := range h {
+		n += int(k)
+	}
+	x := rng.IntN(n)
+	for i, k := range h {
+		if x < int(k) {
+			h[i]--
+			return
+		}
+		x -= int(k)
+	}
+}
+
+func choose(n uint8, k int) float64 {
+	switch k {
+	case 2:
+		return float64(int(n) * (int(n) - 1) / 2)
+	default:
+		return float64(int(n) * (int(n) - 1) * (int(n) - 2) / 6)
+	}
+}
+
+// pick returns an index drawn with probability weights[i]/total.
+func pick(weights []float64, total float64, rng *rand.Rand) (int, bool) {
+	if total <= 0 {
+		return 0, false
+	}
+	x := rng.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return i, true
+		}
+		x -= w
+	}
+	// Rounding left x just past the end: take the last candidate.
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// riverWeight is the relative likelihood of a river given the concealed
+// hand h behind it.
+func riverWeight(river []engine.Discard, h *[engine.NumKinds]uint8) float64 {
+	weight := 1.0
+	for _, d := range river {
+		if d.Tsumogiri {
+			continue
+		}
+		i := d.Tile.Index()
+		lo, hi := i, i
+		if i < 27 {
+			lo, hi = max(i-2, i/9*9), min(i+2, i/9*9+8)
+		}
+		for j := lo; j <= hi; j++ {
+			if h[j] > 0 {
+				weight *= connectedCutFactor
+				break
+			}
+		}
+	}
+	return weight
+}
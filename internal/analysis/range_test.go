@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// rangeRound has seat 1 with pons of 2m and 8m after cutting pins and
+// sous from their hand, and seat 3 in riichi.
+func rangeRound(t *testing.T) *engine.Round {
+	t.Helper()
+	tiles := func(s string) []engine.Tile {
+		ts, err := engine.ParseHandCompact(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	r := &engine.Round{Dealer: 0, DoraIndicators: tiles("3z"), TilesLeft: 40}
+	r.Hands[0] = tiles("123p456p789s1122s")
+	pon := func(s string, from int) engine.Meld {
+		ts := tiles(s)
+		return engine.Meld{Kind: engine.MeldPon, Tiles: ts, Called: ts[0], From: from}
+	}
+	r.Melds[1] = []engine.Meld{pon("222m", 0), pon("888m", 0)}
+	seq := 0
+	river := func(seat int, s string) {
+		for _, tile := range tiles(s) {
+			r.Discards[seat] = append(r.Discards[seat], engine.Discard{Tile: tile, Seq: seq})
+			seq++
+		}
+	}
+	river(1, "1p9s4p6s2p7s3p")
+	river(3, "1z9m5p")
+	r.Discards[3][2].Riichi = true
+	r.Riichi[3] = true
+	river(0, "2m8m") // the tiles seat 1 called
+	r.Discards[0][0].Called = true
+	r.Discards[0][1].Called = true
+	return r
+}
+
+func suitShare(waits []WaitOdds) [4]float64 {
+	var share [4]float64
+	for _, w := range waits {
+		share[w.Tile.Suit()] += w.P
+	}
+	return share
+}
+
+func yakuP(rg Range, y engine.Yaku) float64 {
+	for _, o := range rg.Yaku {
+		if o.Yaku == y {
+			return o.P
+		}
+	}
+	return 0
+}
+
+func TestInferRange(t *testing.T) {
+	r := rangeRound(t)
+	rg, err := InferRange(r, 0, 1, engine.DefaultRules(), RangeConfig{Samples: 400, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Opponent != 1 || rg.Samples != 400 {
+		t.Errorf("opponent %d, %d samples", rg.Opponent, rg.Samples)
+	}
+	for _, w := range rg.Waits {
+		if w.P <= 0 || w.P > 1 {
+			t.Errorf("wait %v outside (0, 1]", w)
+		}
+		if slices.ContainsFunc(r.Discards[1], func(d engine.Discard) bool { return d.Tile.Index() == w.Tile.Index() }) {
+			t.Errorf("wait %v is in seat 1's river", w)
+		}
+	}
+	// Two pons of man after a river of pins and sous: the man waits lead.
+	share := suitShare(rg.Waits)
+	if share[engine.SuitManzu] <= share[engine.SuitPinzu] || share[engine.SuitManzu] <= share[engine.SuitSouzu] {
+		t.Errorf("wait share by suit = %v, want man ahead", share)
+	}
+	// Without yakuhai in the pons, the hand needs tanyao or a flush.
+	if rg.Yaku[0].Yaku != engine.YakuTanyao || yakuP(rg, engine.YakuHonitsu) < 0.1 {
+		t.Errorf("yaku = %v", rg.Yaku)
+	}
+}
+
+func TestInferRangeToitoi(t *testing.T) {
+	r := rangeRound(t)
+	tiles := func(s string) []engine.Tile {
+		ts, _ := engine.ParseHandCompact(s)
+		return ts
+	}
+	// Terminal pons rule out tanyao; with no flush or yakuhai in view
+	// toitoi and the outside hands are what is left.
+	r.Melds[1] = []engine.Meld{
+		{Kind: engine.MeldPon, Tiles: tiles("111m"), Called: tiles("1m")[0], From: 0},
+		{Kind: engine.MeldPon, Tiles: tiles("999p"), Called: tiles("9p")[0], From: 0},
+	}
+	r.Hands[0] = tiles("234m456p789s1122s")
+	rg, err := InferRange(r, 0, 1, engine.DefaultRules(), RangeConfig{Samples: 400, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yakuP(rg, engine.YakuTanyao) != 0 || yakuP(rg, engine.YakuToitoi) < 0.1 {
+		t.Errorf("yaku = %v", rg.Yaku)
+	}
+}
+
+func TestInferRangeRiichi(t *testing.T) {
+	r := rangeRound(t)
+	rg, err := InferRange(r, 0, 3, engine.DefaultRules(), RangeConfig{Samples: 200, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yakuP(rg, engine.YakuRiichi) != 1 {
+		t.Errorf("yaku = %v, want riichi in every hand", rg.Yaku)
+	}
+	for _, w := range rg.Waits {
+		if s := w.Tile.String(); s == "1z" || s == "9m" || s == "5p" {
+			t.Errorf("furiten wait %v", w)
+		}
+	}
+
+	if _, err := InferRange(r, 0, 0, engine.DefaultRules(), RangeConfig{}); err == nil {
+		t.Error("InferRange against the seat itself: no error")
+	}
+}
@@ -0,0 +1,199 @@
+// Package analysis evaluates riichi hands: shanten, tile acceptance and
+// discard choice.
+package analysis
+
+import (
+	"sync"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Shanten values of note. A hand one tile from tenpai is at 1.
+const (
+	Complete = -1
+	Tenpai   = 0
+)
+
+// Shanten returns how many tiles the concealed tiles in c are from tenpai,
+// the best of the regular, seven pairs and thirteen orphans forms. Called
+// melds are inferred from the tile count: a 13- or 14-tile hand has none,
+// a 10- or 11-tile hand one, and so on.
+func Shanten(c *engine.TileCounts) int {
+	s := RegularShanten(c)
+	if c.Len() >= 13 {
+		s = min(s, ChiitoiShanten(c), KokushiShanten(c))
+	}
+	return s
+}
+
+// RegularShanten returns the shanten of the four sets and a pair form.
+func RegularShanten(c *engine.TileCounts) int {
+	melds := 4 - c.Len()/3
+
+	// best[p][m] is the most partial sets that fit beside m sets, with
+	// (p=1) or without a pair, over the groups combined so far; -1 if m
+	// sets cannot be made.
+	best := emptyGroup
+	best[0][0] = 0
+	for g := range 4 {
+		var kinds [9]uint8
+		copy(kinds[:], c.Kinds[g*9:min(g*9+9, engine.NumKinds)])
+		best = combine(best, groupTable(kinds, g == 3))
+	}
+
+	s := 8
+	for p := range 2 {
+		for m, partials := range best[p] {
+			if partials < 0 || m+melds > 4 {
+				continue
+			}
+			sets := m + melds
+			s = min(s, 8-2*sets-min(int(partials), 4-sets)-p)
+		}
+	}
+	return s
+}
+
+// ChiitoiShanten returns the shanten of the seven pairs form. Four of a
+// kind count as one pair.
+func ChiitoiShanten(c *engine.TileCounts) int {
+	pairs, kinds := 0, 0
+	for _, n := range c.Kinds {
+		if n > 0 {
+			kinds++
+		}
+		if n >= 2 {
+			pairs++
+		}
+	}
+	return 6 - pairs + max(0, 7-kinds)
+}
+
+// KokushiShanten returns the shanten of the thirteen orphans form.
+func KokushiShanten(c *engine.TileCounts) int {
+	kinds, pair := 0, 0
+	for _, i := range orphans {
+		if c.Kinds[i] > 0 {
+			kinds++
+		}
+		if c.Kinds[i] >= 2 {
+			pair = 1
+		}
+	}
+	return 13 - kinds - pair
+}
+
+// orphans are the kind indices of the terminals and honors.
+var orphans = [13]int{0, 8, 9, 17, 18, 26, 27, 28, 29, 30, 31, 32, 33}
+
+// table holds the most partial sets (pairs, two-sided, edge and closed
+// waits) a group of tiles can form beside a pair (first index 1) or none
+// and a number of sets (second index, up to four), or -1 when that many
+// sets cannot be made.
+type table [2][5]int8
+
+var emptyGroup = table{{-1, -1, -1, -1, -1}, {-1, -1, -1, -1, -1}}
+
+func combine(a, b table) table {
+	out := emptyGroup
+	for pa := range 2 {
+		for ma, xa := range a[pa] {
+			if xa < 0 {
+				continue
+			}
+			for pb := range 2 - pa {
+				for mb, xb := range b[pb] {
+					if xb < 0 || ma+mb > 4 {
+						continue
+					}
+					out[pa+pb][ma+mb] = max(out[pa+pb][ma+mb], xa+xb)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// groupTables caches the table of every suit or honor layout seen so far,
+// keyed by the counts in base 5 with the honor flag on top.
+var groupTables sync.Map
+
+func groupTable(kinds [9]uint8, honors bool) table {
+	key := 0
+	for _, n := range kinds {
+		key = key*5 + int(n)
+	}
+	if honors {
+		key += 1 << 24
+	}
+	if t, ok := groupTables.Load(key); ok {
+		return t.(table)
+	}
+	d := decomposer{counts: kinds, honors: honors, best: emptyGroup}
+	d.search(0, 0, 0, 0)
+	groupTables.Store(key, d.best)
+	return d.best
+}
+
+// decomposer searches every split of one suit, or the honors, into sets,
+// partial sets, a pair and floating tiles.
+type decomposer struct {
+	counts [9]uint8
+	honors bool
+	best   table
+}
+
+func (d *decomposer) search(i, sets, partials, pair int) {
+	for i < 9 && d.counts[i] == 0 {
+		i++
+	}
+	if i == 9 {
+		if sets <= 4 {
+			d.best[pair][sets] = max(d.best[pair][sets], int8(min(partials, 8)))
+		}
+		return
+	}
+	c := &d.counts
+	runs := !d.honors
+
+	if c[i] >= 3 {
+		c[i] -= 3
+		d.search(i, sets+1, partials, pair)
+		c[i] += 3
+	}
+	if runs && i <= 6 && c[i+1] > 0 && c[i+2] > 0 {
+		c[i]--
+		c[i+1]--
+		c[i+2]--
+		d.search(i, sets+1, partials, pair)
+		c[i]++
+		c[i+1]++
+		c[i+2]++
+	}
+	if c[i] >= 2 {
+		c[i] -= 2
+		if pair == 0 {
+			d.search(i, sets, partials, 1)
+		}
+		d.search(i, sets, partials+1, pair)
+		c[i] += 2
+	}
+	if runs && i <= 7 && c[i+1] > 0 {
+		c[i]--
+		c[i+1]--
+		d.search(i, sets, partials+1, pair)
+		c[i]++
+		c[i+1]++
+	}
+	if runs && i <= 6 && c[i+2] > 0 {
+		c[i]--
+		c[i+2]--
+		d.search(i, sets, partials+1, pair)
+		c[i]++
+		c[i+2]++
+	}
+	// Leave the tile floating.
+	c[i]--
+	d.search(i, sets, partials, pair)
+	c[i]++
+}
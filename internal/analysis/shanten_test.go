@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func mustCounts(t testing.TB, s string) engine.TileCounts {
+	t.Helper()
+	tiles, err := engine.ParseHandCompact(s)
+	if err != nil {
+		t.Fatalf("ParseHandCompact(%q): %v", s, err)
+	}
+	c, err := engine.CountTiles(tiles)
+	if err != nil {
+		t.Fatalf("CountTiles(%q): %v", s, err)
+	}
+	return c
+}
+
+func TestShanten(t *testing.T) {
+	tests := []struct {
+		hand                            string
+		want, regular, chiitoi, kokushi int
+	}{
+		{"123m456p789s11122z", -1, -1, 4, 8},
+		{"123m456p789s1112z", 0, 0, 5, 8},
+		{"123m456p789s45s11z", 0, 0, 5, 9},
+		{"1133557799m11p2z", 0, 3, 0, 8},
+		{"1133557799m11p22z", -1, 3, -1, 8},
+		{"19m19p19s1234567z", 0, 8, 6, 0},
+		{"19m19p19s12345677z", -1, 7, 5, -1},
+		{"147m258p369s1234z", 6, 8, 6, 7},
+		{"123m456p78s12345z", 3, 3, 6, 7},
+		{"123m456p78s123z", 1, 1, 0, 0},
+		{"2345m", 0, 0, 0, 0},
+		{"123m11z", -1, -1, 0, 0},
+		{"1m", 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		c := mustCounts(t, tt.hand)
+		if got := Shanten(&c); got != tt.want {
+			t.Errorf("Shanten(%s) = %d, want %d", tt.hand, got, tt.want)
+		}
+		if got := RegularShanten(&c); got != tt.regular {
+			t.Errorf("RegularShanten(%s) = %d, want %d", tt.hand, got, tt.regular)
+		}
+		if c.Len() < 13 {
+			continue
+		}
+		if got := ChiitoiShanten(&c); got != tt.chiitoi {
+			t.Errorf("ChiitoiShanten(%s) = %d, want %d", tt.hand, got, tt.chiitoi)
+		}
+		if got := KokushiShanten(&c); got != tt.kokushi {
+			t.Errorf("KokushiShanten(%s) = %d, want %d", tt.hand, got, tt.kokushi)
+		}
+	}
+}
+
+func BenchmarkShanten(b *testing.B) {
+	c := mustCounts(b, "1245789m3568p346s")
+	for b.Loop() {
+		Shanten(&c)
+	}
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Acceptance is the set of tiles that bring a hand closer to tenpai.
+type Acceptance struct {
+	Shanten int
+	Tiles   []engine.Tile // kinds that lower the shanten, in kind order
+	Count   int           // live copies of Tiles
+}
+
+// Accept finds the tile kinds that lower the shanten of a hand waiting
+// for a draw (13 tiles less three per meld). seen counts the tiles
+// visible outside the hand: rivers, melds and dora indicators. A kind is
+// accepted even when no copy is live; it then adds nothing to Count.
+func Accept(hand, seen *engine.TileCounts) Acceptance {
+	h := *hand
+	a := Acceptance{Shanten: Shanten(&h)}
+	for i := range engine.NumKinds {
+		if h.Kinds[i] >= 4 {
+			continue
+		}
+		h.Kinds[i]++
+		if Shanten(&h) < a.Shanten {
+			a.Tiles = append(a.Tiles, engine.TileAt(i))
+			a.Count += live(hand, seen, i)
+		}
+		h.Kinds[i]--
+	}
+	return a
+}
+
+// live returns the unseen copies of kind i.
+func live(hand, seen *engine.TileCounts, i int) int {
+	return max(0, 4-int(hand.Kinds[i])-int(seen.Kinds[i]))
+}
+
+// Option is the result of one discard from a hand that has just drawn.
+type Option struct {
+	Discard engine.Tile
+	Acceptance
+}
+
+func (o Option) String() string {
+	return fmt.Sprintf("%s: %d-shanten, %s (%d)",
+		o.Discard, o.Shanten, engine.FormatHandCompact(o.Tiles), o.Count)
+}
+
+// Discards evaluates every distinct discard of a hand that has just drawn
+// (14 tiles less three per meld). Results are sorted the way Tenhou's
+// hand analyzer lists them: lowest shanten first, then most live tiles
+// accepted, then kind order. A five is discarded as a plain five while
+// one is held, keeping the red one.
+func Discards(hand, seen *engine.TileCounts) []Option {
+	var opts []Option
+	h, s := *hand, *seen
+	for i, n := range hand.Kinds {
+		if n == 0 {
+			continue
+		}
+		discard := engine.TileAt(i)
+		if i < 27 && i%9 == 4 && hand.Red[i/9] == n {
+			discard, _ = engine.NewRedFive(discard.Suit())
+		}
+		// The discard lies in the river and is no longer live.
+		h.Kinds[i]--
+		s.Kinds[i]++
+		opts = append(opts, Option{Discard: discard, Acceptance: Accept(&h, &s)})
+		h.Kinds[i]++
+		s.Kinds[i]--
+	}
+	slices.SortStableFunc(opts, func(a, b Option) int {
+		return cmp.Or(
+			cmp.Compare(a.Shanten, b.Shanten),
+			cmp.Compare(b.Count, a.Count),
+		)
+	})
+	return opts
+}
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestAccept(t *testing.T) {
+	tests := []struct {
+		hand, seen string
+		shanten    int
+		tiles      string
+		count      int
+	}{
+		{"123m456p789s45s11z", "", 0, "36s", 8},
+		{"123m456p789s45s11z", "33s", 0, "36s", 6},
+		{"123m456p789s1112z", "", 0, "2z", 3},
+		{"2345m", "", 0, "25m", 6},
+		{"19m19p19s1234567z", "1m", 0, "19m19p19s1234567z", 38},
+		{"1m", "111m", 0, "1m", 0},
+	}
+	for _, tt := range tests {
+		hand, seen := mustCounts(t, tt.hand), mustCounts(t, tt.seen)
+		a := Accept(&hand, &seen)
+		if a.Shanten != tt.shanten || engine.FormatHandCompact(a.Tiles) != tt.tiles || a.Count != tt.count {
+			t.Errorf("Accept(%s, seen %s) = %d-shanten %s (%d), want %d-shanten %s (%d)",
+				tt.hand, tt.seen, a.Shanten, engine.FormatHandCompact(a.Tiles), a.Count,
+				tt.shanten, tt.tiles, tt.count)
+		}
+	}
+}
+
+func TestDiscards(t *testing.T) {
+	hand, seen := mustCounts(t, "123m456p789s11z459s"), mustCounts(t, "")
+	opts := Discards(&hand, &seen)
+	if len(opts) != 12 {
+		t.Fatalf("%d options, want one per kind: %v", len(opts), opts)
+	}
+	if got := opts[0].String(); got != "9s: 0-shanten, 36s (8)" {
+		t.Errorf("best = %s", got)
+	}
+	for i := 1; i < len(opts); i++ {
+		a, b := opts[i-1], opts[i]
+		if a.Shanten > b.Shanten || a.Shanten == b.Shanten && a.Count < b.Count {
+			t.Errorf("options out of order: %s before %s", a, b)
+		}
+	}
+}
+
+func TestDiscardsCountDiscardAsSeen(t *testing.T) {
+	// Throwing one 2z from three leaves a 1z/2z wait: two live 1z and
+	// one live 2z, the discard being gone.
+	hand, seen := mustCounts(t, "123m456p789s11222z"), mustCounts(t, "")
+	for _, o := range Discards(&hand, &seen) {
+		if o.Discard.String() == "2z" && (o.Shanten != 0 || o.Count != 3) {
+			t.Errorf("discard 2z: %s", o)
+		}
+	}
+}
+
+func TestDiscardsKeepRedFive(t *testing.T) {
+	tests := []struct {
+		hand, discard string
+	}{
+		{"055m123p456s789s1z", "5m"},
+		{"0m1234p456s789s11z", "0m"},
+	}
+	for _, tt := range tests {
+		hand, seen := mustCounts(t, tt.hand), mustCounts(t, "")
+		found := false
+		for _, o := range Discards(&hand, &seen) {
+			if o.Discard.Index() == 4 {
+				found = true
+				if o.Discard.String() != tt.discard {
+					t.Errorf("%s: discards %s, want %s", tt.hand, o.Discard, tt.discard)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: no five discard", tt.hand)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Visible splits what seat can see of a round into its own concealed
+// tiles and everything else on the table: the rivers, every meld and the
+// dora indicators. A discard that was called is counted once, in the meld
+// that took it.
+func Visible(r *engine.Round, seat int) (hand, seen engine.TileCounts, err error) {
+	if seat < 0 || seat > 3 {
+		return hand, seen, fmt.Errorf("invalid seat %d", seat)
+	}
+	if hand, err = engine.CountTiles(r.Hands[seat]); err != nil {
+		return hand, seen, fmt.Errorf("seat %d hand: %w", seat, err)
+	}
+	table := append([]engine.Tile(nil), r.DoraIndicators...)
+	for s := range 4 {
+		for _, d := range r.Discards[s] {
+			if !d.Called {
+				table = append(table, d.Tile)
+			}
+		}
+		for _, m := range r.Melds[s] {
+			table = append(table, m.Tiles...)
+		}
+	}
+	if seen, err = engine.CountTiles(table); err != nil {
+		return hand, seen, fmt.Errorf("table: %w", err)
+	}
+	for i, n := range seen.Kinds {
+		if n+hand.Kinds[i] > 4 {
+			return hand, seen, fmt.Errorf("more than four %s in view", engine.TileAt(i))
+		}
+	}
+	return hand, seen, nil
+}
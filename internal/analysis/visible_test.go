@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestVisible(t *testing.T) {
+	events, err := engine.LoadEventLog("../engine/testdata/events.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range events {
+		g, err := engine.Replay(events, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := g.Round
+		if r == nil {
+			continue
+		}
+		want := len(r.DoraIndicators)
+		for s := range 4 {
+			for _, d := range r.Discards[s] {
+				if !d.Called {
+					want++
+				}
+			}
+			for _, m := range r.Melds[s] {
+				want += len(m.Tiles)
+			}
+		}
+		for seat := range 4 {
+			hand, seen, err := Visible(r, seat)
+			if err != nil {
+				t.Fatalf("event %d seat %d: %v", n, seat, err)
+			}
+			if hand.Len() != len(r.Hands[seat]) || seen.Len() != want {
+				t.Errorf("event %d seat %d: hand %d, seen %d; want %d, %d",
+					n, seat, hand.Len(), seen.Len(), len(r.Hands[seat]), want)
+			}
+		}
+	}
+}
+
+func TestVisibleTooMany(t *testing.T) {
+	one := mustCounts(t, "1z").Tiles()
+	r := &engine.Round{DoraIndicators: append(one, one[0], one[0])}
+	r.Hands[0] = append(one, one[0])
+	if _, _, err := Visible(r, 0); err == nil {
+		t.Error("five 1z in view accepted")
+	}
+}
+
+func TestVisibleInvalidSeat(t *testing.T) {
+	for _, seat := range []int{-1, 4} {
+		if _, _, err := Visible(&engine.Round{}, seat); err == nil {
+			t.Errorf("seat %d accepted", seat)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package bot is a rule-based player. It plays for the fastest hand by
+// shanten and tile acceptance, calls only for a yaku it can see, and
+// folds to the safest tile when someone else is in riichi.
+package bot
+
+import (
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/analysis"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Bot is the baseline player, a table.Agent to seat with table.Local.
+// The zero value is ready to use and keeps no state between decisions,
+// so one Bot can fill several seats.
+type Bot struct{}
+
+// Turn wins when it can. Otherwise, facing a riichi without being in
+// tenpai, it discards the least dangerous tile; else it discards for the
+// lowest shanten and most live tiles, declaring riichi in tenpai when a
+// winning tile is still live.
+func (Bot) Turn(v *table.View, legal []table.Action) table.Action {
+	if a, ok := find(legal, table.ActionTsumo); ok {
+		return a
+	}
+	hand, seen, err := analysis.Visible(v.Round, v.Seat)
+	if err != nil {
+		return firstDiscard(legal)
+	}
+	opts := analysis.Discards(&hand, &seen)
+
+	if threatened(v) && opts[0].Shanten > analysis.Tenpai {
+		if ranking, err := analysis.DangerRanking(v.Round, v.Seat); err == nil {
+			for i := len(ranking) - 1; i >= 0; i-- {
+				if a, ok := discardOf(legal, ranking[i].Tile); ok {
+					return a
+				}
+			}
+		}
+	}
+
+	for _, o := range opts {
+		if o.Shanten == analysis.Tenpai && o.Count > 0 {
+			if a, ok := riichiOf(legal, o.Discard); ok {
+				return a
+			}
+		}
+		if a, ok := discardOf(legal, o.Discard); ok {
+			return a
+		}
+	}
+	return firstDiscard(legal)
+}
+
+// Call wins on a discard when it can. It never calls while someone else
+// is in riichi, and otherwise takes a pon or chi only when the call
+// lowers its shanten and leaves a yaku in sight: a yakuhai pon, an open
+// yakuhai already down, or an all-simples hand.
+func (Bot) Call(v *table.View, legal []table.Action) table.Action {
+	if a, ok := find(legal, table.ActionRon); ok {
+		return a
+	}
+	pass := table.Action{Type: table.ActionPass}
+	if threatened(v) {
+		return pass
+	}
+	hand, err := engine.CountTiles(v.Round.Hands[v.Seat])
+	if err != nil {
+		return pass
+	}
+	before := analysis.Shanten(&hand)
+	for _, a := range legal {
+		if a.Type != table.ActionPon && a.Type != table.ActionChi {
+			continue
+		}
+		after := hand
+		for _, t := range a.Meld.Tiles[1:] { // the first tile is the one called
+			after.Remove(t)
+		}
+		if analysis.Shanten(&after) < before && hasYakuPath(v, *a.Meld, &after) {
+			return a
+		}
+	}
+	return pass
+}
+
+// threatened reports whether another seat is in riichi.
+func threatened(v *table.View) bool {
+	for s, riichi := range v.Round.Riichi {
+		if riichi && s != v.Seat {
+			return true
+		}
+	}
+	return false
+}
+
+// hasYakuPath reports whether calling m leaves a yaku the bot can see.
+func hasYakuPath(v *table.View, m engine.Meld, rest *engine.TileCounts) bool {
+	melds := append(slices.Clone(v.Round.Melds[v.Seat]), m)
+	for _, p := range melds {
+		if p.Kind != engine.MeldChi && isYakuhai(v, p.Tiles[0]) {
+			return true
+		}
+	}
+	if !v.Rules.OpenTanyao {
+		return false
+	}
+	for _, p := range melds {
+		if slices.ContainsFunc(p.Tiles, engine.Tile.IsTerminalOrHonor) {
+			return false
+		}
+	}
+	// A tile or two outside can still be discarded on the way.
+	outside := 0
+	for i, n := range rest.Kinds {
+		if engine.TileAt(i).IsTerminalOrHonor() {
+			outside += int(n)
+		}
+	}
+	return outside <= 2
+}
+
+func isYakuhai(v *table.View, t engine.Tile) bool {
+	seatWind := engine.Wind((v.Seat - v.Round.Dealer + 4) % 4)
+	return t.IsDragon() || t.Index() == seatWind.Tile().Index() || t.Index() == v.Round.Wind.Tile().Index()
+}
+
+func find(legal []table.Action, typ table.ActionType) (table.Action, bool) {
+	i := slices.IndexFunc(legal, func(a table.Action) bool { return a.Type == typ })
+	if i < 0 {
+		return table.Action{}, false
+	}
+	return legal[i], true
+}
+
+// discardOf returns the legal discard of t, or of a tile of the same
+// kind when that exact tile (a red or plain five) is not offered.
+func discardOf(legal []table.Action, t engine.Tile) (table.Action, bool) {
+	return ofType(legal, table.ActionDiscard, t)
+}
+
+func riichiOf(legal []table.Action, t engine.Tile) (table.Action, bool) {
+	return ofType(legal, table.ActionRiichi, t)
+}
+
+func ofType(legal []table.Action, typ table.ActionType, t engine.Tile) (table.Action, bool) {
+	var alike *table.Action
+	for i, a := range legal {
+		if a.Type != typ {
+			continue
+		}
+		if a.Tile == t {
+			return a, true
+		}
+		if a.Tile.Index() == t.Index() && alike == nil {
+			alike = &legal[i]
+		}
+	}
+	if alike != nil {
+		return *alike, true
+	}
+	return table.Action{}, false
+}
+
+func firstDiscard(legal []table.Action) table.Action {
+	if a, ok := find(legal, table.ActionDiscard); ok {
+		return a
+	}
+	return legal[0]
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+func tile(t *testing.T, s string) engine.Tile {
+	t.Helper()
+	tt, err := engine.ParseTile(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tt
+}
+
+// turnView is seat 0 to move with hand, every tile discardable.
+func turnView(t *testing.T, hand string) (*table.View, []table.Action) {
+	t.Helper()
+	tiles, err := engine.ParseHandCompact(hand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &engine.Round{TilesLeft: 50}
+	r.Hands[0] = tiles
+	var legal []table.Action
+	for _, tt := range tiles {
+		legal = append(legal, table.Action{Type: table.ActionDiscard, Tile: tt})
+	}
+	return &table.View{Rules: engine.DefaultRules(), Round: r}, legal
+}
+
+func TestTurn(t *testing.T) {
+	t.Run("tsumo", func(t *testing.T) {
+		v, legal := turnView(t, "123m456p789s11z234m")
+		legal = append(legal, table.Action{Type: table.ActionTsumo})
+		if got := (Bot{}).Turn(v, legal); got.Type != table.ActionTsumo {
+			t.Errorf("got %s, want tsumo", got)
+		}
+	})
+
+	t.Run("attack", func(t *testing.T) {
+		v, legal := turnView(t, "123m456p789s11z23m7z")
+		if got := (Bot{}).Turn(v, legal); !got.Equal(table.Action{Type: table.ActionDiscard, Tile: tile(t, "7z")}) {
+			t.Errorf("got %s, want discard 7z", got)
+		}
+	})
+
+	t.Run("riichi", func(t *testing.T) {
+		v, legal := turnView(t, "123m456p789s11z23m7z")
+		legal = append(legal, table.Action{Type: table.ActionRiichi, Tile: tile(t, "7z")})
+		if got := (Bot{}).Turn(v, legal); !got.Equal(table.Action{Type: table.ActionRiichi, Tile: tile(t, "7z")}) {
+			t.Errorf("got %s, want riichi 7z", got)
+		}
+	})
+
+	t.Run("fold", func(t *testing.T) {
+		v, legal := turnView(t, "1457m588889p19s26z")
+		v.Round.Riichi[1] = true
+		v.Round.Discards[1] = []engine.Discard{
+			{Tile: tile(t, "4m"), Seq: 0},
+			{Tile: tile(t, "3p"), Seq: 1, Riichi: true},
+		}
+		if got := (Bot{}).Turn(v, legal); !got.Equal(table.Action{Type: table.ActionDiscard, Tile: tile(t, "4m")}) {
+			t.Errorf("got %s, want the genbutsu 4m", got)
+		}
+	})
+}
+
+func TestCall(t *testing.T) {
+	haku := tile(t, "5z")
+	pon := table.Action{Type: table.ActionPon, Meld: &engine.Meld{
+		Kind: engine.MeldPon, Tiles: []engine.Tile{haku, haku, haku}, Called: haku, From: 3,
+	}}
+	one := tile(t, "1m")
+	chi := table.Action{Type: table.ActionChi, Meld: &engine.Meld{
+		Kind: engine.MeldChi, Tiles: []engine.Tile{one, tile(t, "2m"), tile(t, "3m")}, Called: one, From: 3,
+	}}
+	pass := table.Action{Type: table.ActionPass}
+
+	tests := []struct {
+		name   string
+		hand   string
+		riichi bool
+		legal  []table.Action
+		want   table.Action
+	}{
+		{"yakuhai pon", "23m456p78s19p1s557z", false, []table.Action{pass, pon}, pon},
+		{"no calls against riichi", "23m456p78s19p1s557z", true, []table.Action{pass, pon}, pass},
+		{"chi without yaku", "23m456p78s19p1s557z", false, []table.Action{pass, chi}, pass},
+		{"ron", "23m456p78s19p1s557z", true, []table.Action{pass, {Type: table.ActionRon}}, table.Action{Type: table.ActionRon}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand, err := engine.ParseHandCompact(tt.hand)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &engine.Round{TilesLeft: 50}
+			r.Hands[0] = hand
+			r.Riichi[2] = tt.riichi
+			v := &table.View{Rules: engine.DefaultRules(), Round: r, Offer: &haku}
+			if got := (Bot{}).Call(v, tt.legal); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGames(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	kinds := map[engine.EventType]int{}
+	for seed := range uint64(10) {
+		res, err := table.Play(context.Background(), [4]table.Player{table.Local(Bot{}), table.Local(Bot{}), table.Local(Bot{}), table.Local(Bot{})}, table.Config{Rules: rules, Seed: seed})
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		g, err := engine.Replay(res.Events, -1)
+		if err != nil {
+			t.Fatalf("seed %d: replay: %v", seed, err)
+		}
+		if !g.Finished {
+			t.Errorf("seed %d: game not finished", seed)
+		}
+		total := 0
+		for _, s := range res.Scores {
+			total += s
+		}
+		if total != 4*rules.StartingPoints {
+			t.Errorf("seed %d: scores %v add up to %d", seed, res.Scores, total)
+		}
+		for _, e := range res.Events {
+			kinds[e.Type]++
+		}
+	}
+	for _, k := range []engine.EventType{engine.EventWin, engine.EventRiichiAccepted, engine.EventCall} {
+		if kinds[k] == 0 {
+			t.Errorf("no %s in 10 games", k)
+		}
+	}
+	if kinds[engine.EventWin] < kinds[engine.EventRyuukyoku] {
+		t.Errorf("%d wins and %d draws: bots should win most rounds", kinds[engine.EventWin], kinds[engine.EventRyuukyoku])
+	}
+}
@@ -0,0 +1,241 @@
+// Package dataset turns game logs into supervised learning samples: one
+// per decision, holding what the deciding seat could see, the action it
+// took and how its round and game turned out.
+package dataset
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/rl"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Label columns.
+const (
+	LabelSeat       = iota // the deciding seat
+	LabelRound             // the round's place in the game, from 0
+	LabelRoundDelta        // the seat's change in score over the round
+	LabelWon               // 1 if the seat won the round
+	LabelDealtIn           // 1 if the seat dealt into a win in the round
+	LabelFinalScore        // the seat's score at the end of the game
+	LabelPlacement         // the seat's final place, from 0 for first
+	NumLabels
+)
+
+// Sample is one decision.
+type Sample struct {
+	Obs    rl.Observation // encoded from the seat's own view
+	Action int            // index in the rl action space
+	Labels [NumLabels]int32
+}
+
+// Extract replays a game and returns a sample for each decision in it.
+//
+// Turn decisions (discard, riichi, tsumo, closed or added kan) are taken
+// from every turn. A discard is a call decision for each seat that took
+// it, by ron or a call, and, when nobody took it, a pass for each seat
+// that held the tiles to pon, chi or kan it. Logs do not show who could
+// have won on a tile and let it go, so passed rons are missing.
+//
+// A game that stops before its end is labeled with the scores reached.
+func Extract(events []engine.Event) ([]Sample, error) {
+	x := extractor{g: engine.NewGame(), round: -1}
+	for i, e := range events {
+		x.decisions(e)
+		if err := x.g.Apply(e); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", i, e, err)
+		}
+		x.record(events, i)
+	}
+	x.endRound()
+	return x.label(), nil
+}
+
+// extractor holds the replay and the samples taken so far.
+type extractor struct {
+	g      *engine.Game
+	rules  engine.Rules
+	round  int
+	drawn  [4]*engine.Tile // the tile a seat just drew, until it acts
+	riichi *pending        // a riichi waiting for its discard
+
+	samples []Sample
+	rounds  []roundInfo
+}
+
+// pending is a decision whose action shows in a later event.
+type pending struct {
+	obs  rl.Observation
+	seat int
+}
+
+type roundInfo struct {
+	start, end [4]int
+	won, dealt [4]bool
+}
+
+// decisions takes the turn samples for e before it is applied: the state
+// the deciding seat saw is the one before its action.
+func (x *extractor) decisions(e engine.Event) {
+	r := x.g.Round
+	if r == nil || r.Ended {
+		return
+	}
+	switch e.Type {
+	case engine.EventRiichi:
+		x.riichi = &pending{obs: x.encode(e.Seat, x.drawn[e.Seat], nil), seat: e.Seat}
+
+	case engine.EventDiscard:
+		if p := x.riichi; p != nil && p.seat == e.Seat {
+			x.add(p.obs, e.Seat, table.Action{Type: table.ActionRiichi, Tile: e.Tile})
+			x.riichi = nil
+			return
+		}
+		x.add(x.encode(e.Seat, x.drawn[e.Seat], nil), e.Seat, table.Action{Type: table.ActionDiscard, Tile: e.Tile})
+
+	case engine.EventCall:
+		if e.Meld.Kind == engine.MeldAnkan || e.Meld.Kind == engine.MeldShouminkan {
+			x.add(x.encode(e.Seat, x.drawn[e.Seat], nil), e.Seat, table.Action{Type: table.ActionKan, Meld: e.Meld})
+		}
+
+	case engine.EventWin:
+		if w := e.Win; w.Seat == w.From {
+			x.add(x.encode(w.Seat, x.drawn[w.Seat], nil), w.Seat, table.Action{Type: table.ActionTsumo})
+		}
+	}
+}
+
+// record follows the game after events[i] is applied, and takes the
+// call decisions on a discard.
+func (x *extractor) record(events []engine.Event, i int) {
+	e := events[i]
+	switch e.Type {
+	case engine.EventGameStart:
+		x.rules = e.Game.Rules
+	case engine.EventRoundStart:
+		x.endRound()
+		x.round++
+		x.rounds = append(x.rounds, roundInfo{start: e.Round.Scores})
+		x.drawn, x.riichi = [4]*engine.Tile{}, nil
+	case engine.EventDrawTile:
+		t := e.Tile
+		x.drawn[e.Seat] = &t
+	case engine.EventDiscard:
+		x.drawn[e.Seat] = nil
+		x.offers(events, i)
+	case engine.EventCall:
+		x.drawn[e.Seat] = nil
+	case engine.EventWin:
+		ri := &x.rounds[x.round]
+		ri.won[e.Win.Seat] = true
+		if e.Win.From != e.Win.Seat {
+			ri.dealt[e.Win.From] = true
+		}
+	}
+}
+
+// offers takes the call decisions on the discard events[i], looking at
+// the events after it to see who took the tile.
+func (x *extractor) offers(events []engine.Event, i int) {
+	p, t := events[i].Seat, events[i].Tile
+	var takers [4]*table.Action
+	took := false
+next:
+	for _, n := range events[i+1:] {
+		switch {
+		case n.Type == engine.EventRiichiAccepted || n.Type == engine.EventDora:
+			continue
+		case n.Type == engine.EventWin && n.Win.From == p && n.Win.Seat != p:
+			takers[n.Win.Seat] = &table.Action{Type: table.ActionRon}
+			took = true
+			continue
+		case n.Type == engine.EventCall && n.Meld.IsOpen() && n.Meld.Kind != engine.MeldShouminkan:
+			a := table.Action{Type: table.ActionPon, Meld: n.Meld}
+			switch n.Meld.Kind {
+			case engine.MeldChi:
+				a.Type = table.ActionChi
+			case engine.MeldKan:
+				a.Type = table.ActionOpenKan
+			}
+			takers[n.Seat], took = &a, true
+		}
+		break next
+	}
+
+	for q := range 4 {
+		switch {
+		case q == p:
+		case takers[q] != nil:
+			x.add(x.encode(q, nil, &t), q, *takers[q])
+		case !took && x.canCall(q, p, t):
+			x.add(x.encode(q, nil, &t), q, table.Action{Type: table.ActionPass})
+		}
+	}
+}
+
+// canCall reports whether seat q holds the tiles to pon, open kan or chi
+// tile t from seat p.
+func (x *extractor) canCall(q, p int, t engine.Tile) bool {
+	r := x.g.Round
+	if r.Riichi[q] || r.TilesLeft == 0 {
+		return false
+	}
+	var kinds [engine.NumKinds]int
+	for _, h := range r.Hands[q] {
+		kinds[h.Index()]++
+	}
+	k := t.Index()
+	if kinds[k] >= 2 {
+		return true
+	}
+	if q != (p+1)%4 || k >= 27 {
+		return false
+	}
+	has := func(j int) bool { return j >= k/9*9 && j <= k/9*9+8 && kinds[j] > 0 }
+	return has(k-2) && has(k-1) || has(k-1) && has(k+1) || has(k+1) && has(k+2)
+}
+
+func (x *extractor) endRound() {
+	if x.round >= 0 {
+		x.rounds[x.round].end = x.g.Scores
+	}
+}
+
+func (x *extractor) encode(seat int, drawn, offer *engine.Tile) rl.Observation {
+	return rl.Encode(table.NewView(x.g.Round, seat, x.rules, x.g.Scores, drawn, offer))
+}
+
+func (x *extractor) add(obs rl.Observation, seat int, a table.Action) {
+	s := Sample{Obs: obs, Action: rl.Index(a)}
+	s.Labels[LabelSeat] = int32(seat)
+	s.Labels[LabelRound] = int32(x.round)
+	x.samples = append(x.samples, s)
+}
+
+// label fills in the outcomes once the game is over.
+func (x *extractor) label() []Sample {
+	final := x.g.Scores
+	place := (&table.Result{Scores: final}).Placement()
+	var placement [4]int32
+	for i, s := range place {
+		placement[s] = int32(i)
+	}
+	for i := range x.samples {
+		l := &x.samples[i].Labels
+		seat, ri := l[LabelSeat], &x.rounds[l[LabelRound]]
+		l[LabelRoundDelta] = int32(ri.end[seat] - ri.start[seat])
+		l[LabelWon] = b2i(ri.won[seat])
+		l[LabelDealtIn] = b2i(ri.dealt[seat])
+		l[LabelFinalScore] = int32(final[seat])
+		l[LabelPlacement] = placement[seat]
+	}
+	return x.samples
+}
+
+func b2i(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
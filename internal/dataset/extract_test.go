@@ -0,0 +1,142 @@
+package dataset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/bot"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/rl"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+func playGame(t *testing.T, seed uint64) *table.Result {
+	t.Helper()
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	b := table.Local(bot.Bot{})
+	res, err := table.Play(context.Background(), [4]table.Player{b, b, b, b}, table.Config{Rules: rules, Seed: seed})
+	if err != nil {
+		t.Fatalf("seed %d: %v", seed, err)
+	}
+	return res
+}
+
+func TestExtract(t *testing.T) {
+	kinds := map[int]int{}
+	for seed := range uint64(4) {
+		res := playGame(t, seed)
+		samples, err := Extract(res.Events)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if len(samples) == 0 {
+			t.Fatalf("seed %d: no samples", seed)
+		}
+
+		discards := 0
+		for _, e := range res.Events {
+			if e.Type == engine.EventDiscard {
+				discards++
+			}
+		}
+		var place [4]int32
+		for i, s := range res.Placement() {
+			place[s] = int32(i)
+		}
+		turns := 0
+		for i, s := range samples {
+			a := s.Action
+			switch {
+			case a >= rl.ActionDiscard && a < rl.ActionTsumo:
+				turns++
+				kinds[rl.ActionDiscard]++
+			case a >= rl.ActionChi && a <= rl.ActionOpenKan:
+				kinds[rl.ActionChi]++
+			default:
+				kinds[a]++
+			}
+
+			// Each sample sees its own hand only.
+			held := float32(0)
+			for _, n := range s.Obs[rl.PlaneHand] {
+				held += n
+			}
+			if held < 1 || held > 14 {
+				t.Errorf("seed %d: sample %d holds %v tiles", seed, i, held)
+			}
+
+			seat := s.Labels[LabelSeat]
+			if got, want := s.Labels[LabelFinalScore], int32(res.Scores[seat]); got != want {
+				t.Errorf("seed %d: sample %d final score %d, want %d", seed, i, got, want)
+			}
+			if got := s.Labels[LabelPlacement]; got != place[seat] {
+				t.Errorf("seed %d: sample %d placement %d, want %d", seed, i, got, place[seat])
+			}
+			if s.Labels[LabelWon] == 1 && s.Labels[LabelRoundDelta] <= 0 {
+				t.Errorf("seed %d: sample %d won and lost %d", seed, i, s.Labels[LabelRoundDelta])
+			}
+			if s.Labels[LabelDealtIn] == 1 && s.Labels[LabelRoundDelta] >= 0 {
+				t.Errorf("seed %d: sample %d dealt in and gained %d", seed, i, s.Labels[LabelRoundDelta])
+			}
+		}
+		if turns != discards {
+			t.Errorf("seed %d: %d discard samples for %d discards", seed, turns, discards)
+		}
+	}
+	for _, k := range []int{rl.ActionDiscard, rl.ActionChi, rl.ActionPass, rl.ActionRon, rl.ActionTsumo} {
+		if kinds[k] == 0 {
+			t.Errorf("no samples of action %d in 4 games", k)
+		}
+	}
+}
+
+func TestExtractView(t *testing.T) {
+	res := playGame(t, 1)
+	samples, err := Extract(res.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first decision is the dealer's first discard, seen with the
+	// fourteen tiles it was dealt and drew and nothing else.
+	var first engine.Event
+	for _, e := range res.Events {
+		if e.Type == engine.EventDiscard {
+			first = e
+			break
+		}
+	}
+	s := samples[0]
+	if int(s.Labels[LabelSeat]) != first.Seat {
+		t.Fatalf("first sample by seat %d, first discard by %d", s.Labels[LabelSeat], first.Seat)
+	}
+	if want := rl.Index(table.Action{Type: table.ActionDiscard, Tile: first.Tile}); s.Action != want {
+		t.Errorf("first action %d, want %d", s.Action, want)
+	}
+	held := float32(0)
+	for _, n := range s.Obs[rl.PlaneHand] {
+		held += n
+	}
+	if held != 14 {
+		t.Errorf("first sample holds %v tiles, want 14", held)
+	}
+	for p := rl.PlaneRivers; p < rl.PlaneRivers+4; p++ {
+		if s.Obs[p] != ([engine.NumKinds]float32{}) {
+			t.Errorf("plane %d set before any discard", p)
+		}
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	res := playGame(t, 0)
+	events := append([]engine.Event(nil), res.Events...)
+	for i, e := range events {
+		if e.Type == engine.EventDiscard {
+			events[i].Seat = (e.Seat + 1) % 4
+			break
+		}
+	}
+	if _, err := Extract(events); err == nil {
+		t.Error("a discard out of turn was accepted")
+	}
+}
@@ -0,0 +1,130 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/rl"
+)
+
+// DefaultShardSize is the number of samples per shard when Writer is
+// given zero. A shard's observations take about 4.9 MB per thousand
+// samples.
+const DefaultShardSize = 4096
+
+// Writer stores samples in NumPy .npy files, split into shards. Shard n
+// is three files in the output directory:
+//
+//	shard-0000n-obs.npy     float32 (samples, rl.NumPlanes, 34)
+//	shard-0000n-action.npy  int32   (samples,)
+//	shard-0000n-label.npy   int32   (samples, NumLabels)
+//
+// which numpy.load reads directly.
+type Writer struct {
+	dir       string
+	shardSize int
+	shards    int
+
+	obs     []float32
+	actions []int32
+	labels  []int32
+}
+
+// NewWriter creates dir if needed and writes shards of shardSize samples
+// into it. Call Close to write the last shard.
+func NewWriter(dir string, shardSize int) (*Writer, error) {
+	if shardSize <= 0 {
+		shardSize = DefaultShardSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Writer{dir: dir, shardSize: shardSize}, nil
+}
+
+// Write adds a sample, writing out the shard once it is full.
+func (w *Writer) Write(s *Sample) error {
+	for i := range s.Obs {
+		w.obs = append(w.obs, s.Obs[i][:]...)
+	}
+	w.actions = append(w.actions, int32(s.Action))
+	w.labels = append(w.labels, s.Labels[:]...)
+	if len(w.actions) == w.shardSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes the samples not yet written as a last, shorter shard.
+func (w *Writer) Close() error {
+	if len(w.actions) == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+// Shards returns the number of shards written.
+func (w *Writer) Shards() int {
+	return w.shards
+}
+
+func (w *Writer) flush() error {
+	n := len(w.actions)
+	name := func(kind string) string {
+		return filepath.Join(w.dir, fmt.Sprintf("shard-%05d-%s.npy", w.shards, kind))
+	}
+	if err := writeNpy(name("obs"), "<f4", []int{n, rl.NumPlanes, engine.NumKinds}, w.obs); err != nil {
+		return err
+	}
+	if err := writeNpy(name("action"), "<i4", []int{n}, w.actions); err != nil {
+		return err
+	}
+	if err := writeNpy(name("label"), "<i4", []int{n, NumLabels}, w.labels); err != nil {
+		return err
+	}
+	w.shards++
+	w.obs, w.actions, w.labels = w.obs[:0], w.actions[:0], w.labels[:0]
+	return nil
+}
+
+// writeNpy writes data, a slice of fixed-size numbers, as a version 1.0
+// .npy file: the magic, a header giving dtype and shape, padded so the
+// data starts on a 64-byte boundary, then the data in C order.
+func writeNpy(path, dtype string, shape []int, data any) error {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	tuple := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", dtype, tuple)
+	const prefix = 10 // magic, version and header length
+	pad := 63 - (prefix+len(header))%64
+	header += strings.Repeat(" ", pad) + "\n"
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+	if err := binary.Write(bw, binary.LittleEndian, data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,82 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/rl"
+)
+
+// readNpy checks the framing of an .npy file and returns its header and
+// the size of its data.
+func readNpy(t *testing.T, path string) (string, int) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "\x93NUMPY\x01\x00") {
+		t.Fatalf("%s: bad magic %q", path, b[:8])
+	}
+	n := 10 + int(binary.LittleEndian.Uint16(b[8:10]))
+	if n%64 != 0 || b[n-1] != '\n' {
+		t.Fatalf("%s: header ends at %d", path, n)
+	}
+	return strings.TrimSpace(string(b[10:n])), len(b) - n
+}
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 10 {
+		s := Sample{Action: i}
+		s.Obs[rl.PlaneHand][i] = 1
+		s.Labels[LabelSeat] = int32(i % 4)
+		if err := w.Write(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Shards() != 3 {
+		t.Fatalf("%d shards, want 3", w.Shards())
+	}
+
+	for shard, n := range []int{4, 4, 2} {
+		tests := []struct {
+			kind, shape string
+			size        int
+		}{
+			{"obs", fmt.Sprintf("(%d, %d, %d)", n, rl.NumPlanes, engine.NumKinds), 4 * n * rl.NumPlanes * engine.NumKinds},
+			{"action", fmt.Sprintf("(%d,)", n), 4 * n},
+			{"label", fmt.Sprintf("(%d, %d)", n, NumLabels), 4 * n * NumLabels},
+		}
+		for _, tt := range tests {
+			path := filepath.Join(dir, fmt.Sprintf("shard-%05d-%s.npy", shard, tt.kind))
+			header, size := readNpy(t, path)
+			if !strings.Contains(header, "'shape': "+tt.shape) {
+				t.Errorf("%s: header %s, want shape %s", path, header, tt.shape)
+			}
+			if size != tt.size {
+				t.Errorf("%s: %d bytes of data, want %d", path, size, tt.size)
+			}
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "shard-00002-action.npy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := binary.LittleEndian.Uint32(b[len(b)-4:]); got != 9 {
+		t.Errorf("last action %d, want 9", got)
+	}
+}
@@ -0,0 +1,147 @@
+package engine
+
+import "fmt"
+
+// NumKinds is the number of tile kinds in riichi mahjong: three suits of
+// nine and seven honors.
+const NumKinds = 34
+
+// Index returns the tile's kind index: 0–8 for 1m–9m, 9–17 for pins,
+// 18–26 for sous and 27–33 for E S W N and the white, green and red
+// dragons. Red fives share the index of the fives. Flowers, jokers and
+// invalid tiles return -1.
+func (t Tile) Index() int {
+	switch {
+	case t.IsRed():
+		return int(t.Suit())*9 + 4
+	case t.IsNumbered() && t.Rank() >= 1 && t.Rank() <= 9:
+		return int(t.Suit())*9 + t.Rank() - 1
+	case t.IsHonor():
+		return 27 + t.Rank() - 1
+	}
+	return -1
+}
+
+// TileAt returns the plain tile of kind index i, the inverse of
+// Tile.Index. It panics if i is out of range.
+func TileAt(i int) Tile {
+	if i < 0 || i >= NumKinds {
+		panic(fmt.Sprintf("engine: tile index %d out of range", i))
+	}
+	return Tile(uint8(i/9)<<4 | uint8(i%9+1))
+}
+
+// TileCounts is a histogram of tile kinds. Kinds counts every copy,
+// red fives included; Red counts how many of the fives in each numbered
+// suit (m, p, s) are red.
+type TileCounts struct {
+	Kinds [NumKinds]uint8
+	Red   [3]uint8
+}
+
+// CountTiles builds the histogram of tiles. It fails on tiles outside the
+// 34 kinds and on a fifth copy of any kind.
+func CountTiles(tiles []Tile) (TileCounts, error) {
+	var c TileCounts
+	for _, t := range tiles {
+		if err := c.Add(t); err != nil {
+			return TileCounts{}, err
+		}
+	}
+	return c, nil
+}
+
+// Add counts one more copy of t.
+func (c *TileCounts) Add(t Tile) error {
+	i := t.Index()
+	if i < 0 {
+		return fmt.Errorf("tile %s has no kind index", t)
+	}
+	if c.Kinds[i] >= 4 {
+		return fmt.Errorf("fifth copy of %s", TileAt(i))
+	}
+	c.Kinds[i]++
+	if t.IsRed() {
+		c.Red[t.Suit()]++
+	}
+	return nil
+}
+
+// Remove takes one copy of t away. A red five only matches a red five
+// and a plain five only a plain one.
+func (c *TileCounts) Remove(t Tile) error {
+	i := t.Index()
+	if i < 0 {
+		return fmt.Errorf("tile %s has no kind index", t)
+	}
+	red := 0
+	if t.IsNumbered() && i%9 == 4 {
+		red = int(c.Red[t.Suit()])
+	}
+	switch {
+	case t.IsRed() && red == 0:
+		return fmt.Errorf("no %s to remove", t)
+	case !t.IsRed() && int(c.Kinds[i]) <= red:
+		return fmt.Errorf("no %s to remove", t)
+	}
+	c.Kinds[i]--
+	if t.IsRed() {
+		c.Red[t.Suit()]--
+	}
+	return nil
+}
+
+// Count returns the number of copies of t's kind, red fives included.
+func (c TileCounts) Count(t Tile) int {
+	i := t.Index()
+	if i < 0 {
+		return 0
+	}
+	return int(c.Kinds[i])
+}
+
+// Len returns the total number of tiles.
+func (c TileCounts) Len() int {
+	n := 0
+	for _, k := range c.Kinds {
+		n += int(k)
+	}
+	return n
+}
+
+// Tiles lists the counted tiles in canonical order, red fives before the
+// plain fives of their suit.
+func (c TileCounts) Tiles() []Tile {
+	tiles := make([]Tile, 0, c.Len())
+	for i, k := range c.Kinds {
+		n := int(k)
+		if i < 27 && i%9 == 4 {
+			red := min(int(c.Red[i/9]), n)
+			for range red {
+				five, _ := NewRedFive(Suit(i / 9))
+				tiles = append(tiles, five)
+			}
+			n -= red
+		}
+		for range n {
+			tiles = append(tiles, TileAt(i))
+		}
+	}
+	return tiles
+}
+
+// Validate checks that no kind has more than four copies and that every
+// red five is also counted as a five.
+func (c TileCounts) Validate() error {
+	for i, k := range c.Kinds {
+		if k > 4 {
+			return fmt.Errorf("%d copies of %s", k, TileAt(i))
+		}
+	}
+	for s, r := range c.Red {
+		if five := c.Kinds[s*9+4]; r > five {
+			return fmt.Errorf("%d red fives but %d fives in %s", r, five, Suit(s))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTileIndex(t *testing.T) {
+	for i := range NumKinds {
+		if got := TileAt(i).Index(); got != i {
+			t.Errorf("TileAt(%d).Index() = %d", i, got)
+		}
+	}
+
+	tests := []struct {
+		tile string
+		want int
+	}{
+		{"1m", 0},
+		{"0m", 4},
+		{"9p", 17},
+		{"0s", 22},
+		{"1z", 27},
+		{"7z", 33},
+	}
+	for _, tt := range tests {
+		tile, err := ParseTile(tt.tile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tile.Index(); got != tt.want {
+			t.Errorf("%s.Index() = %d, want %d", tt.tile, got, tt.want)
+		}
+		if got := tile.SetDora(true).SetUra(true).Index(); got != tt.want {
+			t.Errorf("%s with dora marks: Index() = %d, want %d", tt.tile, got, tt.want)
+		}
+	}
+	if got := NewFlower().Index(); got != -1 {
+		t.Errorf("flower Index() = %d", got)
+	}
+}
+
+func TestCountTiles(t *testing.T) {
+	tiles := mustTiles(t, "5055m19p0s1177z")
+	c, err := CountTiles(tiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != len(tiles) {
+		t.Errorf("Len = %d", c.Len())
+	}
+	if c.Kinds[4] != 4 || c.Red != [3]uint8{1, 0, 1} || c.Kinds[27] != 2 {
+		t.Errorf("counts = %+v", c)
+	}
+	if got := c.Count(mustTiles(t, "5s")[0]); got != 1 {
+		t.Errorf("Count(5s) = %d", got)
+	}
+	want := mustTiles(t, "0555m19p0s1177z")
+	if got := c.Tiles(); !slices.Equal(got, want) {
+		t.Errorf("Tiles = %v, want %v", got, want)
+	}
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := CountTiles(mustTiles(t, "11111z")); err == nil {
+		t.Error("fifth copy accepted")
+	}
+	if _, err := CountTiles([]Tile{NewJoker()}); err == nil {
+		t.Error("joker accepted")
+	}
+}
+
+func TestTileCountsRemove(t *testing.T) {
+	c, err := CountTiles(mustTiles(t, "05m"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, red := mustTiles(t, "5m")[0], mustTiles(t, "0m")[0]
+
+	if err := c.Remove(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Remove(plain); err == nil {
+		t.Error("removed a plain five that was red")
+	}
+	if err := c.Remove(red); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Remove(red); err == nil {
+		t.Error("removed a missing red five")
+	}
+	if c != (TileCounts{}) {
+		t.Errorf("counts left: %+v", c)
+	}
+	if err := c.Remove(mustTiles(t, "1z")[0]); err == nil {
+		t.Error("removed a missing tile")
+	}
+}
+
+func TestTileCountsValidate(t *testing.T) {
+	var c TileCounts
+	c.Kinds[3] = 5
+	if err := c.Validate(); err == nil {
+		t.Error("five copies passed")
+	}
+	c = TileCounts{}
+	c.Red[1] = 1
+	if err := c.Validate(); err == nil {
+		t.Error("red five without a five passed")
+	}
+}
@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EventLogVersion is the schema version written by WriteEventLog. Readers
+// accept any version up to this one.
+const EventLogVersion = 1
+
+// eventLogFormat names the header line so other JSON-lines files are not
+// mistaken for event logs.
+const eventLogFormat = "mahjong-events"
+
+// An event log is a JSON-lines file: a header line
+//
+//	{"format":"mahjong-events","version":1}
+//
+// followed by one event per line. Tiles are written in compact notation
+// ("0m" is a red five); dora and ura flags are not part of the log.
+type eventLogHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// eventJSON is the wire form of Event. Payloads that do not apply to the
+// event type are left out.
+type eventJSON struct {
+	Type      EventType        `json:"type"`
+	Seat      int              `json:"seat"`
+	Tile      *Tile            `json:"tile,omitempty"`
+	Tsumogiri bool             `json:"tsumogiri,omitempty"`
+	Meld      *Meld            `json:"meld,omitempty"`
+	Game      *GameStart       `json:"game,omitempty"`
+	Round     *RoundStart      `json:"round,omitempty"`
+	Win       *WinResult       `json:"win,omitempty"`
+	Ryuukyoku *RyuukyokuResult `json:"ryuukyoku,omitempty"`
+	End       *GameEnd         `json:"end,omitempty"`
+	Score     *ScoreChange     `json:"score,omitempty"`
+}
+
+// hasTile reports whether Event.Tile is written for the type. A win
+// carries its tile in the WinResult.
+func (t EventType) hasTile() bool {
+	return t == EventDrawTile || t == EventDiscard || t == EventDora
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	if e.Type > EventScoreChange {
+		return nil, fmt.Errorf("unknown event type %d", e.Type)
+	}
+	out := eventJSON{
+		Type:      e.Type,
+		Seat:      e.Seat,
+		Tsumogiri: e.Tsumogiri,
+		Meld:      e.Meld,
+		Game:      e.Game,
+		Round:     e.Round,
+		Win:       e.Win,
+		Ryuukyoku: e.Ryuukyoku,
+		End:       e.End,
+		Score:     e.Score,
+	}
+	if e.Type.hasTile() {
+		out.Tile = &e.Tile
+	}
+	return json.Marshal(out)
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var in eventJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*e = Event{
+		Type:      in.Type,
+		Seat:      in.Seat,
+		Tsumogiri: in.Tsumogiri,
+		Meld:      in.Meld,
+		Game:      in.Game,
+		Round:     in.Round,
+		Win:       in.Win,
+		Ryuukyoku: in.Ryuukyoku,
+		End:       in.End,
+		Score:     in.Score,
+	}
+	switch {
+	case in.Tile != nil:
+		e.Tile = *in.Tile
+	case e.Type.hasTile():
+		return fmt.Errorf("%s event without tile", e.Type)
+	case e.Type == EventWin && e.Win != nil:
+		e.Tile = e.Win.Tile
+	}
+	return e.checkPayload()
+}
+
+// checkPayload reports a missing payload for the event type.
+func (e Event) checkPayload() error {
+	var missing string
+	switch {
+	case e.Type == EventGameStart && e.Game == nil:
+		missing = "game"
+	case e.Type == EventRoundStart && e.Round == nil:
+		missing = "round"
+	case e.Type == EventCall && e.Meld == nil:
+		missing = "meld"
+	case e.Type == EventWin && e.Win == nil:
+		missing = "win"
+	case e.Type == EventRyuukyoku && e.Ryuukyoku == nil:
+		missing = "ryuukyoku"
+	case e.Type == EventGameEnd && e.End == nil:
+		missing = "end"
+	case e.Type == EventScoreChange && e.Score == nil:
+		missing = "score"
+	}
+	if missing != "" {
+		return fmt.Errorf("%s event without %s", e.Type, missing)
+	}
+	return nil
+}
+
+// SaveEventLog writes events to a log file.
+func SaveEventLog(path string, events []Event) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteEventLog(f, events); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteEventLog writes the header and one line per event.
+func WriteEventLog(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(eventLogHeader{Format: eventLogFormat, Version: EventLogVersion}); err != nil {
+		return err
+	}
+	for i, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadEventLog reads a log file written by SaveEventLog.
+func LoadEventLog(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := ReadEventLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// ReadEventLog reads an event log. Logs from a newer schema version are
+// rejected.
+func ReadEventLog(r io.Reader) ([]Event, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		events []Event
+		header bool
+	)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if !header {
+			var h eventLogHeader
+			if err := json.Unmarshal([]byte(text), &h); err != nil || h.Format != eventLogFormat {
+				return nil, fmt.Errorf("line %d: missing event log header", line)
+			}
+			if h.Version < 1 || h.Version > EventLogVersion {
+				return nil, fmt.Errorf("unsupported event log version %d (want 1-%d)", h.Version, EventLogVersion)
+			}
+			header = true
+			continue
+		}
+
+		var e Event
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("missing event log header")
+	}
+	return events, nil
+}
+
+func (t EventType) MarshalText() ([]byte, error) {
+	if t > EventScoreChange {
+		return nil, fmt.Errorf("unknown event type %d", t)
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *EventType) UnmarshalText(text []byte) error {
+	for v := EventGameStart; v <= EventScoreChange; v++ {
+		if string(text) == v.String() {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event type %q", text)
+}
+
+func (t Tile) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Tile) UnmarshalText(text []byte) error {
+	v, err := ParseTile(string(text))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+func (w Wind) MarshalText() ([]byte, error) {
+	if w > WindNorth {
+		return nil, fmt.Errorf("unknown wind %d", w)
+	}
+	return []byte(w.String()), nil
+}
+
+func (w *Wind) UnmarshalText(text []byte) error {
+	for v := WindEast; v <= WindNorth; v++ {
+		if string(text) == v.String() {
+			*w = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown wind %q", text)
+}
+
+func (k MeldKind) MarshalText() ([]byte, error) {
+	if k > MeldShouminkan {
+		return nil, fmt.Errorf("unknown meld kind %d", k)
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *MeldKind) UnmarshalText(text []byte) error {
+	for v := MeldChi; v <= MeldShouminkan; v++ {
+		if string(text) == v.String() {
+			*k = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown meld kind %q", text)
+}
+
+func (y Yaku) MarshalText() ([]byte, error) {
+	if y >= yakuCount {
+		return nil, fmt.Errorf("unknown yaku %d", y)
+	}
+	return []byte(y.String()), nil
+}
+
+func (y *Yaku) UnmarshalText(text []byte) error {
+	for v := range yakuCount {
+		if string(text) == v.String() {
+			*y = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown yaku %q", text)
+}
+
+func (r RyuukyokuReason) MarshalText() ([]byte, error) {
+	if r > RyuukyokuNagashiMangan {
+		return nil, fmt.Errorf("unknown ryuukyoku reason %d", r)
+	}
+	return []byte(r.String()), nil
+}
+
+func (r *RyuukyokuReason) UnmarshalText(text []byte) error {
+	for v := RyuukyokuExhaustive; v <= RyuukyokuNagashiMangan; v++ {
+		if string(text) == v.String() {
+			*r = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown ryuukyoku reason %q", text)
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadEventLog(t *testing.T) {
+	events, err := LoadEventLog("testdata/events.jsonl")
+	if err != nil {
+		t.Fatalf("LoadEventLog failed: %v", err)
+	}
+	if len(events) != 19 {
+		t.Fatalf("got %d events, want 19", len(events))
+	}
+	if events[0].Game.Players[2] != "天" || events[0].Game.Rules.MultipleRon != MultipleRonDouble {
+		t.Errorf("unexpected game start: %+v", events[0].Game)
+	}
+	if m := events[3].Meld; m.Kind != MeldAnkan || len(m.Tiles) != 4 {
+		t.Errorf("unexpected ankan: %+v", m)
+	}
+	if e := events[16]; e.Type != EventDiscard || e.Tile.String() != "0s" || !e.Tsumogiri {
+		t.Errorf("unexpected discard: %+v", e)
+	}
+	win := events[17]
+	if win.Tile.String() != "0s" || win.Win.Yaku[2].Yaku != YakuAkaDora || win.Win.Deltas != [4]int{-3900, 0, 0, 4900} {
+		t.Errorf("unexpected win: %+v %+v", win, win.Win)
+	}
+}
+
+func TestEventLog_RoundTrip(t *testing.T) {
+	events, err := LoadEventLog("testdata/events.jsonl")
+	if err != nil {
+		t.Fatalf("LoadEventLog failed: %v", err)
+	}
+	events = append(events, Event{Type: EventScoreChange, Score: &ScoreChange{Deltas: [4]int{-8000, 0, 0, 8000}, Reason: "chombo"}})
+
+	var buf bytes.Buffer
+	if err := WriteEventLog(&buf, events); err != nil {
+		t.Fatalf("WriteEventLog failed: %v", err)
+	}
+	got, err := ReadEventLog(&buf)
+	if err != nil {
+		t.Fatalf("ReadEventLog failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("round trip changed events:\n got  %+v\n want %+v", got, events)
+	}
+}
+
+func TestEventJSON_TileOnlyWhereUsed(t *testing.T) {
+	data, err := Event{Type: EventRiichi, Seat: 2}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"riichi","seat":2}` {
+		t.Errorf("riichi encoded as %s", data)
+	}
+
+	// The zero tile is a red 5m and must still be written for draws.
+	data, err = Event{Type: EventDrawTile, Seat: 1}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"draw","seat":1,"tile":"0m"}` {
+		t.Errorf("draw encoded as %s", data)
+	}
+}
+
+func TestReadEventLog_Errors(t *testing.T) {
+	header := `{"format":"mahjong-events","version":1}` + "\n"
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{"empty", ""},
+		{"no header", `{"type":"riichi","seat":0}`},
+		{"other format", `{"format":"something","version":1}`},
+		{"future version", `{"format":"mahjong-events","version":99}`},
+		{"unknown type", header + `{"type":"teleport","seat":0}`},
+		{"bad tile", header + `{"type":"draw","seat":0,"tile":"0z"}`},
+		{"draw without tile", header + `{"type":"draw","seat":0}`},
+		{"call without meld", header + `{"type":"call","seat":0}`},
+		{"unknown yaku", header + `{"type":"win","seat":0,"win":{"yaku":[{"yaku":"super luck","han":1}]}}`},
+		{"not json", header + `{"type":`},
+	}
+	for _, tt := range tests {
+		if _, err := ReadEventLog(strings.NewReader(tt.log)); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package engine
+
+import "fmt"
+
+// EventType identifies what happened in an Event.
+type EventType uint8
+
+const (
+	EventGameStart      EventType = iota // Game
+	EventRoundStart                      // Round
+	EventDrawTile                        // Seat draws Tile
+	EventDiscard                         // Seat discards Tile
+	EventCall                            // Seat calls Meld
+	EventRiichi                          // Seat declares riichi (before the discard)
+	EventRiichiAccepted                  // riichi discard passed, the stick is paid
+	EventDora                            // new dora indicator Tile
+	EventWin                             // Win
+	EventRyuukyoku                       // Ryuukyoku
+	EventGameEnd                         // End
+	EventScoreChange                     // Score, e.g. a chombo penalty
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventGameStart:
+		return "game_start"
+	case EventRoundStart:
+		return "round_start"
+	case EventDrawTile:
+		return "draw"
+	case EventDiscard:
+		return "discard"
+	case EventCall:
+		return "call"
+	case EventRiichi:
+		return "riichi"
+	case EventRiichiAccepted:
+		return "riichi_accepted"
+	case EventDora:
+		return "dora"
+	case EventWin:
+		return "win"
+	case EventRyuukyoku:
+		return "ryuukyoku"
+	case EventGameEnd:
+		return "game_end"
+	case EventScoreChange:
+		return "score_change"
+	default:
+		return "?"
+	}
+}
+
+// Event is one step of a game. Only the fields relevant to Type are set;
+// seats are absolute (0–3, seat 0 is the first dealer).
+type Event struct {
+	Type      EventType
+	Seat      int
+	Tile      Tile
+	Tsumogiri bool // discard of the tile just drawn
+	Meld      *Meld
+
+	Game      *GameStart
+	Round     *RoundStart
+	Win       *WinResult
+	Ryuukyoku *RyuukyokuResult
+	End       *GameEnd
+	Score     *ScoreChange
+}
+
+func (e Event) String() string {
+	switch e.Type {
+	case EventDrawTile, EventDiscard:
+		return fmt.Sprintf("%s seat=%d %s", e.Type, e.Seat, e.Tile)
+	case EventCall:
+		return fmt.Sprintf("%s seat=%d %s", e.Type, e.Seat, e.Meld)
+	case EventDora:
+		return fmt.Sprintf("%s %s", e.Type, e.Tile)
+	case EventRiichi, EventRiichiAccepted:
+		return fmt.Sprintf("%s seat=%d", e.Type, e.Seat)
+	default:
+		return e.Type.String()
+	}
+}
+
+// GameStart describes the table before the first round.
+type GameStart struct {
+	Players [4]string `json:"players"`
+	Rules   Rules     `json:"rules"`
+}
+
+// RoundStart is the state dealt at the start of a round (kyoku).
+type RoundStart struct {
+	Wind          Wind      `json:"wind"`   // prevalent wind
+	Number        int       `json:"number"` // 0–3 within the wind; East 1 is 0
+	Honba         int       `json:"honba"`
+	RiichiSticks  int       `json:"riichi_sticks"`
+	Dealer        int       `json:"dealer"`
+	Scores        [4]int    `json:"scores"`
+	DoraIndicator Tile      `json:"dora_indicator"`
+	Hands         [4][]Tile `json:"hands"`
+}
+
+// WinResult is a single winner of a tsumo or ron. A double ron produces
+// two EventWin events.
+type WinResult struct {
+	Seat           int       `json:"seat"`
+	From           int       `json:"from"` // discarder; equal to Seat for tsumo
+	Tile           Tile      `json:"tile"`
+	Hand           []Tile    `json:"hand"` // concealed tiles including Tile
+	Melds          []Meld    `json:"melds,omitempty"`
+	Han            int       `json:"han"`
+	Fu             int       `json:"fu"`
+	Points         int       `json:"points"`
+	Yaku           []YakuHan `json:"yaku"`
+	DoraIndicators []Tile    `json:"dora_indicators"`
+	UraIndicators  []Tile    `json:"ura_indicators,omitempty"`
+	Deltas         [4]int    `json:"deltas"`
+}
+
+// IsTsumo reports whether the win was self-drawn.
+func (w WinResult) IsTsumo() bool {
+	return w.Seat == w.From
+}
+
+// RyuukyokuReason is why a round ended without a winner.
+type RyuukyokuReason uint8
+
+const (
+	RyuukyokuExhaustive    RyuukyokuReason = iota // wall ran out
+	RyuukyokuKyuushu                              // nine terminals and honors
+	RyuukyokuSuufon                               // four same winds discarded
+	RyuukyokuSuuchaRiichi                         // four riichi
+	RyuukyokuSuukaikan                            // four kans by different players
+	RyuukyokuSanchahou                            // triple ron
+	RyuukyokuNagashiMangan                        // exhaustive draw with nagashi mangan
+)
+
+func (r RyuukyokuReason) String() string {
+	switch r {
+	case RyuukyokuExhaustive:
+		return "exhaustive"
+	case RyuukyokuKyuushu:
+		return "kyuushu"
+	case RyuukyokuSuufon:
+		return "suufon"
+	case RyuukyokuSuuchaRiichi:
+		return "suucha_riichi"
+	case RyuukyokuSuukaikan:
+		return "suukaikan"
+	case RyuukyokuSanchahou:
+		return "sanchahou"
+	case RyuukyokuNagashiMangan:
+		return "nagashi_mangan"
+	default:
+		return "?"
+	}
+}
+
+// RyuukyokuResult ends a round without a winner.
+type RyuukyokuResult struct {
+	Reason RyuukyokuReason `json:"reason"`
+	Tenpai [4]bool         `json:"tenpai"`
+	Deltas [4]int          `json:"deltas"`
+}
+
+// GameEnd holds the final scores.
+type GameEnd struct {
+	Scores [4]int `json:"scores"`
+}
+
+// ScoreChange moves points outside a round result, e.g. a chombo penalty
+// or a manual correction.
+type ScoreChange struct {
+	Deltas [4]int `json:"deltas"`
+	Reason string `json:"reason,omitempty"`
+}
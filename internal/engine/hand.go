@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 	"unicode"
 )
 
@@ -92,3 +95,66 @@ func ParseHandCompact(input string) ([]Tile, error) {
 
 	return tiles, nil
 }
+
+// Notation selects how FormatHand writes tiles.
+type Notation uint8
+
+const (
+	// NotationCompact is the canonical form, "123m406p789s11z": red fives
+	// are written 0 and sorted where the fives go.
+	NotationCompact Notation = iota
+	// NotationTenhou sorts digits as written, so red fives lead their
+	// suit: "123m046p789s11z".
+	NotationTenhou
+)
+
+// FormatHandCompact writes tiles in canonical compact notation, the
+// inverse of ParseHandCompact up to tile order.
+func FormatHandCompact(tiles []Tile) string {
+	return FormatHand(tiles, NotationCompact)
+}
+
+// FormatHand sorts tiles by suit (m, p, s, z) and rank and writes them in
+// the given notation. Dora marks are dropped. Flowers and jokers, which
+// compact notation has no digit for, come last as F and J.
+func FormatHand(tiles []Tile, n Notation) string {
+	sorted := slices.Clone(tiles)
+	sortTiles(sorted, n)
+	return formatCompact(sorted)
+}
+
+// formatCompact writes tiles in the order given, with one suit letter per
+// run of tiles in the same suit.
+func formatCompact(tiles []Tile) string {
+	var b strings.Builder
+	for i, t := range tiles {
+		if !t.IsNumbered() && !t.IsHonor() {
+			b.WriteString(t.String())
+			continue
+		}
+		b.WriteByte(byte('0' + t.Rank()))
+		if i+1 == len(tiles) || tiles[i+1].Suit() != t.Suit() || !tiles[i+1].IsNumbered() && !tiles[i+1].IsHonor() {
+			b.WriteString(t.Suit().String())
+		}
+	}
+	return b.String()
+}
+
+func sortTiles(tiles []Tile, n Notation) {
+	slices.SortStableFunc(tiles, func(a, b Tile) int {
+		return cmp.Compare(sortKey(a, n), sortKey(b, n))
+	})
+}
+
+// sortKey orders tiles by suit, then rank, with a red five just before
+// the plain fives, or for NotationTenhou before the ones.
+func sortKey(t Tile, n Notation) int {
+	rank := 2 * t.Rank()
+	switch {
+	case t.IsRed() && n != NotationTenhou:
+		rank = 2*5 - 1
+	case !t.IsNumbered() && !t.IsHonor():
+		return 4<<5 | rank // flowers and jokers last
+	}
+	return int(t.Suit())<<5 | rank
+}
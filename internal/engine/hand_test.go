@@ -108,3 +108,55 @@ func TestParseHandCompact_Content(t *testing.T) {
 		t.Errorf("ParseHandCompact(%q) = %v, want %v", input, got, want)
 	}
 }
+
+func TestFormatHand(t *testing.T) {
+	tests := []struct {
+		input   string
+		compact string
+		tenhou  string
+	}{
+		{"11z789s604p321m", "123m406p789s11z", "123m046p789s11z"},
+		{"5505m", "0555m", "0555m"},
+		{"7654321z", "1234567z", "1234567z"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		tiles, err := ParseHandCompact(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatHandCompact(tiles); got != tt.compact {
+			t.Errorf("FormatHandCompact(%s) = %q, want %q", tt.input, got, tt.compact)
+		}
+		if got := FormatHand(tiles, NotationTenhou); got != tt.tenhou {
+			t.Errorf("FormatHand(%s, tenhou) = %q, want %q", tt.input, got, tt.tenhou)
+		}
+	}
+}
+
+func TestFormatHandRoundTrip(t *testing.T) {
+	wall, err := BuildWall(DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wall, err = ShuffleWall(wall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+14 <= len(wall); i += 14 {
+		hand := wall[i : i+14]
+		s := FormatHandCompact(hand)
+		back, err := ParseHandCompact(s)
+		if err != nil {
+			t.Fatalf("ParseHandCompact(%q): %v", s, err)
+		}
+		if again := FormatHandCompact(back); again != s {
+			t.Errorf("round trip %q -> %q", s, again)
+		}
+		want := slices.Clone(hand)
+		sortTiles(want, NotationCompact)
+		if !slices.Equal(back, want) {
+			t.Errorf("ParseHandCompact(%q) = %v, want %v", s, back, want)
+		}
+	}
+}
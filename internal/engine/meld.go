@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MeldKind is the type of an open or closed meld.
+type MeldKind uint8
+
+const (
+	MeldChi        MeldKind = iota // sequence called from the player on the left
+	MeldPon                        // triplet called from anyone
+	MeldKan                        // open kan on a discard (daiminkan)
+	MeldAnkan                      // closed kan from the hand
+	MeldShouminkan                 // kan made by adding a drawn tile to a pon
+)
+
+func (k MeldKind) String() string {
+	switch k {
+	case MeldChi:
+		return "chi"
+	case MeldPon:
+		return "pon"
+	case MeldKan:
+		return "kan"
+	case MeldAnkan:
+		return "ankan"
+	case MeldShouminkan:
+		return "shouminkan"
+	default:
+		return "?"
+	}
+}
+
+// IsKan reports whether the meld has four tiles.
+func (k MeldKind) IsKan() bool {
+	return k == MeldKan || k == MeldAnkan || k == MeldShouminkan
+}
+
+// Meld is a called (or closed kan) group of tiles.
+//
+// Seats are absolute (0–3). For chi, pon and open kan, From is the seat
+// the called tile came from; for a closed kan it is the owner's seat. For
+// a shouminkan, Called is the tile added to the pon and From is the seat
+// the original pon was called from.
+type Meld struct {
+	Kind   MeldKind `json:"kind"`
+	Tiles  []Tile   `json:"tiles"` // every tile of the meld, including Called
+	Called Tile     `json:"called"`
+	From   int      `json:"from"`
+}
+
+// IsOpen reports whether the meld opens the hand. Only a closed kan keeps
+// the hand concealed.
+func (m Meld) IsOpen() bool {
+	return m.Kind != MeldAnkan
+}
+
+func (m Meld) String() string {
+	var b strings.Builder
+	for _, t := range m.Tiles {
+		b.WriteString(t.String())
+	}
+	return fmt.Sprintf("%s(%s)", m.Kind, b.String())
+}
@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestMeld(t *testing.T) {
+	five := mustNewTile(t, SuitPinzu, 5)
+	red, _ := NewRedFive(SuitPinzu)
+
+	pon := Meld{Kind: MeldPon, Tiles: []Tile{red, five, five}, Called: five, From: 2}
+	if !pon.IsOpen() || pon.Kind.IsKan() {
+		t.Errorf("pon: IsOpen=%v IsKan=%v", pon.IsOpen(), pon.Kind.IsKan())
+	}
+	if got := pon.String(); got != "pon(0p5p5p)" {
+		t.Errorf("String() = %q, want pon(0p5p5p)", got)
+	}
+
+	ankan := Meld{Kind: MeldAnkan, Tiles: []Tile{red, five, five, five}, Called: five}
+	if ankan.IsOpen() || !ankan.Kind.IsKan() {
+		t.Errorf("ankan: IsOpen=%v IsKan=%v", ankan.IsOpen(), ankan.Kind.IsKan())
+	}
+}
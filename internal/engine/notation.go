@@ -0,0 +1,341 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hand is a hand with the context needed to score it, as described by
+// ParseHand. Meld sources are relative to the owner sitting at seat 0:
+// From is 3 for the player on the left, 2 across and 1 on the right.
+type Hand struct {
+	Concealed []Tile
+	Melds     []Meld
+	Winning   *Tile // the tile won on, not part of Concealed; nil if unknown
+
+	Dora []Tile // dora indicators
+	Ura  []Tile // ura dora indicators
+
+	RoundWind Wind
+	SeatWind  Wind
+
+	Tsumo        bool
+	Riichi       bool
+	DoubleRiichi bool
+	Ippatsu      bool
+	Rinshan      bool
+	Chankan      bool
+	Haitei       bool
+	Houtei       bool
+}
+
+// Tiles returns the concealed tiles followed by the winning tile.
+func (h Hand) Tiles() []Tile {
+	tiles := slices.Clone(h.Concealed)
+	if h.Winning != nil {
+		tiles = append(tiles, *h.Winning)
+	}
+	return tiles
+}
+
+// Size counts the hand's tiles with each meld as three, including the
+// winning tile: 14 for a complete hand.
+func (h Hand) Size() int {
+	n := len(h.Concealed) + 3*len(h.Melds)
+	if h.Winning != nil {
+		n++
+	}
+	return n
+}
+
+// Relative seats of a meld's source in a Hand.
+const (
+	fromShimocha = 1
+	fromToimen   = 2
+	fromKamicha  = 3
+)
+
+// ParseHand parses a hand in extended compact notation, e.g.
+//
+//	123m456p789s11z +5p [555z] (1111s) d:3m r:E s:S tsumo riichi
+//
+// Space-separated fields are:
+//   - compact tiles as in ParseHandCompact: concealed tiles
+//   - +TILE: the winning tile
+//   - [TILES]: an open meld. Three alike is a pon, a run a chi, four alike
+//     a kan. The first tile written is the called one. A trailing <, ^ or >
+//     says the tile came from the left (the default), across or the right.
+//   - (TILES): a closed kan
+//   - d:TILES and u:TILES: dora and ura dora indicators
+//   - r:WIND and s:WIND: round and seat wind as E/S/W/N or 1z–4z;
+//     both default to East
+//   - tsumo or ron (the default), riichi, wriichi (double riichi), ippatsu,
+//     rinshan, chankan, haitei, houtei
+//
+// ParseHand only rejects hands no table could hold, with more than four of
+// a tile kind. It does not check the hand's size (see Hand.Size) or whether
+// the flags make sense together; that is left to scoring.
+func ParseHand(input string) (Hand, error) {
+	var h Hand
+	for _, field := range strings.Fields(input) {
+		if err := h.parseField(field); err != nil {
+			return Hand{}, fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	if err := h.validate(); err != nil {
+		return Hand{}, err
+	}
+	return h, nil
+}
+
+func (h *Hand) parseField(field string) error {
+	switch strings.ToLower(field) {
+	case "tsumo":
+		h.Tsumo = true
+		return nil
+	case "ron":
+		h.Tsumo = false
+		return nil
+	case "riichi":
+		h.Riichi = true
+		return nil
+	case "wriichi":
+		h.Riichi, h.DoubleRiichi = true, true
+		return nil
+	case "ippatsu":
+		h.Ippatsu = true
+		return nil
+	case "rinshan":
+		h.Rinshan = true
+		return nil
+	case "chankan":
+		h.Chankan = true
+		return nil
+	case "haitei":
+		h.Haitei = true
+		return nil
+	case "houtei":
+		h.Houtei = true
+		return nil
+	}
+
+	if key, value, ok := strings.Cut(field, ":"); ok {
+		switch strings.ToLower(key) {
+		case "d":
+			tiles, err := ParseHandCompact(value)
+			h.Dora = append(h.Dora, tiles...)
+			return err
+		case "u":
+			tiles, err := ParseHandCompact(value)
+			h.Ura = append(h.Ura, tiles...)
+			return err
+		case "r":
+			w, err := parseWind(value)
+			h.RoundWind = w
+			return err
+		case "s":
+			w, err := parseWind(value)
+			h.SeatWind = w
+			return err
+		}
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	switch field[0] {
+	case '+':
+		if h.Winning != nil {
+			return fmt.Errorf("second winning tile")
+		}
+		tiles, err := ParseHandCompact(field[1:])
+		if err != nil {
+			return err
+		}
+		if len(tiles) != 1 {
+			return fmt.Errorf("winning tile must be a single tile")
+		}
+		h.Winning = &tiles[0]
+		return nil
+	case '[':
+		if !strings.HasSuffix(field, "]") {
+			return fmt.Errorf("unclosed meld")
+		}
+		m, err := parseOpenMeld(field[1 : len(field)-1])
+		h.Melds = append(h.Melds, m)
+		return err
+	case '(':
+		if !strings.HasSuffix(field, ")") {
+			return fmt.Errorf("unclosed kan")
+		}
+		tiles, err := ParseHandCompact(field[1 : len(field)-1])
+		if err != nil {
+			return err
+		}
+		if len(tiles) != 4 || !allAlike(tiles) {
+			return fmt.Errorf("closed kan needs four alike tiles")
+		}
+		h.Melds = append(h.Melds, Meld{Kind: MeldAnkan, Tiles: tiles, Called: tiles[3]})
+		return nil
+	}
+
+	tiles, err := ParseHandCompact(field)
+	h.Concealed = append(h.Concealed, tiles...)
+	return err
+}
+
+func parseOpenMeld(s string) (Meld, error) {
+	from := fromKamicha
+	switch {
+	case strings.HasSuffix(s, "<"):
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "^"):
+		from, s = fromToimen, s[:len(s)-1]
+	case strings.HasSuffix(s, ">"):
+		from, s = fromShimocha, s[:len(s)-1]
+	}
+
+	tiles, err := ParseHandCompact(s)
+	if err != nil {
+		return Meld{}, err
+	}
+	m := Meld{Tiles: tiles, From: from}
+	switch {
+	case len(tiles) == 3 && allAlike(tiles):
+		m.Kind = MeldPon
+	case len(tiles) == 3 && isRun(tiles):
+		if from != fromKamicha {
+			return Meld{}, fmt.Errorf("chi can only be called from the left")
+		}
+		m.Kind = MeldChi
+	case len(tiles) == 4 && allAlike(tiles):
+		m.Kind = MeldKan
+	default:
+		return Meld{}, fmt.Errorf("not a chi, pon or kan")
+	}
+	m.Called = tiles[0]
+	return m, nil
+}
+
+func parseWind(s string) (Wind, error) {
+	t, err := ParseTile(s)
+	if err != nil {
+		return 0, err
+	}
+	if !t.IsWind() {
+		return 0, fmt.Errorf("%s is not a wind", s)
+	}
+	return Wind(t.Rank() - 1), nil
+}
+
+// allAlike reports whether the tiles are all of one kind, red fives
+// counting as fives.
+func allAlike(tiles []Tile) bool {
+	for _, t := range tiles[1:] {
+		if t.Plain() != tiles[0].Plain() {
+			return false
+		}
+	}
+	return true
+}
+
+// isRun reports whether the tiles form a sequence in one suit, in any
+// order.
+func isRun(tiles []Tile) bool {
+	ranks := make([]int, len(tiles))
+	for i, t := range tiles {
+		if !t.IsNumbered() || t.Suit() != tiles[0].Suit() {
+			return false
+		}
+		ranks[i] = t.Plain().Rank()
+	}
+	slices.Sort(ranks)
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i] != ranks[i-1]+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks that no tile kind is used more than four times.
+func (h Hand) validate() error {
+	counts := make(map[Tile]int)
+	seen := slices.Concat(h.Tiles(), h.Dora, h.Ura)
+	for _, m := range h.Melds {
+		seen = append(seen, m.Tiles...)
+	}
+	for _, t := range seen {
+		counts[t.Plain()]++
+		if counts[t.Plain()] > 4 {
+			return fmt.Errorf("more than four %s", t.Plain())
+		}
+	}
+	return nil
+}
+
+// String writes the hand in the notation ParseHand reads, with concealed
+// tiles sorted. Winds are left out when East.
+func (h Hand) String() string {
+	var fields []string
+	if len(h.Concealed) > 0 {
+		fields = append(fields, FormatHandCompact(h.Concealed))
+	}
+	if h.Winning != nil {
+		fields = append(fields, "+"+h.Winning.String())
+	}
+	for _, m := range h.Melds {
+		fields = append(fields, formatMeld(m))
+	}
+	if len(h.Dora) > 0 {
+		fields = append(fields, "d:"+formatCompact(h.Dora))
+	}
+	if len(h.Ura) > 0 {
+		fields = append(fields, "u:"+formatCompact(h.Ura))
+	}
+	if h.RoundWind != WindEast {
+		fields = append(fields, "r:"+h.RoundWind.String())
+	}
+	if h.SeatWind != WindEast {
+		fields = append(fields, "s:"+h.SeatWind.String())
+	}
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{h.Tsumo, "tsumo"},
+		{h.Riichi && !h.DoubleRiichi, "riichi"},
+		{h.DoubleRiichi, "wriichi"},
+		{h.Ippatsu, "ippatsu"},
+		{h.Rinshan, "rinshan"},
+		{h.Chankan, "chankan"},
+		{h.Haitei, "haitei"},
+		{h.Houtei, "houtei"},
+	}
+	for _, f := range flags {
+		if f.set {
+			fields = append(fields, f.name)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// formatMeld writes a meld as ParseHand reads it: a closed kan in
+// parentheses, an open meld in brackets with the called tile first.
+func formatMeld(m Meld) string {
+	if !m.IsOpen() {
+		return "(" + FormatHandCompact(m.Tiles) + ")"
+	}
+	rest := slices.Clone(m.Tiles)
+	if i := slices.Index(rest, m.Called); i >= 0 {
+		rest = slices.Delete(rest, i, i+1)
+	}
+	sortTiles(rest, NotationCompact)
+	s := formatCompact(append([]Tile{m.Called}, rest...))
+	switch m.From {
+	case fromToimen:
+		s += "^"
+	case fromShimocha:
+		s += ">"
+	}
+	return "[" + s + "]"
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func mustTiles(t *testing.T, s string) []Tile {
+	t.Helper()
+	tiles, err := ParseHandCompact(s)
+	if err != nil {
+		t.Fatalf("ParseHandCompact(%q): %v", s, err)
+	}
+	return tiles
+}
+
+func TestParseHand(t *testing.T) {
+	h, err := ParseHand("123m456p789s11z +5p [555z] (1111s) d:3m r:E s:S tsumo riichi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(h.Concealed, mustTiles(t, "123m456p789s11z")) {
+		t.Errorf("Concealed = %v", h.Concealed)
+	}
+	if h.Winning == nil || *h.Winning != mustTiles(t, "5p")[0] {
+		t.Errorf("Winning = %v", h.Winning)
+	}
+	if len(h.Melds) != 2 {
+		t.Fatalf("Melds = %v", h.Melds)
+	}
+	if m := h.Melds[0]; m.Kind != MeldPon || m.From != fromKamicha || m.Called != mustTiles(t, "5z")[0] {
+		t.Errorf("pon = %+v", m)
+	}
+	if m := h.Melds[1]; m.Kind != MeldAnkan || m.From != 0 || len(m.Tiles) != 4 {
+		t.Errorf("ankan = %+v", m)
+	}
+	if !slices.Equal(h.Dora, mustTiles(t, "3m")) || len(h.Ura) != 0 {
+		t.Errorf("Dora = %v, Ura = %v", h.Dora, h.Ura)
+	}
+	if h.RoundWind != WindEast || h.SeatWind != WindSouth {
+		t.Errorf("winds = %v %v", h.RoundWind, h.SeatWind)
+	}
+	if !h.Tsumo || !h.Riichi || h.Ippatsu || h.DoubleRiichi {
+		t.Errorf("flags = %+v", h)
+	}
+	if got := h.Size(); got != 18 {
+		t.Errorf("Size = %d", got)
+	}
+}
+
+func TestParseHandMelds(t *testing.T) {
+	tests := []struct {
+		input  string
+		kind   MeldKind
+		from   int
+		called string
+	}{
+		{"[312m]", MeldChi, fromKamicha, "3m"},
+		{"[505p^]", MeldPon, fromToimen, "5p"},
+		{"[777z>]", MeldPon, fromShimocha, "7z"},
+		{"[9999s<]", MeldKan, fromKamicha, "9s"},
+		{"(0555m)", MeldAnkan, 0, "5m"},
+	}
+	for _, tt := range tests {
+		h, err := ParseHand("11z " + tt.input)
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		m := h.Melds[0]
+		if m.Kind != tt.kind || m.From != tt.from || m.Called.Plain().String() != tt.called {
+			t.Errorf("%s = %+v", tt.input, m)
+		}
+	}
+}
+
+func TestParseHandFlags(t *testing.T) {
+	h, err := ParseHand("123456789m1122z +1z wriichi ippatsu u:4p5p d:0s r:2z s:w ron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Riichi || !h.DoubleRiichi || !h.Ippatsu || h.Tsumo {
+		t.Errorf("flags = %+v", h)
+	}
+	if !slices.Equal(h.Ura, mustTiles(t, "4p5p")) || !h.Dora[0].IsRed() {
+		t.Errorf("Dora = %v, Ura = %v", h.Dora, h.Ura)
+	}
+	if h.RoundWind != WindSouth || h.SeatWind != WindWest || h.Size() != 14 {
+		t.Errorf("winds = %v %v, size %d", h.RoundWind, h.SeatWind, h.Size())
+	}
+	if got := h.Tiles(); len(got) != 14 || got[13] != *h.Winning {
+		t.Errorf("Tiles = %v", got)
+	}
+}
+
+func TestParseHandErrors(t *testing.T) {
+	tests := []string{
+		"123m +",
+		"123m +12m",
+		"123m +1m +2m",
+		"123m [124m]",
+		"123m [123m^]",
+		"123m [55z",
+		"123m (555z)",
+		"123m (5555z",
+		"123m x:1m",
+		"123m r:5z",
+		"123m s:1m",
+		"123m bogus",
+		"1111m [111m]",
+		"0555m d:5m",
+		"123m d:9z",
+	}
+	for _, input := range tests {
+		if h, err := ParseHand(input); err == nil {
+			t.Errorf("ParseHand(%q) = %+v, want error", input, h)
+		}
+	}
+}
+
+func TestHandString(t *testing.T) {
+	tests := []string{
+		"123m456p789s11z +5p [555z] (1111s) d:3m s:S tsumo riichi",
+		"11z [312m] [505p^] [7777z>] r:S s:W",
+		"2234067p11s +8p d:10m u:4p wriichi ippatsu houtei",
+		"+1z",
+	}
+	for _, s := range tests {
+		h, err := ParseHand(s)
+		if err != nil {
+			t.Fatalf("ParseHand(%q): %v", s, err)
+		}
+		if got := h.String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// liveWallSize is the number of draws available after the deal: 136 tiles
+// minus 52 dealt and the 14-tile dead wall.
+const liveWallSize = 70
+
+// Discard is one tile in a player's pond.
+type Discard struct {
+	Tile      Tile `json:"tile"`
+	Tsumogiri bool `json:"tsumogiri,omitempty"`
+	Riichi    bool `json:"riichi,omitempty"` // turned sideways to declare riichi
+	Called    bool `json:"called,omitempty"` // taken by another player's chi, pon or kan
+	Seq       int  `json:"seq"`              // discards made in the round before this one, by any seat
+}
+
+// Round is the table state inside one round, as seen by an observer with
+// full information.
+type Round struct {
+	Wind           Wind         `json:"wind"`
+	Number         int          `json:"number"`
+	Honba          int          `json:"honba"`
+	RiichiSticks   int          `json:"riichi_sticks"`
+	Dealer         int          `json:"dealer"`
+	DoraIndicators []Tile       `json:"dora_indicators"`
+	Hands          [4][]Tile    `json:"hands"`
+	Melds          [4][]Meld    `json:"melds"`
+	Discards       [4][]Discard `json:"discards"`
+	Riichi         [4]bool      `json:"riichi"`     // riichi declared and paid
+	TilesLeft      int          `json:"tiles_left"` // draws left in the live wall
+
+	// Turn is the seat that acted last. A round ends with one or more
+	// wins (several ron on one tile) or a draw.
+	Turn  int              `json:"turn"`
+	Ended bool             `json:"ended,omitempty"`
+	Wins  []*WinResult     `json:"wins,omitempty"`
+	Draw  *RyuukyokuResult `json:"draw,omitempty"`
+
+	pendingRiichi [4]bool
+	lastDiscard   int // seat whose discard can be called, or -1
+}
+
+// Game is the state of a game after some prefix of its events.
+type Game struct {
+	Players  [4]string
+	Rules    Rules
+	Scores   [4]int
+	Round    *Round // nil before the first deal
+	Rounds   int    // rounds started so far
+	Finished bool
+}
+
+// NewGame returns an empty game with the default rules, ready for Apply.
+func NewGame() *Game {
+	return &Game{Rules: DefaultRules()}
+}
+
+// Replay applies events[:n] to a new game and returns the state. A
+// negative n replays everything.
+func Replay(events []Event, n int) (*Game, error) {
+	if n < 0 || n > len(events) {
+		n = len(events)
+	}
+	g := NewGame()
+	for i, e := range events[:n] {
+		if err := g.Apply(e); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", i, e, err)
+		}
+	}
+	return g, nil
+}
+
+// Apply advances the state by one event. It rejects events that do not
+// fit the current state, e.g. discarding a tile that is not in the hand.
+func (g *Game) Apply(e Event) error {
+	if err := e.checkPayload(); err != nil {
+		return err
+	}
+	if g.Finished {
+		return fmt.Errorf("game already ended")
+	}
+
+	switch e.Type {
+	case EventGameStart:
+		if g.Rounds > 0 {
+			return fmt.Errorf("game start after the first round")
+		}
+		g.Players = e.Game.Players
+		g.Rules = e.Game.Rules
+		return nil
+	case EventRoundStart:
+		return g.startRound(e.Round)
+	case EventScoreChange:
+		g.addScores(e.Score.Deltas)
+		return nil
+	case EventGameEnd:
+		g.Scores = e.End.Scores
+		g.Finished = true
+		return nil
+	}
+
+	r := g.Round
+	if r == nil {
+		return fmt.Errorf("no round in progress")
+	}
+	if r.Ended && !(e.Type == EventWin && r.moreRon(e.Win)) {
+		return fmt.Errorf("round already ended")
+	}
+	if e.Type != EventDora && (e.Seat < 0 || e.Seat > 3) {
+		return fmt.Errorf("invalid seat %d", e.Seat)
+	}
+
+	switch e.Type {
+	case EventDrawTile:
+		if r.TilesLeft == 0 {
+			return fmt.Errorf("wall is empty")
+		}
+		if len(r.Hands[e.Seat])%3 != 1 {
+			return fmt.Errorf("seat %d draws with %d tiles in hand", e.Seat, len(r.Hands[e.Seat]))
+		}
+		r.Hands[e.Seat] = append(r.Hands[e.Seat], e.Tile)
+		r.TilesLeft--
+		r.lastDiscard = -1
+
+	case EventDiscard:
+		if len(r.Hands[e.Seat])%3 != 2 {
+			return fmt.Errorf("seat %d discards with %d tiles in hand", e.Seat, len(r.Hands[e.Seat]))
+		}
+		i := slices.Index(r.Hands[e.Seat], e.Tile)
+		if i < 0 {
+			return fmt.Errorf("seat %d does not hold %s", e.Seat, e.Tile)
+		}
+		r.Hands[e.Seat] = slices.Delete(r.Hands[e.Seat], i, i+1)
+		d := Discard{Tile: e.Tile, Tsumogiri: e.Tsumogiri}
+		for _, pond := range r.Discards {
+			d.Seq += len(pond)
+		}
+		if r.pendingRiichi[e.Seat] && !r.sidewaysPlaced(e.Seat) {
+			d.Riichi = true
+		}
+		r.Discards[e.Seat] = append(r.Discards[e.Seat], d)
+		r.lastDiscard = e.Seat
+
+	case EventCall:
+		if err := r.call(e.Seat, *e.Meld); err != nil {
+			return err
+		}
+		r.lastDiscard = -1
+
+	case EventRiichi:
+		if r.Riichi[e.Seat] || r.pendingRiichi[e.Seat] {
+			return fmt.Errorf("seat %d already declared riichi", e.Seat)
+		}
+		r.pendingRiichi[e.Seat] = true
+
+	case EventRiichiAccepted:
+		if !r.pendingRiichi[e.Seat] || r.Riichi[e.Seat] {
+			return fmt.Errorf("seat %d has no pending riichi", e.Seat)
+		}
+		r.Riichi[e.Seat] = true
+		r.RiichiSticks++
+		g.Scores[e.Seat] -= 1000
+
+	case EventDora:
+		r.DoraIndicators = append(r.DoraIndicators, e.Tile)
+
+	case EventWin:
+		// Deltas already include honba and the riichi sticks on the table.
+		g.addScores(e.Win.Deltas)
+		r.RiichiSticks = 0
+		r.Wins = append(r.Wins, e.Win)
+		r.Ended = true
+
+	case EventRyuukyoku:
+		g.addScores(e.Ryuukyoku.Deltas)
+		r.Draw = e.Ryuukyoku
+		r.Ended = true
+
+	default:
+		return fmt.Errorf("unknown event type %d", e.Type)
+	}
+	if e.Type != EventDora {
+		r.Turn = e.Seat
+	}
+	return nil
+}
+
+func (g *Game) startRound(rs *RoundStart) error {
+	if rs.Dealer < 0 || rs.Dealer > 3 {
+		return fmt.Errorf("invalid dealer %d", rs.Dealer)
+	}
+	r := &Round{
+		Wind:           rs.Wind,
+		Number:         rs.Number,
+		Honba:          rs.Honba,
+		RiichiSticks:   rs.RiichiSticks,
+		Dealer:         rs.Dealer,
+		DoraIndicators: []Tile{rs.DoraIndicator},
+		TilesLeft:      liveWallSize,
+		Turn:           rs.Dealer,
+		lastDiscard:    -1,
+	}
+	for seat, hand := range rs.Hands {
+		if len(hand) != 13 {
+			return fmt.Errorf("seat %d dealt %d tiles", seat, len(hand))
+		}
+		r.Hands[seat] = slices.Clone(hand)
+	}
+	g.Round = r
+	g.Scores = rs.Scores
+	g.Rounds++
+	return nil
+}
+
+func (g *Game) addScores(deltas [4]int) {
+	for i, d := range deltas {
+		g.Scores[i] += d
+	}
+}
+
+// moreRon reports whether w is another ron on the tile that ended the
+// round.
+func (r *Round) moreRon(w *WinResult) bool {
+	if len(r.Wins) == 0 || w.IsTsumo() {
+		return false
+	}
+	last := r.Wins[len(r.Wins)-1]
+	return !last.IsTsumo() && last.From == w.From && !slices.ContainsFunc(r.Wins, func(p *WinResult) bool { return p.Seat == w.Seat })
+}
+
+// sidewaysPlaced reports whether the riichi tile of seat is already in the
+// pond. A called riichi tile has left it.
+func (r *Round) sidewaysPlaced(seat int) bool {
+	return slices.ContainsFunc(r.Discards[seat], func(d Discard) bool { return d.Riichi && !d.Called })
+}
+
+func (r *Round) call(seat int, m Meld) error {
+	var own []Tile
+	switch m.Kind {
+	case MeldChi, MeldPon, MeldKan:
+		if m.From == seat || m.From < 0 || m.From > 3 {
+			return fmt.Errorf("%s from invalid seat %d", m.Kind, m.From)
+		}
+		pond := r.Discards[m.From]
+		if m.From != r.lastDiscard || pond[len(pond)-1].Tile != m.Called {
+			return fmt.Errorf("%s of %s, which seat %d did not just discard", m.Kind, m.Called, m.From)
+		}
+		// If this is a riichi tile, the discarder's next discard is laid
+		// sideways in its place, as sidewaysPlaced no longer finds it.
+		pond[len(pond)-1].Called = true
+		own = slices.Clone(m.Tiles)
+		if i := slices.Index(own, m.Called); i >= 0 {
+			own = slices.Delete(own, i, i+1)
+		}
+		r.Melds[seat] = append(r.Melds[seat], m)
+
+	case MeldAnkan:
+		own = m.Tiles
+		r.Melds[seat] = append(r.Melds[seat], m)
+
+	case MeldShouminkan:
+		i := slices.IndexFunc(r.Melds[seat], func(p Meld) bool {
+			return p.Kind == MeldPon && len(p.Tiles) > 0 && p.Tiles[0].Plain() == m.Called.Plain()
+		})
+		if i < 0 {
+			return fmt.Errorf("shouminkan of %s without a pon", m.Called)
+		}
+		own = []Tile{m.Called}
+		r.Melds[seat][i] = m
+
+	default:
+		return fmt.Errorf("unknown meld kind %d", m.Kind)
+	}
+
+	hand := slices.Clone(r.Hands[seat])
+	for _, t := range own {
+		i := slices.Index(hand, t)
+		if i < 0 {
+			return fmt.Errorf("seat %d does not hold %s for %s", seat, t, m)
+		}
+		hand = slices.Delete(hand, i, i+1)
+	}
+	r.Hands[seat] = hand
+	return nil
+}
@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func loadEvents(t *testing.T) []Event {
+	t.Helper()
+	events, err := LoadEventLog("testdata/events.jsonl")
+	if err != nil {
+		t.Fatalf("LoadEventLog failed: %v", err)
+	}
+	return events
+}
+
+func TestReplay(t *testing.T) {
+	events := loadEvents(t)
+
+	g, err := Replay(events, 0)
+	if err != nil || g.Round != nil || g.Rounds != 0 {
+		t.Fatalf("empty replay = %+v, %v", g, err)
+	}
+
+	// After the ankan and its dora flip.
+	g, err = Replay(events, 5)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	r := g.Round
+	if len(r.Hands[0]) != 10 || len(r.Melds[0]) != 1 || r.Melds[0][0].Kind != MeldAnkan {
+		t.Errorf("seat 0 hand %v melds %v", r.Hands[0], r.Melds[0])
+	}
+	if len(r.DoraIndicators) != 2 || r.DoraIndicators[1].String() != "2s" {
+		t.Errorf("dora indicators = %v", r.DoraIndicators)
+	}
+	if r.TilesLeft != liveWallSize-1 {
+		t.Errorf("tiles left = %d", r.TilesLeft)
+	}
+
+	// After the pon: the called 5z is marked in seat 1's pond.
+	g, err = Replay(events, 10)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if d := g.Round.Discards[1]; len(d) != 1 || !d[0].Called {
+		t.Errorf("seat 1 pond = %+v", d)
+	}
+	if len(g.Round.Hands[2]) != 11 || g.Round.Turn != 2 {
+		t.Errorf("seat 2 hand %v, turn %d", g.Round.Hands[2], g.Round.Turn)
+	}
+
+	// Riichi: sideways tile, then the stick is paid.
+	g, err = Replay(events, 15)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if d := g.Round.Discards[3]; !d[0].Riichi || !d[0].Tsumogiri || d[0].Seq != 3 {
+		t.Errorf("riichi discard = %+v", d[0])
+	}
+	if !g.Round.Riichi[3] || g.Round.RiichiSticks != 1 || g.Scores[3] != 24000 {
+		t.Errorf("riichi state: %v sticks %d scores %v", g.Round.Riichi, g.Round.RiichiSticks, g.Scores)
+	}
+
+	// The win pays out and collects the stick.
+	g, err = Replay(events, 18)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if !g.Round.Ended || len(g.Round.Wins) != 1 || g.Round.RiichiSticks != 0 {
+		t.Errorf("round after win: %+v", g.Round)
+	}
+	if g.Scores != [4]int{21100, 25000, 25000, 28900} {
+		t.Errorf("scores = %v", g.Scores)
+	}
+
+	g, err = Replay(events, -1)
+	if err != nil || !g.Finished {
+		t.Fatalf("full replay = %+v, %v", g, err)
+	}
+}
+
+func TestReplay_DoubleRon(t *testing.T) {
+	events := loadEvents(t)[:17]
+	first := Event{Type: EventWin, Seat: 3, Win: &WinResult{Seat: 3, From: 0, Deltas: [4]int{-3900, 0, 0, 4900}}}
+	second := Event{Type: EventWin, Seat: 1, Win: &WinResult{Seat: 1, From: 0, Deltas: [4]int{-1000, 1000, 0, 0}}}
+	events = append(events, first, second)
+
+	g, err := Replay(events, -1)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(g.Round.Wins) != 2 || g.Scores[1] != 26000 {
+		t.Errorf("wins %d, scores %v", len(g.Round.Wins), g.Scores)
+	}
+
+	// The same winner twice is not a double ron.
+	if _, err := Replay(append(events, second), -1); err == nil {
+		t.Error("expected error for a repeated win")
+	}
+}
+
+// TestReplay_CalledRiichiTile checks that the discard after a called
+// riichi tile is laid sideways in its place.
+func TestReplay_CalledRiichiTile(t *testing.T) {
+	tile := func(s string) Tile {
+		tl, err := ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tl
+	}
+	chi := &Meld{Kind: MeldChi, Tiles: []Tile{tile("6m"), tile("7m"), tile("8m")}, Called: tile("7m"), From: 3}
+	events := append(loadEvents(t)[:12],
+		Event{Type: EventRiichi, Seat: 3},
+		Event{Type: EventDiscard, Seat: 3, Tile: tile("7m")},
+		Event{Type: EventRiichiAccepted, Seat: 3},
+		Event{Type: EventCall, Seat: 0, Meld: chi},
+		Event{Type: EventDiscard, Seat: 0, Tile: tile("1m")},
+		Event{Type: EventDrawTile, Seat: 1, Tile: tile("3s")},
+		Event{Type: EventDiscard, Seat: 1, Tile: tile("3s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 2, Tile: tile("4s")},
+		Event{Type: EventDiscard, Seat: 2, Tile: tile("4s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 3, Tile: tile("5s")},
+		Event{Type: EventDiscard, Seat: 3, Tile: tile("5s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 0, Tile: tile("6s")},
+		Event{Type: EventDiscard, Seat: 0, Tile: tile("6s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 1, Tile: tile("3s")},
+		Event{Type: EventDiscard, Seat: 1, Tile: tile("3s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 2, Tile: tile("4s")},
+		Event{Type: EventDiscard, Seat: 2, Tile: tile("4s"), Tsumogiri: true},
+		Event{Type: EventDrawTile, Seat: 3, Tile: tile("7s")},
+		Event{Type: EventDiscard, Seat: 3, Tile: tile("7s"), Tsumogiri: true},
+	)
+	g, err := Replay(events, -1)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	var sideways []bool
+	for _, d := range g.Round.Discards[3] {
+		sideways = append(sideways, d.Riichi)
+	}
+	if want := []bool{true, true, false}; !slices.Equal(sideways, want) {
+		t.Errorf("seat 3 sideways tiles = %v, want %v", sideways, want)
+	}
+}
+
+func TestReplay_Errors(t *testing.T) {
+	events := loadEvents(t)
+	tile := func(s string) Tile {
+		tl, err := ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tl
+	}
+
+	tests := []struct {
+		name  string
+		at    int
+		event Event
+		want  string
+	}{
+		{"discard not held", 3, Event{Type: EventDiscard, Seat: 0, Tile: tile("7z")}, "does not hold"},
+		{"draw twice", 3, Event{Type: EventDrawTile, Seat: 0, Tile: tile("1m")}, "draws with 14 tiles"},
+		{"discard twice", 7, Event{Type: EventDiscard, Seat: 0, Tile: tile("1m")}, "discards with 10 tiles"},
+		{"pon of old discard", 9, Event{Type: EventCall, Seat: 3, Meld: &Meld{Kind: MeldPon, Tiles: []Tile{tile("9p"), tile("9p"), tile("9p")}, Called: tile("9p"), From: 0}}, "did not just discard"},
+		{"kakan without pon", 3, Event{Type: EventCall, Seat: 0, Meld: &Meld{Kind: MeldShouminkan, Called: tile("1m")}}, "without a pon"},
+		{"accept without riichi", 9, Event{Type: EventRiichiAccepted, Seat: 1}, "no pending riichi"},
+		{"before round", 1, Event{Type: EventDiscard, Seat: 0, Tile: tile("1m")}, "no round"},
+		{"after end", 19, Event{Type: EventRiichi, Seat: 0}, "game already ended"},
+		{"missing payload", 2, Event{Type: EventWin, Seat: 0}, "without win"},
+		{"bad seat", 2, Event{Type: EventRiichi, Seat: 4}, "invalid seat"},
+	}
+	for _, tt := range tests {
+		log := append(append([]Event{}, events[:tt.at]...), tt.event)
+		_, err := Replay(log, -1)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
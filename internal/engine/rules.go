@@ -1,20 +1,215 @@
 package engine
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MultipleRon decides what happens when several players ron the same tile.
+type MultipleRon uint8
+
+const (
+	MultipleRonHeadBump MultipleRon = iota // 頭ハネ: only the first player after the discarder wins
+	MultipleRonDouble                      // double ron allowed, triple ron is an abortive draw
+	MultipleRonTriple                      // double and triple ron allowed
+)
+
+func (m MultipleRon) String() string {
+	switch m {
+	case MultipleRonHeadBump:
+		return "headbump"
+	case MultipleRonDouble:
+		return "double"
+	case MultipleRonTriple:
+		return "triple"
+	default:
+		return "?"
+	}
+}
+
+// GameLength is how many prevalent winds a game plays through.
+type GameLength uint8
+
+const (
+	GameEast      GameLength = iota // 東風戦
+	GameEastSouth                   // 半荘戦
+)
+
+func (g GameLength) String() string {
+	switch g {
+	case GameEast:
+		return "east"
+	case GameEastSouth:
+		return "eastsouth"
+	default:
+		return "?"
+	}
+}
+
 // Rules contains configurable game rules.
 type Rules struct {
 	// Number of red 5s in each suit.
-	RedFivesMan  int
-	RedFivesPin  int
-	RedFivesSou  int
-	StartingDora int
+	RedFivesMan  int `json:"red_fives_man" yaml:"red_fives_man" toml:"red_fives_man"`
+	RedFivesPin  int `json:"red_fives_pin" yaml:"red_fives_pin" toml:"red_fives_pin"`
+	RedFivesSou  int `json:"red_fives_sou" yaml:"red_fives_sou" toml:"red_fives_sou"`
+	StartingDora int `json:"starting_dora" yaml:"starting_dora" toml:"starting_dora"`
+
+	// Dora and bonus yaku.
+	KanDora bool `json:"kan_dora" yaml:"kan_dora" toml:"kan_dora"`
+	UraDora bool `json:"ura_dora" yaml:"ura_dora" toml:"ura_dora"`
+	Ippatsu bool `json:"ippatsu" yaml:"ippatsu" toml:"ippatsu"`
+
+	// Hand and scoring rules.
+	OpenTanyao   bool        `json:"open_tanyao" yaml:"open_tanyao" toml:"open_tanyao"`          // kuitan
+	Atozuke      bool        `json:"atozuke" yaml:"atozuke" toml:"atozuke"`                      // winning on a tile that completes a yaku only after the call
+	DoubleWindFu int         `json:"double_wind_fu" yaml:"double_wind_fu" toml:"double_wind_fu"` // fu for a pair of the seat wind that is also the round wind: 2 or 4
+	Kiriage      bool        `json:"kiriage" yaml:"kiriage" toml:"kiriage"`                      // round 4 han 30 fu and 3 han 60 fu up to mangan
+	KazoeYakuman bool        `json:"kazoe_yakuman" yaml:"kazoe_yakuman" toml:"kazoe_yakuman"`    // 13+ han counts as yakuman instead of sanbaiman
+	MultipleRon  MultipleRon `json:"multiple_ron" yaml:"multiple_ron" toml:"multiple_ron"`
+
+	// Game flow.
+	Length        GameLength `json:"length" yaml:"length" toml:"length"`
+	AbortiveDraws bool       `json:"abortive_draws" yaml:"abortive_draws" toml:"abortive_draws"` // kyuushu kyuuhai, suufon renda, suucha riichi, suukaikan
+	Bust          bool       `json:"bust" yaml:"bust" toml:"bust"`                               // game ends when a player drops below zero
+	AgariYame     bool       `json:"agari_yame" yaml:"agari_yame" toml:"agari_yame"`             // dealer may end the game in the last round when in first place
+
+	// Final scoring. Uma is in thousands of points by placement; the oka
+	// goes to first place and comes from the gap between ReturnPoints and
+	// StartingPoints.
+	StartingPoints int    `json:"starting_points" yaml:"starting_points" toml:"starting_points"`
+	ReturnPoints   int    `json:"return_points" yaml:"return_points" toml:"return_points"`
+	Uma            [4]int `json:"uma" yaml:"uma" toml:"uma"`
+}
+
+// Oka returns the first-place bonus in points.
+func (r Rules) Oka() int {
+	return (r.ReturnPoints - r.StartingPoints) * 4
 }
 
 // DefaultRules returns standard Riichi rules (3 akadora) 1 starting Dora.
+// It is the same ruleset as TenhouRules.
 func DefaultRules() Rules {
 	return Rules{
 		RedFivesMan:  1,
 		RedFivesPin:  1,
 		RedFivesSou:  1,
 		StartingDora: 1,
+
+		KanDora: true,
+		UraDora: true,
+		Ippatsu: true,
+
+		OpenTanyao:   true,
+		Atozuke:      true,
+		DoubleWindFu: 4,
+		Kiriage:      false,
+		KazoeYakuman: true,
+		MultipleRon:  MultipleRonDouble,
+
+		Length:        GameEastSouth,
+		AbortiveDraws: true,
+		Bust:          true,
+		AgariYame:     true,
+
+		StartingPoints: 25000,
+		ReturnPoints:   30000,
+		Uma:            [4]int{20, 10, -10, -20},
+	}
+}
+
+// TenhouRules returns the Tenhou ranked hanchan rules (東南喰赤).
+func TenhouRules() Rules {
+	return DefaultRules()
+}
+
+// MahjongSoulRules returns the Mahjong Soul ranked rules as played in the
+// Jade and Throne rooms.
+func MahjongSoulRules() Rules {
+	r := DefaultRules()
+	r.ReturnPoints = 25000
+	r.Uma = [4]int{15, 5, -5, -15}
+	return r
+}
+
+// WRCRules returns the World Riichi Championship rules.
+func WRCRules() Rules {
+	r := DefaultRules()
+	r.RedFivesMan, r.RedFivesPin, r.RedFivesSou = 0, 0, 0
+	r.DoubleWindFu = 2
+	r.Kiriage = true
+	r.KazoeYakuman = false
+	r.MultipleRon = MultipleRonHeadBump
+	r.AbortiveDraws = false
+	r.Bust = false
+	r.AgariYame = false
+	r.StartingPoints = 30000
+	r.ReturnPoints = 30000
+	r.Uma = [4]int{15, 5, -5, -15}
+	return r
+}
+
+// EMARules returns the European Mahjong Association riichi rules.
+func EMARules() Rules {
+	r := WRCRules()
+	r.DoubleWindFu = 4
+	r.Kiriage = false
+	r.MultipleRon = MultipleRonTriple
+	return r
+}
+
+// MLeagueRules returns the M-League professional league rules.
+func MLeagueRules() Rules {
+	r := DefaultRules()
+	r.DoubleWindFu = 2
+	r.KazoeYakuman = false
+	r.MultipleRon = MultipleRonHeadBump
+	r.Bust = false
+	r.AgariYame = false
+	r.Uma = [4]int{30, 10, -10, -30}
+	return r
+}
+
+// JPMLRules returns the Japan Professional Mahjong League "A rules": no
+// ippatsu, ura dora, kan dora or red fives.
+func JPMLRules() Rules {
+	r := WRCRules()
+	r.KanDora = false
+	r.UraDora = false
+	r.Ippatsu = false
+	r.Kiriage = false
+	return r
+}
+
+// rulePresets maps preset names to their constructors.
+var rulePresets = map[string]func() Rules{
+	"default":     DefaultRules,
+	"tenhou":      TenhouRules,
+	"mahjongsoul": MahjongSoulRules,
+	"wrc":         WRCRules,
+	"ema":         EMARules,
+	"mleague":     MLeagueRules,
+	"jpml":        JPMLRules,
+}
+
+// PresetNames returns the names accepted by RulesPreset, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(rulePresets))
+	for name := range rulePresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RulesPreset returns the named preset. Names are case-insensitive and
+// ignore "-" and "_", so "Mahjong-Soul" and "m_league" work.
+func RulesPreset(name string) (Rules, error) {
+	key := strings.ToLower(name)
+	key = strings.NewReplacer("-", "", "_", "", " ", "").Replace(key)
+	preset, ok := rulePresets[key]
+	if !ok {
+		return Rules{}, fmt.Errorf("unknown rules preset %q (known: %s)", name, strings.Join(PresetNames(), ", "))
 	}
+	return preset(), nil
 }
@@ -0,0 +1,338 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// RulesFormat is a config file format for Rules.
+type RulesFormat uint8
+
+const (
+	FormatJSON RulesFormat = iota
+	FormatYAML
+	FormatTOML
+)
+
+func (f RulesFormat) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	case FormatTOML:
+		return "toml"
+	default:
+		return "?"
+	}
+}
+
+// FormatFromPath picks the config format from a file extension.
+func FormatFromPath(path string) (RulesFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return 0, fmt.Errorf("unknown rules file extension %q (want .json, .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+}
+
+// FieldError is a validation problem with a single rules field. Path uses
+// the config file key names, e.g. "uma[2]".
+type FieldError struct {
+	Path string
+	Msg  string
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+func fieldErr(path, format string, args ...any) error {
+	return &FieldError{Path: path, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks every field and the combinations between them. All
+// problems are reported at once, each as a *FieldError.
+func (r Rules) Validate() error {
+	errs := r.validateRedFives()
+
+	if r.StartingDora < 0 || r.StartingDora > 5 {
+		errs = append(errs, fieldErr("starting_dora", "must be between 0 and 5, got %d", r.StartingDora))
+	}
+	if r.StartingDora == 0 && r.KanDora {
+		errs = append(errs, fieldErr("kan_dora", "requires starting_dora > 0"))
+	}
+	if r.StartingDora == 0 && r.UraDora {
+		errs = append(errs, fieldErr("ura_dora", "requires starting_dora > 0"))
+	}
+	if r.DoubleWindFu != 2 && r.DoubleWindFu != 4 {
+		errs = append(errs, fieldErr("double_wind_fu", "must be 2 or 4, got %d", r.DoubleWindFu))
+	}
+	if r.MultipleRon > MultipleRonTriple {
+		errs = append(errs, fieldErr("multiple_ron", "unknown value %d", r.MultipleRon))
+	}
+	if r.Length > GameEastSouth {
+		errs = append(errs, fieldErr("length", "unknown value %d", r.Length))
+	}
+
+	if r.StartingPoints <= 0 || r.StartingPoints%100 != 0 {
+		errs = append(errs, fieldErr("starting_points", "must be a positive multiple of 100, got %d", r.StartingPoints))
+	}
+	if r.ReturnPoints%100 != 0 {
+		errs = append(errs, fieldErr("return_points", "must be a multiple of 100, got %d", r.ReturnPoints))
+	}
+	if r.ReturnPoints < r.StartingPoints {
+		errs = append(errs, fieldErr("return_points", "must be at least starting_points (%d), got %d", r.StartingPoints, r.ReturnPoints))
+	}
+
+	sum := 0
+	for i, u := range r.Uma {
+		sum += u
+		if i > 0 && u > r.Uma[i-1] {
+			errs = append(errs, fieldErr(fmt.Sprintf("uma[%d]", i), "must not exceed uma[%d] (%d), got %d", i-1, r.Uma[i-1], u))
+		}
+	}
+	if sum != 0 {
+		errs = append(errs, fieldErr("uma", "must sum to 0, got %d", sum))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRedFives checks the fields BuildWall depends on.
+func (r Rules) validateRedFives() []error {
+	var errs []error
+	for _, f := range []struct {
+		path  string
+		count int
+	}{
+		{"red_fives_man", r.RedFivesMan},
+		{"red_fives_pin", r.RedFivesPin},
+		{"red_fives_sou", r.RedFivesSou},
+	} {
+		if f.count < 0 || f.count > copiesPerTileKind {
+			errs = append(errs, fieldErr(f.path, "must be between 0 and %d, got %d", copiesPerTileKind, f.count))
+		}
+	}
+	return errs
+}
+
+// rulesFile is the on-disk layout: an optional preset to start from, with
+// every Rules field available as an override.
+type rulesFile struct {
+	Preset string `json:"preset,omitempty" yaml:"preset,omitempty" toml:"preset,omitempty"`
+	Rules  `yaml:",inline"`
+}
+
+// LoadRules reads and validates a rules file. The format is picked from
+// the file extension.
+func LoadRules(path string) (Rules, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+	rules, err := ParseRules(data, format)
+	if err != nil {
+		return Rules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules decodes and validates a rules config. Fields that are not set
+// keep the value of the "preset" key, or of DefaultRules when there is none.
+// Unknown keys are rejected.
+func ParseRules(data []byte, format RulesFormat) (Rules, error) {
+	// First pass: only look for the preset so overrides apply on top of it.
+	var head struct {
+		Preset string `json:"preset" yaml:"preset" toml:"preset"`
+	}
+	if err := decodeRules(data, format, &head, false); err != nil {
+		return Rules{}, err
+	}
+
+	base := DefaultRules()
+	if head.Preset != "" {
+		var err error
+		if base, err = RulesPreset(head.Preset); err != nil {
+			return Rules{}, fieldErr("preset", "%v", err)
+		}
+	}
+
+	file := rulesFile{Rules: base}
+	if err := decodeRules(data, format, &file, true); err != nil {
+		return Rules{}, err
+	}
+	if err := file.Rules.Validate(); err != nil {
+		return Rules{}, err
+	}
+	return file.Rules, nil
+}
+
+func decodeRules(data []byte, format RulesFormat, v any, strict bool) error {
+	if strict && format != FormatTOML {
+		if err := unknownKeys(data, format); err != nil {
+			return err
+		}
+	}
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(v); err != nil {
+			return fmt.Errorf("invalid json: %w", err)
+		}
+		if dec.More() {
+			return fmt.Errorf("invalid json: trailing data after rules object")
+		}
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(strict)
+		if err := dec.Decode(v); err != nil && err != io.EOF {
+			return fmt.Errorf("invalid yaml: %w", err)
+		}
+	case FormatTOML:
+		md, err := toml.Decode(string(data), v)
+		if err != nil {
+			return fmt.Errorf("invalid toml: %w", err)
+		}
+		if undecoded := md.Undecoded(); strict && len(undecoded) > 0 {
+			errs := make([]error, 0, len(undecoded))
+			for _, key := range undecoded {
+				errs = append(errs, fieldErr(key.String(), "unknown field"))
+			}
+			return errors.Join(errs...)
+		}
+	default:
+		return fmt.Errorf("unknown rules format %d", format)
+	}
+	return nil
+}
+
+// rulesKeys is the set of keys a rules file may contain.
+var rulesKeys = func() map[string]bool {
+	keys := map[string]bool{"preset": true}
+	t := reflect.TypeFor[Rules]()
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// unknownKeys reports every key of a JSON or YAML rules file that is not a
+// rules field, each as a *FieldError. The decoders only report the first
+// one, and not as a field. Documents that are not a mapping are left to
+// the decoder to reject.
+func unknownKeys(data []byte, format RulesFormat) error {
+	var doc map[string]any
+	var err error
+	if format == FormatJSON {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil
+	}
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(doc)) {
+		if !rulesKeys[key] {
+			errs = append(errs, fieldErr(key, "unknown field"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteRules writes the complete ruleset in the given format, so the file
+// documents every effective value rather than just the overrides.
+func WriteRules(w io.Writer, r Rules, format RulesFormat) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatTOML:
+		return toml.NewEncoder(w).Encode(r)
+	default:
+		return fmt.Errorf("unknown rules format %d", format)
+	}
+}
+
+// SaveRules writes the ruleset to a file, picking the format from the
+// file extension.
+func SaveRules(path string, r Rules) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := WriteRules(&buf, r, format); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func (m MultipleRon) MarshalText() ([]byte, error) {
+	if m > MultipleRonTriple {
+		return nil, fmt.Errorf("unknown multiple ron value %d", m)
+	}
+	return []byte(m.String()), nil
+}
+
+func (m *MultipleRon) UnmarshalText(text []byte) error {
+	for v := MultipleRonHeadBump; v <= MultipleRonTriple; v++ {
+		if strings.EqualFold(string(text), v.String()) {
+			*m = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown multiple ron value %q (want headbump, double or triple)", text)
+}
+
+func (g GameLength) MarshalText() ([]byte, error) {
+	if g > GameEastSouth {
+		return nil, fmt.Errorf("unknown game length %d", g)
+	}
+	return []byte(g.String()), nil
+}
+
+func (g *GameLength) UnmarshalText(text []byte) error {
+	for v := GameEast; v <= GameEastSouth; v++ {
+		if strings.EqualFold(string(text), v.String()) {
+			*g = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown game length %q (want east or eastsouth)", text)
+}
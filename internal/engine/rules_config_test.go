@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	want := WRCRules()
+	want.RedFivesMan, want.RedFivesPin, want.RedFivesSou = 1, 1, 1
+	want.Length = GameEast
+
+	for _, name := range []string{"rules.json", "rules.yaml", "rules.toml"} {
+		t.Run(name, func(t *testing.T) {
+			got, err := LoadRules(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf("LoadRules failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("LoadRules() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRules_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    RulesFormat
+		input     string
+		wantPaths []string
+	}{
+		{"json unknown key", FormatJSON, `{"red_fives": 3}`, []string{"red_fives"}},
+		{"yaml unknown key", FormatYAML, "red_fives: 3\n", []string{"red_fives"}},
+		{"toml unknown key", FormatTOML, "red_fives = 3\n", []string{"red_fives"}},
+		{"json unknown keys", FormatJSON, `{"red_fives": 3, "kuitan": true, "ippatsu": false}`, []string{"kuitan", "red_fives"}},
+		{"yaml unknown keys", FormatYAML, "red_fives: 3\nkuitan: true\n", []string{"kuitan", "red_fives"}},
+		{"unknown preset", FormatJSON, `{"preset": "hong kong"}`, []string{"preset"}},
+		{"red fives out of range", FormatYAML, "red_fives_pin: 5\n", []string{"red_fives_pin"}},
+		{"bad enum", FormatTOML, "multiple_ron = \"quadruple\"\n", nil},
+		{"double wind fu", FormatJSON, `{"double_wind_fu": 3}`, []string{"double_wind_fu"}},
+		{
+			"inconsistent dora",
+			FormatYAML,
+			"starting_dora: 0\nkan_dora: true\nura_dora: true\n",
+			[]string{"kan_dora", "ura_dora"},
+		},
+		{
+			"return below start",
+			FormatTOML,
+			"starting_points = 30000\nreturn_points = 25000\n",
+			[]string{"return_points"},
+		},
+		{
+			"uma does not sum to zero",
+			FormatJSON,
+			`{"uma": [30, 10, -10, -20]}`,
+			[]string{"uma"},
+		},
+		{
+			"uma out of order",
+			FormatYAML,
+			"uma: [10, 20, -10, -20]\n",
+			[]string{"uma[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.input), tt.format)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			for _, path := range tt.wantPaths {
+				if !hasFieldError(err, path) {
+					t.Errorf("expected a field error for %q, got %v", path, err)
+				}
+			}
+		})
+	}
+}
+
+// hasFieldError reports whether err (possibly joined) contains a
+// *FieldError for path.
+func hasFieldError(err error, path string) bool {
+	var fe *FieldError
+	if errors.As(err, &fe) && fe.Path == path {
+		return true
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if hasFieldError(e, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestValidate_Presets(t *testing.T) {
+	for _, name := range PresetNames() {
+		rules, _ := RulesPreset(name)
+		if err := rules.Validate(); err != nil {
+			t.Errorf("preset %q does not validate: %v", name, err)
+		}
+	}
+}
+
+func TestWriteRules_RoundTrip(t *testing.T) {
+	rules := MLeagueRules()
+	rules.MultipleRon = MultipleRonTriple
+	rules.Length = GameEast
+
+	for _, format := range []RulesFormat{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteRules(&buf, rules, format); err != nil {
+				t.Fatalf("WriteRules failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), "triple") {
+				t.Errorf("expected enum written as text, got:\n%s", buf.String())
+			}
+			got, err := ParseRules(buf.Bytes(), format)
+			if err != nil {
+				t.Fatalf("ParseRules failed: %v\n%s", err, buf.String())
+			}
+			if got != rules {
+				t.Errorf("round trip = %+v, want %+v", got, rules)
+			}
+		})
+	}
+}
+
+func TestSaveRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	if err := SaveRules(path, JPMLRules()); err != nil {
+		t.Fatalf("SaveRules failed: %v", err)
+	}
+	got, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if got != JPMLRules() {
+		t.Errorf("LoadRules() = %+v, want %+v", got, JPMLRules())
+	}
+
+	if err := SaveRules(filepath.Join(t.TempDir(), "rules.ini"), JPMLRules()); err == nil {
+		t.Errorf("expected error for unknown extension, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestRulesPreset(t *testing.T) {
+	for _, name := range PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			rules, err := RulesPreset(name)
+			if err != nil {
+				t.Fatalf("RulesPreset(%q) failed: %v", name, err)
+			}
+			if _, err := BuildWall(rules); err != nil {
+				t.Errorf("BuildWall(%q) failed: %v", name, err)
+			}
+			if rules.DoubleWindFu != 2 && rules.DoubleWindFu != 4 {
+				t.Errorf("DoubleWindFu = %d, want 2 or 4", rules.DoubleWindFu)
+			}
+			uma := 0
+			for _, u := range rules.Uma {
+				uma += u
+			}
+			if uma != 0 {
+				t.Errorf("uma %v sums to %d, want 0", rules.Uma, uma)
+			}
+		})
+	}
+
+	t.Run("aliases", func(t *testing.T) {
+		for _, name := range []string{"Tenhou", "Mahjong-Soul", "m_league", "WRC"} {
+			if _, err := RulesPreset(name); err != nil {
+				t.Errorf("RulesPreset(%q) failed: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := RulesPreset("hong kong"); err == nil {
+			t.Errorf("expected error for unknown preset, got nil")
+		}
+	})
+}
+
+func TestRulesPreset_Differences(t *testing.T) {
+	tenhou := TenhouRules()
+	if tenhou != DefaultRules() {
+		t.Errorf("TenhouRules should equal DefaultRules")
+	}
+	if tenhou.Oka() != 20000 {
+		t.Errorf("Tenhou oka = %d, want 20000", tenhou.Oka())
+	}
+
+	wrc := WRCRules()
+	if wrc.RedFivesMan+wrc.RedFivesPin+wrc.RedFivesSou != 0 {
+		t.Errorf("WRC should not use red fives")
+	}
+	if wrc.MultipleRon != MultipleRonHeadBump || !wrc.Kiriage || wrc.Oka() != 0 {
+		t.Errorf("unexpected WRC rules: %+v", wrc)
+	}
+
+	if ema := EMARules(); ema.MultipleRon != MultipleRonTriple {
+		t.Errorf("EMA should allow triple ron, got %v", ema.MultipleRon)
+	}
+
+	jpml := JPMLRules()
+	if jpml.UraDora || jpml.KanDora || jpml.Ippatsu {
+		t.Errorf("JPML A rules have no ura, kan dora or ippatsu: %+v", jpml)
+	}
+
+	if ml := MLeagueRules(); ml.Uma != [4]int{30, 10, -10, -30} {
+		t.Errorf("M-League uma = %v", ml.Uma)
+	}
+}
@@ -0,0 +1,62 @@
+package engine
+
+import "fmt"
+
+// NumTileIDs is the number of physical tiles in a riichi set.
+const NumTileIDs = NumKinds * copiesPerTileKind
+
+// TileID identifies one physical tile, 0–135, numbered as Tenhou does:
+// id/4 is the kind index (see Tile.Index) and id%4 tells the four copies
+// apart. Where a set has red fives, they are the lowest copies of the five,
+// so with one red five per suit ids 16, 52 and 88 are red.
+type TileID uint8
+
+// RedFives is the number of red fives in each numbered suit (m, p, s).
+type RedFives [3]int
+
+// RedFives returns the rules' red five counts.
+func (r Rules) RedFives() RedFives {
+	return RedFives{r.RedFivesMan, r.RedFivesPin, r.RedFivesSou}
+}
+
+// Index returns the id's kind index.
+func (id TileID) Index() int {
+	return int(id) / copiesPerTileKind
+}
+
+// Copy returns which of the four copies of its kind the id is.
+func (id TileID) Copy() int {
+	return int(id) % copiesPerTileKind
+}
+
+// Tile returns the tile kind of id in a set with the given red fives.
+// It panics if id is out of range.
+func (id TileID) Tile(red RedFives) Tile {
+	t := TileAt(id.Index())
+	if t.IsNumbered() && t.Rank() == 5 && id.Copy() < red[t.Suit()] {
+		t, _ = NewRedFive(t.Suit())
+	}
+	return t
+}
+
+func (id TileID) String() string {
+	return fmt.Sprintf("#%d", uint8(id))
+}
+
+// TileIDs returns the ids of every copy of t in a set with the given red
+// fives. A red five matches only the red copies and a plain five only the
+// others.
+func TileIDs(t Tile, red RedFives) []TileID {
+	i := t.Index()
+	if i < 0 {
+		return nil
+	}
+	var ids []TileID
+	for c := range copiesPerTileKind {
+		id := TileID(i*copiesPerTileKind + c)
+		if id.Tile(red) == t.SetDora(false).SetUra(false) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
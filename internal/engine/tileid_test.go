@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTileIDTile(t *testing.T) {
+	aka := RedFives{1, 1, 1}
+	tests := []struct {
+		id   TileID
+		red  RedFives
+		want string
+	}{
+		{0, aka, "1m"},
+		{3, aka, "1m"},
+		{16, aka, "0m"},
+		{16, RedFives{}, "5m"},
+		{17, aka, "5m"},
+		{17, RedFives{2, 0, 0}, "0m"},
+		{52, aka, "0p"},
+		{88, aka, "0s"},
+		{107, aka, "9s"},
+		{108, aka, "1z"},
+		{135, aka, "7z"},
+	}
+	for _, tt := range tests {
+		if got := tt.id.Tile(tt.red).String(); got != tt.want {
+			t.Errorf("TileID(%d).Tile(%v) = %s, want %s", tt.id, tt.red, got, tt.want)
+		}
+	}
+}
+
+func TestTileIDs(t *testing.T) {
+	aka := RedFives{1, 1, 1}
+	tests := []struct {
+		tile string
+		want []TileID
+	}{
+		{"1m", []TileID{0, 1, 2, 3}},
+		{"0p", []TileID{52}},
+		{"5p", []TileID{53, 54, 55}},
+		{"7z", []TileID{132, 133, 134, 135}},
+	}
+	for _, tt := range tests {
+		tile, err := ParseTile(tt.tile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := TileIDs(tile, aka); !slices.Equal(got, tt.want) {
+			t.Errorf("TileIDs(%s) = %v, want %v", tt.tile, got, tt.want)
+		}
+		if got := TileIDs(tile.SetDora(true), aka); !slices.Equal(got, tt.want) {
+			t.Errorf("TileIDs(%s with dora) = %v, want %v", tt.tile, got, tt.want)
+		}
+	}
+	if got := TileIDs(NewFlower(), aka); got != nil {
+		t.Errorf("TileIDs(flower) = %v", got)
+	}
+
+	// Every id maps back to itself through its kind.
+	for id := range TileID(NumTileIDs) {
+		tile := id.Tile(aka)
+		if !slices.Contains(TileIDs(tile, aka), id) {
+			t.Errorf("TileIDs(%s) lacks %v", tile, id)
+		}
+	}
+}
+
+func TestBuildWallMatchesTileIDs(t *testing.T) {
+	rules := DefaultRules()
+	rules.RedFivesPin = 2
+	wall, err := BuildWall(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, tile := range wall {
+		if want := TileID(id).Tile(rules.RedFives()); tile != want {
+			t.Errorf("wall[%d] = %s, want %s", id, tile, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -47,6 +48,13 @@ const (
 	bitUra   uint8 = 1 << 7
 )
 
+// Extra honor ranks used by American (NMJL) mahjong. They live above the
+// seven riichi honors so they never collide with winds or dragons.
+const (
+	rankFlower = 8
+	rankJoker  = 9
+)
+
 // NewTile creates a tile from suit and rank.
 // For suits man/pin/sou: rank 1-9
 // For honors: rank mapping is like Mahjong Soul
@@ -70,6 +78,17 @@ func NewRedFive(s Suit) (Tile, error) {
 	return Tile((uint8(s) << 4) | 0), nil // rank 0 = red five
 }
 
+// NewFlower returns the flower tile used by American (NMJL) mahjong.
+// All flowers are interchangeable, so there is a single flower kind.
+func NewFlower() Tile {
+	return Tile((uint8(SuitHonor) << 4) | rankFlower)
+}
+
+// NewJoker returns the joker tile used by American (NMJL) mahjong.
+func NewJoker() Tile {
+	return Tile((uint8(SuitHonor) << 4) | rankJoker)
+}
+
 func (t Tile) Suit() Suit {
 	return Suit((uint8(t) & maskSuit) >> 4)
 }
@@ -104,8 +123,39 @@ func (t Tile) SetUra(isUra bool) Tile {
 	return Tile(uint8(t) & ^bitUra)
 }
 
+// Plain strips the dora flags and turns a red five into a plain five, so
+// tiles of the same kind compare equal.
+func (t Tile) Plain() Tile {
+	t = t.SetDora(false).SetUra(false)
+	if t.IsRed() {
+		t, _ = NewTile(t.Suit(), 5)
+	}
+	return t
+}
+
+// RemoveTile removes t from hand, preferring an exact match and falling
+// back to the same kind when red fives differ. The hand is returned
+// unchanged if it holds no such tile.
+func RemoveTile(hand []Tile, t Tile) []Tile {
+	if i := slices.Index(hand, t); i >= 0 {
+		return slices.Delete(hand, i, i+1)
+	}
+	if i := slices.IndexFunc(hand, func(h Tile) bool { return h.Plain() == t.Plain() }); i >= 0 {
+		return slices.Delete(hand, i, i+1)
+	}
+	return hand
+}
+
 func (t Tile) IsHonor() bool {
-	return t.Suit() == SuitHonor
+	return t.Suit() == SuitHonor && t.Rank() >= 1 && t.Rank() <= 7
+}
+
+func (t Tile) IsFlower() bool {
+	return t.Suit() == SuitHonor && t.Rank() == rankFlower
+}
+
+func (t Tile) IsJoker() bool {
+	return t.Suit() == SuitHonor && t.Rank() == rankJoker
 }
 
 func (t Tile) IsNumbered() bool {
@@ -146,6 +196,13 @@ func (t Tile) String() string {
 		return fmt.Sprintf("%d%s", r, s.String())
 	}
 
+	switch r {
+	case rankFlower:
+		return "F"
+	case rankJoker:
+		return "J"
+	}
+
 	return "?"
 }
 
@@ -153,6 +210,7 @@ func (t Tile) String() string {
 // Numbered: 1m, 0p, 9s
 // Honors:   1z–7z
 // Aliases:  E, S, W, N, G, R, Wh
+// American: F (flower), J (joker)
 func ParseTile(s string) (Tile, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -177,6 +235,10 @@ func ParseTile(s string) (Tile, error) {
 		return NewTile(SuitHonor, 7)
 	case "WH": // white dragon
 		return NewTile(SuitHonor, 5)
+	case "F":
+		return NewFlower(), nil
+	case "J":
+		return NewJoker(), nil
 	}
 
 	// Numbered & 1z–7z
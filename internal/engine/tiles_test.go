@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -193,3 +194,71 @@ func TestParseTile(t *testing.T) {
 		})
 	}
 }
+
+func TestTile_FlowerAndJoker(t *testing.T) {
+	flower := NewFlower()
+	joker := NewJoker()
+
+	if !flower.IsFlower() || flower.IsJoker() {
+		t.Errorf("NewFlower: IsFlower=%v IsJoker=%v", flower.IsFlower(), flower.IsJoker())
+	}
+	if !joker.IsJoker() || joker.IsFlower() {
+		t.Errorf("NewJoker: IsJoker=%v IsFlower=%v", joker.IsJoker(), joker.IsFlower())
+	}
+	for _, tile := range []Tile{flower, joker} {
+		if tile.IsHonor() || tile.IsWind() || tile.IsDragon() || tile.IsNumbered() {
+			t.Errorf("%v should not be an honor, wind, dragon or numbered tile", tile)
+		}
+	}
+	if flower.String() != "F" || joker.String() != "J" {
+		t.Errorf("String() = %q, %q, want F, J", flower.String(), joker.String())
+	}
+
+	for input, want := range map[string]Tile{"F": flower, "j": joker} {
+		got, err := ParseTile(input)
+		if err != nil {
+			t.Fatalf("ParseTile(%q) failed: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseTile(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestTile_Plain(t *testing.T) {
+	five := mustNewTile(t, SuitPinzu, 5)
+	red, _ := NewRedFive(SuitPinzu)
+	tests := []struct {
+		in, want Tile
+	}{
+		{five, five},
+		{red, five},
+		{red.SetDora(true).SetUra(true), five},
+		{NewFlower(), NewFlower()},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Plain(); got != tt.want {
+			t.Errorf("%s.Plain() = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveTile(t *testing.T) {
+	hand, _ := ParseHandCompact("055m1z")
+	red, five, east, north := hand[0], hand[1], hand[3], mustNewTile(t, SuitHonor, 4)
+	tests := []struct {
+		name string
+		t    Tile
+		want []Tile
+	}{
+		{"exact", five, []Tile{red, five, east}},
+		{"exact red", red, []Tile{five, five, east}},
+		{"same kind", red.SetDora(true), []Tile{five, five, east}},
+		{"missing", north, hand},
+	}
+	for _, tt := range tests {
+		if got := RemoveTile(slices.Clone(hand), tt.t); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package engine
+
+// Wind is a round or seat wind.
+type Wind uint8
+
+const (
+	WindEast Wind = iota
+	WindSouth
+	WindWest
+	WindNorth
+)
+
+func (w Wind) String() string {
+	switch w {
+	case WindEast:
+		return "E"
+	case WindSouth:
+		return "S"
+	case WindWest:
+		return "W"
+	case WindNorth:
+		return "N"
+	default:
+		return "?"
+	}
+}
+
+// Tile returns the honor tile for the wind (1z–4z).
+func (w Wind) Tile() Tile {
+	return Tile((uint8(SuitHonor) << 4) | (uint8(w)%4 + 1))
+}
+
+// SeatWind returns the seat wind of seat in a round where dealer is East.
+func SeatWind(seat, dealer int) Wind {
+	return Wind(((seat-dealer)%4 + 4) % 4)
+}
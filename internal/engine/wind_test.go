@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestWind_Tile(t *testing.T) {
+	want := []string{"1z", "2z", "3z", "4z"}
+	for w := WindEast; w <= WindNorth; w++ {
+		if got := w.Tile().String(); got != want[w] {
+			t.Errorf("%v.Tile() = %s, want %s", w, got, want[w])
+		}
+		if !w.Tile().IsWind() {
+			t.Errorf("%v.Tile() is not a wind", w)
+		}
+	}
+}
+
+func TestSeatWind(t *testing.T) {
+	tests := []struct {
+		seat, dealer int
+		want         Wind
+	}{
+		{0, 0, WindEast},
+		{1, 0, WindSouth},
+		{3, 0, WindNorth},
+		{0, 1, WindNorth},
+		{1, 3, WindWest},
+	}
+	for _, tt := range tests {
+		if got := SeatWind(tt.seat, tt.dealer); got != tt.want {
+			t.Errorf("SeatWind(%d, %d) = %v, want %v", tt.seat, tt.dealer, got, tt.want)
+		}
+	}
+}
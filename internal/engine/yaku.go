@@ -0,0 +1,150 @@
+package engine
+
+// Yaku identifies a scoring pattern. Dora, ura dora and red fives are
+// listed as well because log formats report them alongside the yaku.
+type Yaku uint8
+
+const (
+	YakuMenzenTsumo Yaku = iota
+	YakuRiichi
+	YakuIppatsu
+	YakuChankan
+	YakuRinshan
+	YakuHaitei
+	YakuHoutei
+	YakuPinfu
+	YakuTanyao
+	YakuIipeikou
+	YakuSeatWind
+	YakuRoundWind
+	YakuHaku
+	YakuHatsu
+	YakuChun
+	YakuDoubleRiichi
+	YakuChiitoitsu
+	YakuChanta
+	YakuIttsu
+	YakuSanshokuDoujun
+	YakuSanshokuDoukou
+	YakuSankantsu
+	YakuToitoi
+	YakuSanankou
+	YakuShousangen
+	YakuHonroutou
+	YakuRyanpeikou
+	YakuJunchan
+	YakuHonitsu
+	YakuChinitsu
+
+	// Yakuman.
+	YakuRenhou
+	YakuTenhou
+	YakuChiihou
+	YakuDaisangen
+	YakuSuuankou
+	YakuSuuankouTanki
+	YakuTsuuiisou
+	YakuRyuuiisou
+	YakuChinroutou
+	YakuChuuren
+	YakuJunseiChuuren
+	YakuKokushi
+	YakuKokushi13
+	YakuDaisuushii
+	YakuShousuushii
+	YakuSuukantsu
+
+	// Bonus han, not yaku on their own.
+	YakuDora
+	YakuUraDora
+	YakuAkaDora
+
+	yakuCount
+)
+
+var yakuNames = [yakuCount]struct {
+	romaji   string
+	japanese string
+}{
+	YakuMenzenTsumo:    {"menzen tsumo", "門前清自摸和"},
+	YakuRiichi:         {"riichi", "立直"},
+	YakuIppatsu:        {"ippatsu", "一発"},
+	YakuChankan:        {"chankan", "槍槓"},
+	YakuRinshan:        {"rinshan kaihou", "嶺上開花"},
+	YakuHaitei:         {"haitei raoyue", "海底摸月"},
+	YakuHoutei:         {"houtei raoyui", "河底撈魚"},
+	YakuPinfu:          {"pinfu", "平和"},
+	YakuTanyao:         {"tanyao", "断幺九"},
+	YakuIipeikou:       {"iipeikou", "一盃口"},
+	YakuSeatWind:       {"seat wind", "自風"},
+	YakuRoundWind:      {"round wind", "場風"},
+	YakuHaku:           {"haku", "役牌 白"},
+	YakuHatsu:          {"hatsu", "役牌 發"},
+	YakuChun:           {"chun", "役牌 中"},
+	YakuDoubleRiichi:   {"double riichi", "両立直"},
+	YakuChiitoitsu:     {"chiitoitsu", "七対子"},
+	YakuChanta:         {"chanta", "混全帯幺九"},
+	YakuIttsu:          {"ittsu", "一気通貫"},
+	YakuSanshokuDoujun: {"sanshoku doujun", "三色同順"},
+	YakuSanshokuDoukou: {"sanshoku doukou", "三色同刻"},
+	YakuSankantsu:      {"sankantsu", "三槓子"},
+	YakuToitoi:         {"toitoi", "対々和"},
+	YakuSanankou:       {"sanankou", "三暗刻"},
+	YakuShousangen:     {"shousangen", "小三元"},
+	YakuHonroutou:      {"honroutou", "混老頭"},
+	YakuRyanpeikou:     {"ryanpeikou", "二盃口"},
+	YakuJunchan:        {"junchan", "純全帯幺九"},
+	YakuHonitsu:        {"honitsu", "混一色"},
+	YakuChinitsu:       {"chinitsu", "清一色"},
+	YakuRenhou:         {"renhou", "人和"},
+	YakuTenhou:         {"tenhou", "天和"},
+	YakuChiihou:        {"chiihou", "地和"},
+	YakuDaisangen:      {"daisangen", "大三元"},
+	YakuSuuankou:       {"suuankou", "四暗刻"},
+	YakuSuuankouTanki:  {"suuankou tanki", "四暗刻単騎"},
+	YakuTsuuiisou:      {"tsuuiisou", "字一色"},
+	YakuRyuuiisou:      {"ryuuiisou", "緑一色"},
+	YakuChinroutou:     {"chinroutou", "清老頭"},
+	YakuChuuren:        {"chuuren poutou", "九蓮宝燈"},
+	YakuJunseiChuuren:  {"junsei chuuren poutou", "純正九蓮宝燈"},
+	YakuKokushi:        {"kokushi musou", "国士無双"},
+	YakuKokushi13:      {"kokushi musou 13-sided", "国士無双１３面"},
+	YakuDaisuushii:     {"daisuushii", "大四喜"},
+	YakuShousuushii:    {"shousuushii", "小四喜"},
+	YakuSuukantsu:      {"suukantsu", "四槓子"},
+	YakuDora:           {"dora", "ドラ"},
+	YakuUraDora:        {"ura dora", "裏ドラ"},
+	YakuAkaDora:        {"aka dora", "赤ドラ"},
+}
+
+func (y Yaku) String() string {
+	if y >= yakuCount {
+		return "?"
+	}
+	return yakuNames[y].romaji
+}
+
+// Japanese returns the name used by Japanese clients and log formats.
+func (y Yaku) Japanese() string {
+	if y >= yakuCount {
+		return "?"
+	}
+	return yakuNames[y].japanese
+}
+
+// IsYakuman reports whether the yaku is a limit hand.
+func (y Yaku) IsYakuman() bool {
+	return y >= YakuRenhou && y <= YakuSuukantsu
+}
+
+// IsDora reports whether the entry is bonus han rather than a yaku.
+func (y Yaku) IsDora() bool {
+	return y == YakuDora || y == YakuUraDora || y == YakuAkaDora
+}
+
+// YakuHan is a yaku with the han it scored. Yakuman report 13 han per
+// multiple.
+type YakuHan struct {
+	Yaku Yaku `json:"yaku"`
+	Han  int  `json:"han"`
+}
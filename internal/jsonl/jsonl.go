@@ -0,0 +1,181 @@
+// Package jsonl carries JSON values one per line between the engine and
+// programs that play through it: subprocesses on stdin and stdout, or
+// clients over TCP. The protocols on top live in table and mjai.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// Stream is one JSON lines connection. Lines are read one at a time with
+// Recv or ReadLine, or by a background reader once Lines is called; after
+// that, only through Lines.
+type Stream struct {
+	r      *bufio.Reader
+	w      io.Writer
+	closer func() error
+
+	start sync.Once
+	lines chan []byte
+	err   error // why lines was closed
+	done  chan struct{}
+	stop  sync.Once
+}
+
+// New wraps a reader and writer, e.g. a program's stdout and stdin.
+// closer, if not nil, is called by Close.
+func New(r io.Reader, w io.Writer, closer func() error) *Stream {
+	return &Stream{r: bufio.NewReader(r), w: w, closer: closer, done: make(chan struct{})}
+}
+
+// Send writes v as one line.
+func (s *Stream) Send(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadLine returns the next line that is not blank, without its line
+// break.
+func (s *Stream) ReadLine() ([]byte, error) {
+	for {
+		line, err := s.r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Recv reads the next line into v.
+func (s *Stream) Recv(v any) error {
+	line, err := s.ReadLine()
+	if err != nil {
+		return err
+	}
+	return Decode(line, v)
+}
+
+// Decode unmarshals one line, quoting it in the error.
+func Decode(line []byte, v any) error {
+	if err := json.Unmarshal(line, v); err != nil {
+		return fmt.Errorf("invalid line %q: %w", line, err)
+	}
+	return nil
+}
+
+// Lines starts a reader that passes each line on the returned channel,
+// so a reply can be waited for with a deadline. The channel is closed
+// when the stream ends, with Err saying why, or is closed.
+func (s *Stream) Lines() <-chan []byte {
+	s.start.Do(func() {
+		s.lines = make(chan []byte)
+		go s.read()
+	})
+	return s.lines
+}
+
+func (s *Stream) read() {
+	defer close(s.lines)
+	for {
+		line, err := s.ReadLine()
+		if err != nil {
+			s.err = err
+			return
+		}
+		select {
+		case s.lines <- line:
+		case <-s.done:
+			s.err = io.ErrClosedPipe
+			return
+		}
+	}
+}
+
+// Err returns the error that ended Lines, once its channel is closed.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close ends the session and releases the process or connection.
+func (s *Stream) Close() error {
+	s.stop.Do(func() { close(s.done) })
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer()
+}
+
+// Start launches a program and connects to its stdin and stdout. Stderr
+// is passed through so the program's logs stay visible. Close waits for
+// the program to exit after closing its stdin.
+func Start(name string, args ...string) (*Stream, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return New(stdout, stdin, func() error {
+		stdin.Close()
+		return cmd.Wait()
+	}), nil
+}
+
+// Server accepts JSON lines clients over TCP.
+type Server struct {
+	ln net.Listener
+}
+
+// Listen starts a server on addr, e.g. "127.0.0.1:11600".
+func Listen(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{ln: ln}, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Accept waits for a client to connect. Closing the stream hangs up.
+func (s *Server) Accept() (*Stream, error) {
+	nc, err := s.ln.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return New(nc, nc, nc.Close), nil
+}
+
+// Close stops accepting new clients.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package jsonl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type msg struct {
+	N int `json:"n"`
+}
+
+func TestStream(t *testing.T) {
+	var out strings.Builder
+	s := New(strings.NewReader("{\"n\":1}\n\n  \n{\"n\":2}\nnot json\n"), &out, nil)
+	if err := s.Send(msg{7}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "{\"n\":7}\n" {
+		t.Errorf("sent %q", out.String())
+	}
+	for _, want := range []int{1, 2} {
+		var m msg
+		if err := s.Recv(&m); err != nil || m.N != want {
+			t.Errorf("Recv = %v, %v; want %d", m, err, want)
+		}
+	}
+	var m msg
+	if err := s.Recv(&m); err == nil || !strings.Contains(err.Error(), "not json") {
+		t.Errorf("invalid line: %v", err)
+	}
+	if err := s.Recv(&m); err != io.EOF {
+		t.Errorf("after the last line: %v, want io.EOF", err)
+	}
+}
+
+func TestLines(t *testing.T) {
+	s := New(strings.NewReader("a\n\nb"), io.Discard, nil)
+	var got []string
+	for line := range s.Lines() {
+		got = append(got, string(line))
+	}
+	if strings.Join(got, ",") != "a,b" || s.Err() != io.EOF {
+		t.Errorf("lines %q, err %v", got, s.Err())
+	}
+
+	r, w := io.Pipe()
+	closed := false
+	s = New(r, io.Discard, func() error { closed = true; return w.Close() })
+	lines := s.Lines()
+	fmt.Fprintln(w, "x")
+	if err := s.Close(); err != nil || !closed {
+		t.Fatalf("Close = %v, closer called %v", err, closed)
+	}
+	for range lines {
+	}
+	if s.Err() == nil {
+		t.Error("no error after Close")
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		nc, err := net.Dial("tcp", srv.Addr().String())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer nc.Close()
+		fmt.Fprintln(nc, `{"n":3}`)
+		bufio.NewReader(nc).ReadBytes('\n')
+	}()
+	s, err := srv.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m msg
+	if err := s.Recv(&m); err != nil || m.N != 3 {
+		t.Errorf("Recv = %v, %v", m, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Error(err)
+	}
+	srv.Close()
+	if err := srv.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+	if _, err := srv.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Accept after Close = %v", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("no cat")
+	}
+	s, err := Start("cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(msg{5}); err != nil {
+		t.Fatal(err)
+	}
+	var m msg
+	if err := s.Recv(&m); err != nil || m.N != 5 {
+		t.Errorf("echo = %v, %v", m, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package majsoul
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// fanByID maps Mahjong Soul fan ids to engine yaku. Ids that are missing
+// (local yaku such as renchan or kita) are rejected.
+var fanByID = map[int]engine.Yaku{
+	1:  engine.YakuMenzenTsumo,
+	2:  engine.YakuRiichi,
+	3:  engine.YakuChankan,
+	4:  engine.YakuRinshan,
+	5:  engine.YakuHaitei,
+	6:  engine.YakuHoutei,
+	7:  engine.YakuHaku,
+	8:  engine.YakuHatsu,
+	9:  engine.YakuChun,
+	10: engine.YakuSeatWind,
+	11: engine.YakuRoundWind,
+	12: engine.YakuTanyao,
+	13: engine.YakuIipeikou,
+	14: engine.YakuPinfu,
+	15: engine.YakuChanta,
+	16: engine.YakuIttsu,
+	17: engine.YakuSanshokuDoujun,
+	18: engine.YakuDoubleRiichi,
+	19: engine.YakuSanshokuDoukou,
+	20: engine.YakuSankantsu,
+	21: engine.YakuToitoi,
+	22: engine.YakuSanankou,
+	23: engine.YakuShousangen,
+	24: engine.YakuHonroutou,
+	25: engine.YakuChiitoitsu,
+	26: engine.YakuJunchan,
+	27: engine.YakuHonitsu,
+	28: engine.YakuRyanpeikou,
+	29: engine.YakuChinitsu,
+	30: engine.YakuIppatsu,
+	31: engine.YakuDora,
+	32: engine.YakuAkaDora,
+	33: engine.YakuUraDora,
+	35: engine.YakuTenhou,
+	36: engine.YakuChiihou,
+	37: engine.YakuDaisangen,
+	38: engine.YakuSuuankou,
+	39: engine.YakuTsuuiisou,
+	40: engine.YakuRyuuiisou,
+	41: engine.YakuChinroutou,
+	42: engine.YakuKokushi,
+	43: engine.YakuShousuushii,
+	44: engine.YakuSuukantsu,
+	45: engine.YakuChuuren,
+	47: engine.YakuJunseiChuuren,
+	48: engine.YakuSuuankouTanki,
+	49: engine.YakuKokushi13,
+	50: engine.YakuDaisuushii,
+	59: engine.YakuRenhou,
+}
+
+func yakuFromFan(id int) (engine.Yaku, error) {
+	y, ok := fanByID[id]
+	if !ok {
+		return 0, fmt.Errorf("unsupported fan id %d", id)
+	}
+	return y, nil
+}
@@ -0,0 +1,705 @@
+package majsoul
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Paipu is a decoded Mahjong Soul game record: the game head plus the
+// round records in play order. Record names may keep the ".lq." package
+// prefix of the protobuf messages.
+type Paipu struct {
+	Head    Head     `json:"head"`
+	Records []Record `json:"records"`
+}
+
+// Head holds the parts of the game head the importer uses.
+type Head struct {
+	UUID   string `json:"uuid"`
+	Config struct {
+		Mode struct {
+			Mode int `json:"mode"`
+		} `json:"mode"`
+	} `json:"config"`
+	Accounts []struct {
+		Seat     int    `json:"seat"`
+		Nickname string `json:"nickname"`
+	} `json:"accounts"`
+	Result struct {
+		Players []struct {
+			Seat       int `json:"seat"`
+			PartPoint1 int `json:"part_point_1"`
+		} `json:"players"`
+	} `json:"result"`
+}
+
+// Record is one wrapped round record, e.g. RecordDealTile.
+type Record struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Game modes from head.config.mode.mode. Three-player modes are 11 and 12.
+const (
+	modeEast  = 1
+	modeSouth = 2
+)
+
+// liqi is the riichi confirmation attached to the record after the
+// riichi discard.
+type liqi struct {
+	Seat int `json:"seat"`
+}
+
+type recordNewRound struct {
+	Chang    int      `json:"chang"`
+	Ju       int      `json:"ju"`
+	Ben      int      `json:"ben"`
+	Liqibang int      `json:"liqibang"`
+	Dora     string   `json:"dora"`
+	Doras    []string `json:"doras"`
+	Scores   []int    `json:"scores"`
+	Tiles0   []string `json:"tiles0"`
+	Tiles1   []string `json:"tiles1"`
+	Tiles2   []string `json:"tiles2"`
+	Tiles3   []string `json:"tiles3"`
+}
+
+type recordDealTile struct {
+	Seat  int      `json:"seat"`
+	Tile  string   `json:"tile"`
+	Liqi  *liqi    `json:"liqi"`
+	Doras []string `json:"doras"`
+}
+
+type recordDiscardTile struct {
+	Seat    int      `json:"seat"`
+	Tile    string   `json:"tile"`
+	IsLiqi  bool     `json:"is_liqi"`
+	IsWliqi bool     `json:"is_wliqi"`
+	Moqie   bool     `json:"moqie"`
+	Doras   []string `json:"doras"`
+}
+
+// Call types of RecordChiPengGang and RecordAnGangAddGang.
+const (
+	callChi     = 0
+	callPon     = 1
+	callMinkan  = 2
+	callAddGang = 2
+	callAnGang  = 3
+)
+
+type recordChiPengGang struct {
+	Seat  int      `json:"seat"`
+	Type  int      `json:"type"`
+	Tiles []string `json:"tiles"`
+	Froms []int    `json:"froms"`
+	Liqi  *liqi    `json:"liqi"`
+}
+
+type recordAnGangAddGang struct {
+	Seat  int      `json:"seat"`
+	Type  int      `json:"type"`
+	Tiles string   `json:"tiles"`
+	Doras []string `json:"doras"`
+}
+
+type fan struct {
+	ID  int `json:"id"`
+	Val int `json:"val"`
+}
+
+type huleInfo struct {
+	Hand          []string `json:"hand"`
+	HuTile        string   `json:"hu_tile"`
+	Seat          int      `json:"seat"`
+	Zimo          bool     `json:"zimo"`
+	Qinjia        bool     `json:"qinjia"`
+	Doras         []string `json:"doras"`
+	LiDoras       []string `json:"li_doras"`
+	Fans          []fan    `json:"fans"`
+	Fu            int      `json:"fu"`
+	PointRong     int      `json:"point_rong"`
+	PointZimoQin  int      `json:"point_zimo_qin"`
+	PointZimoXian int      `json:"point_zimo_xian"`
+}
+
+type recordHule struct {
+	Hules       []huleInfo `json:"hules"`
+	DeltaScores []int      `json:"delta_scores"`
+}
+
+type recordNoTile struct {
+	Liujumanguan bool `json:"liujumanguan"`
+	Players      []struct {
+		Tingpai bool `json:"tingpai"`
+	} `json:"players"`
+	Scores []struct {
+		DeltaScores []int `json:"delta_scores"`
+	} `json:"scores"`
+}
+
+type recordLiuJu struct {
+	Type int `json:"type"`
+	Seat int `json:"seat"`
+}
+
+// liujuTypes maps RecordLiuJu.type to a draw reason.
+var liujuTypes = map[int]engine.RyuukyokuReason{
+	1: engine.RyuukyokuKyuushu,
+	2: engine.RyuukyokuSuufon,
+	3: engine.RyuukyokuSuuchaRiichi,
+	4: engine.RyuukyokuSuukaikan,
+	5: engine.RyuukyokuSanchahou,
+}
+
+// LoadPaipu reads a decoded Mahjong Soul record saved as JSON.
+func LoadPaipu(path string) ([]engine.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := ParsePaipu(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// ParsePaipu converts a decoded Mahjong Soul record into engine events.
+// Three-player games are rejected.
+func ParsePaipu(r io.Reader) ([]engine.Event, error) {
+	var pp Paipu
+	if err := json.NewDecoder(r).Decode(&pp); err != nil {
+		return nil, fmt.Errorf("invalid paipu json: %w", err)
+	}
+	return pp.Events()
+}
+
+// Events converts the record into engine events.
+func (pp *Paipu) Events() ([]engine.Event, error) {
+	p := &paipuParser{}
+	if err := p.head(&pp.Head); err != nil {
+		return nil, err
+	}
+	for i, rec := range pp.Records {
+		name := strings.TrimPrefix(rec.Name, ".lq.")
+		if err := p.record(name, rec.Data); err != nil {
+			return nil, fmt.Errorf("record %d (%s): %w", i, name, err)
+		}
+	}
+	if !slices.ContainsFunc(p.events, func(e engine.Event) bool { return e.Type == engine.EventRoundStart }) {
+		return nil, fmt.Errorf("paipu contains no rounds")
+	}
+
+	if res := pp.Head.Result.Players; len(res) > 0 {
+		end := &engine.GameEnd{}
+		for _, pl := range res {
+			if pl.Seat < 0 || pl.Seat > 3 {
+				return nil, fmt.Errorf("result: invalid seat %d", pl.Seat)
+			}
+			end.Scores[pl.Seat] = pl.PartPoint1
+		}
+		p.emit(engine.Event{Type: engine.EventGameEnd, End: end})
+	}
+	return p.events, nil
+}
+
+type paipuParser struct {
+	events  []engine.Event
+	inRound bool
+	dealer  int
+	doras   int
+	hands   [4][]engine.Tile
+	melds   [4][]engine.Meld
+}
+
+func (p *paipuParser) emit(e engine.Event) {
+	p.events = append(p.events, e)
+}
+
+func (p *paipuParser) head(h *Head) error {
+	rules := engine.MahjongSoulRules()
+	switch h.Config.Mode.Mode {
+	case modeEast:
+		rules.Length = engine.GameEast
+	case modeSouth, 0:
+		rules.Length = engine.GameEastSouth
+	default:
+		return fmt.Errorf("unsupported game mode %d", h.Config.Mode.Mode)
+	}
+	if len(h.Accounts) > 4 {
+		return fmt.Errorf("head lists %d accounts", len(h.Accounts))
+	}
+
+	g := &engine.GameStart{Rules: rules}
+	for _, a := range h.Accounts {
+		if a.Seat < 0 || a.Seat > 3 {
+			return fmt.Errorf("account %q: invalid seat %d", a.Nickname, a.Seat)
+		}
+		g.Players[a.Seat] = a.Nickname
+	}
+	p.emit(engine.Event{Type: engine.EventGameStart, Game: g})
+	return nil
+}
+
+func (p *paipuParser) record(name string, data json.RawMessage) error {
+	if name != "RecordNewRound" && !p.inRound {
+		return fmt.Errorf("record before the first round")
+	}
+	switch name {
+	case "RecordNewRound":
+		var rec recordNewRound
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.newRound(&rec)
+	case "RecordDealTile":
+		var rec recordDealTile
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.dealTile(&rec)
+	case "RecordDiscardTile":
+		var rec recordDiscardTile
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.discardTile(&rec)
+	case "RecordChiPengGang":
+		var rec recordChiPengGang
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.chiPengGang(&rec)
+	case "RecordAnGangAddGang":
+		var rec recordAnGangAddGang
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.anGangAddGang(&rec)
+	case "RecordHule":
+		var rec recordHule
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.hule(&rec)
+	case "RecordNoTile":
+		var rec recordNoTile
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.noTile(&rec)
+	case "RecordLiuJu":
+		var rec recordLiuJu
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		return p.liuJu(&rec)
+	case "RecordBaBei":
+		return fmt.Errorf("three-player games are not supported")
+	}
+	return fmt.Errorf("unknown record type")
+}
+
+func (p *paipuParser) newRound(rec *recordNewRound) error {
+	if rec.Chang < 0 || rec.Chang > 3 || rec.Ju < 0 || rec.Ju > 3 {
+		return fmt.Errorf("invalid round chang=%d ju=%d", rec.Chang, rec.Ju)
+	}
+	if len(rec.Scores) != 4 {
+		return fmt.Errorf("got %d scores, three-player games are not supported", len(rec.Scores))
+	}
+	doras := rec.Doras
+	if len(doras) == 0 && rec.Dora != "" {
+		doras = []string{rec.Dora}
+	}
+	if len(doras) == 0 {
+		return fmt.Errorf("missing dora indicator")
+	}
+
+	rs := &engine.RoundStart{
+		Wind:         engine.Wind(rec.Chang),
+		Number:       rec.Ju,
+		Honba:        rec.Ben,
+		RiichiSticks: rec.Liqibang,
+		Dealer:       rec.Ju,
+	}
+	copy(rs.Scores[:], rec.Scores)
+	var err error
+	if rs.DoraIndicator, err = parseTile(doras[0]); err != nil {
+		return err
+	}
+
+	p.inRound = true
+	p.dealer = rec.Ju
+	p.doras = 1
+	p.melds = [4][]engine.Meld{}
+
+	// The dealer's starting fourteen tiles include the first draw, which
+	// becomes a draw event like in the other log formats.
+	var first engine.Tile
+	for seat, names := range [4][]string{rec.Tiles0, rec.Tiles1, rec.Tiles2, rec.Tiles3} {
+		hand, err := parseTiles(names)
+		if err != nil {
+			return fmt.Errorf("tiles%d: %w", seat, err)
+		}
+		want := 13
+		if seat == p.dealer {
+			want = 14
+		}
+		if len(hand) != want {
+			return fmt.Errorf("tiles%d: got %d tiles, want %d", seat, len(hand), want)
+		}
+		if seat == p.dealer {
+			first = hand[13]
+			hand = hand[:13]
+		}
+		rs.Hands[seat] = hand
+		p.hands[seat] = slices.Clone(hand)
+	}
+
+	p.emit(engine.Event{Type: engine.EventRoundStart, Round: rs})
+	p.draw(p.dealer, first)
+	return p.newDoras(doras)
+}
+
+func (p *paipuParser) draw(seat int, t engine.Tile) {
+	p.hands[seat] = append(p.hands[seat], t)
+	p.emit(engine.Event{Type: engine.EventDrawTile, Seat: seat, Tile: t})
+}
+
+// newDoras emits dora events for indicators not seen yet; records repeat
+// the full list.
+func (p *paipuParser) newDoras(doras []string) error {
+	for ; p.doras < len(doras); p.doras++ {
+		t, err := parseTile(doras[p.doras])
+		if err != nil {
+			return err
+		}
+		p.emit(engine.Event{Type: engine.EventDora, Tile: t})
+	}
+	return nil
+}
+
+// accept emits the riichi confirmation carried by the record after the
+// riichi discard.
+func (p *paipuParser) accept(l *liqi) error {
+	if l == nil {
+		return nil
+	}
+	if err := checkSeat(l.Seat); err != nil {
+		return err
+	}
+	p.emit(engine.Event{Type: engine.EventRiichiAccepted, Seat: l.Seat})
+	return nil
+}
+
+func (p *paipuParser) dealTile(rec *recordDealTile) error {
+	if err := checkSeat(rec.Seat); err != nil {
+		return err
+	}
+	if err := p.accept(rec.Liqi); err != nil {
+		return err
+	}
+	t, err := parseTile(rec.Tile)
+	if err != nil {
+		return err
+	}
+	p.draw(rec.Seat, t)
+	return p.newDoras(rec.Doras)
+}
+
+func (p *paipuParser) discardTile(rec *recordDiscardTile) error {
+	if err := checkSeat(rec.Seat); err != nil {
+		return err
+	}
+	t, err := parseTile(rec.Tile)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(p.hands[rec.Seat], t) {
+		return fmt.Errorf("seat %d discards %s which is not in hand", rec.Seat, t)
+	}
+	p.hands[rec.Seat] = engine.RemoveTile(p.hands[rec.Seat], t)
+
+	if rec.IsLiqi || rec.IsWliqi {
+		p.emit(engine.Event{Type: engine.EventRiichi, Seat: rec.Seat})
+	}
+	p.emit(engine.Event{Type: engine.EventDiscard, Seat: rec.Seat, Tile: t, Tsumogiri: rec.Moqie})
+	return p.newDoras(rec.Doras)
+}
+
+func (p *paipuParser) chiPengGang(rec *recordChiPengGang) error {
+	if err := checkSeat(rec.Seat); err != nil {
+		return err
+	}
+	if err := p.accept(rec.Liqi); err != nil {
+		return err
+	}
+
+	var kind engine.MeldKind
+	want := 3
+	switch rec.Type {
+	case callChi:
+		kind = engine.MeldChi
+	case callPon:
+		kind = engine.MeldPon
+	case callMinkan:
+		kind, want = engine.MeldKan, 4
+	default:
+		return fmt.Errorf("unknown call type %d", rec.Type)
+	}
+	if len(rec.Tiles) != want || len(rec.Froms) != want {
+		return fmt.Errorf("%s needs %d tiles and froms, got %d and %d", kind, want, len(rec.Tiles), len(rec.Froms))
+	}
+
+	meld := engine.Meld{Kind: kind, From: -1}
+	var own []engine.Tile
+	for i, name := range rec.Tiles {
+		t, err := parseTile(name)
+		if err != nil {
+			return err
+		}
+		if rec.Froms[i] == rec.Seat {
+			own = append(own, t)
+			continue
+		}
+		if meld.From >= 0 {
+			return fmt.Errorf("%s takes more than one tile from other seats", kind)
+		}
+		if err := checkSeat(rec.Froms[i]); err != nil {
+			return err
+		}
+		meld.Called, meld.From = t, rec.Froms[i]
+	}
+	if meld.From < 0 {
+		return fmt.Errorf("%s does not take a discard", kind)
+	}
+	meld.Tiles = append([]engine.Tile{meld.Called}, own...)
+	for _, t := range own {
+		p.hands[rec.Seat] = engine.RemoveTile(p.hands[rec.Seat], t)
+	}
+	p.melds[rec.Seat] = append(p.melds[rec.Seat], meld)
+	p.emit(engine.Event{Type: engine.EventCall, Seat: rec.Seat, Meld: &meld})
+	return nil
+}
+
+func (p *paipuParser) anGangAddGang(rec *recordAnGangAddGang) error {
+	if err := checkSeat(rec.Seat); err != nil {
+		return err
+	}
+	t, err := parseTile(rec.Tiles)
+	if err != nil {
+		return err
+	}
+	hand := p.hands[rec.Seat]
+
+	var meld engine.Meld
+	switch rec.Type {
+	case callAnGang:
+		// The record names only the kind; take the actual tiles, red fives
+		// included, from the hand.
+		meld = engine.Meld{Kind: engine.MeldAnkan, From: rec.Seat}
+		for _, h := range hand {
+			if h.Plain() == t.Plain() {
+				meld.Tiles = append(meld.Tiles, h)
+			}
+		}
+		if len(meld.Tiles) != 4 {
+			return fmt.Errorf("ankan of %s with %d tiles in hand", t, len(meld.Tiles))
+		}
+		meld.Called = meld.Tiles[3]
+		for _, h := range meld.Tiles {
+			hand = engine.RemoveTile(hand, h)
+		}
+		p.melds[rec.Seat] = append(p.melds[rec.Seat], meld)
+
+	case callAddGang:
+		i := slices.IndexFunc(p.melds[rec.Seat], func(m engine.Meld) bool {
+			return m.Kind == engine.MeldPon && m.Called.Plain() == t.Plain()
+		})
+		if i < 0 {
+			return fmt.Errorf("kakan of %s without a pon", t)
+		}
+		added := slices.IndexFunc(hand, func(h engine.Tile) bool { return h.Plain() == t.Plain() })
+		if added < 0 {
+			return fmt.Errorf("kakan of %s which is not in hand", t)
+		}
+		pon := p.melds[rec.Seat][i]
+		meld = engine.Meld{
+			Kind:   engine.MeldShouminkan,
+			Tiles:  append(slices.Clone(pon.Tiles), hand[added]),
+			Called: hand[added],
+			From:   pon.From,
+		}
+		hand = slices.Delete(hand, added, added+1)
+		p.melds[rec.Seat][i] = meld
+
+	default:
+		return fmt.Errorf("unknown kan type %d", rec.Type)
+	}
+
+	p.hands[rec.Seat] = hand
+	p.emit(engine.Event{Type: engine.EventCall, Seat: rec.Seat, Meld: &meld})
+	return p.newDoras(rec.Doras)
+}
+
+func (p *paipuParser) hule(rec *recordHule) error {
+	if len(rec.Hules) == 0 {
+		return fmt.Errorf("no winners")
+	}
+	var total [4]int
+	copy(total[:], rec.DeltaScores)
+
+	// Multiple ron share one delta list. Each win gets its own hand value;
+	// honba and riichi sticks stay with the first winner.
+	results := make([]*engine.WinResult, 0, len(rec.Hules))
+	var rest [4]int
+	for _, h := range rec.Hules {
+		w, err := p.winResult(&h)
+		if err != nil {
+			return err
+		}
+		if !w.IsTsumo() {
+			w.Deltas[w.Seat] = w.Points
+			w.Deltas[w.From] = -w.Points
+		}
+		for i := range rest {
+			rest[i] += w.Deltas[i]
+		}
+		results = append(results, w)
+	}
+	if len(results) == 1 {
+		results[0].Deltas = total
+	} else {
+		for i := range total {
+			results[0].Deltas[i] += total[i] - rest[i]
+		}
+	}
+
+	for _, w := range results {
+		p.emit(engine.Event{Type: engine.EventWin, Seat: w.Seat, Tile: w.Tile, Win: w})
+	}
+	p.inRound = false
+	return nil
+}
+
+func (p *paipuParser) winResult(h *huleInfo) (*engine.WinResult, error) {
+	if err := checkSeat(h.Seat); err != nil {
+		return nil, err
+	}
+	w := &engine.WinResult{Seat: h.Seat, From: h.Seat, Fu: h.Fu, Melds: slices.Clone(p.melds[h.Seat])}
+	if !h.Zimo {
+		from, ok := p.lastDiscarder()
+		if !ok {
+			return nil, fmt.Errorf("ron without a discard")
+		}
+		w.From = from
+	}
+
+	var err error
+	if w.Tile, err = parseTile(h.HuTile); err != nil {
+		return nil, err
+	}
+	if w.Hand, err = parseTiles(h.Hand); err != nil {
+		return nil, err
+	}
+	w.Hand = append(w.Hand, w.Tile)
+	if w.DoraIndicators, err = parseTiles(h.Doras); err != nil {
+		return nil, err
+	}
+	if w.UraIndicators, err = parseTiles(h.LiDoras); err != nil {
+		return nil, err
+	}
+
+	for _, f := range h.Fans {
+		y, err := yakuFromFan(f.ID)
+		if err != nil {
+			return nil, err
+		}
+		han := f.Val
+		if y.IsYakuman() {
+			han = 13 * max(f.Val, 1)
+		}
+		if han == 0 {
+			continue
+		}
+		w.Yaku = append(w.Yaku, engine.YakuHan{Yaku: y, Han: han})
+		w.Han += han
+	}
+
+	switch {
+	case !h.Zimo:
+		w.Points = h.PointRong
+	case h.Qinjia:
+		w.Points = 3 * h.PointZimoXian
+	default:
+		w.Points = h.PointZimoQin + 2*h.PointZimoXian
+	}
+	return w, nil
+}
+
+// lastDiscarder returns who played the tile a ron is declared on: the
+// last discard, or the added tile of a kakan for chankan.
+func (p *paipuParser) lastDiscarder() (int, bool) {
+	for i := len(p.events) - 1; i >= 0; i-- {
+		e := p.events[i]
+		switch {
+		case e.Type == engine.EventRoundStart:
+			return 0, false
+		case e.Type == engine.EventDiscard,
+			e.Type == engine.EventCall && e.Meld.Kind == engine.MeldShouminkan:
+			return e.Seat, true
+		}
+	}
+	return 0, false
+}
+
+func (p *paipuParser) noTile(rec *recordNoTile) error {
+	res := &engine.RyuukyokuResult{Reason: engine.RyuukyokuExhaustive}
+	if rec.Liujumanguan {
+		res.Reason = engine.RyuukyokuNagashiMangan
+	}
+	for i := 0; i < len(rec.Players) && i < 4; i++ {
+		res.Tenpai[i] = rec.Players[i].Tingpai
+	}
+	// Each nagashi mangan gets its own score entry; the deltas add up.
+	for _, sc := range rec.Scores {
+		for i := 0; i < len(sc.DeltaScores) && i < 4; i++ {
+			res.Deltas[i] += sc.DeltaScores[i]
+		}
+	}
+	p.emit(engine.Event{Type: engine.EventRyuukyoku, Ryuukyoku: res})
+	p.inRound = false
+	return nil
+}
+
+func (p *paipuParser) liuJu(rec *recordLiuJu) error {
+	reason, ok := liujuTypes[rec.Type]
+	if !ok {
+		return fmt.Errorf("unknown liuju type %d", rec.Type)
+	}
+	if err := checkSeat(rec.Seat); err != nil {
+		return err
+	}
+	res := &engine.RyuukyokuResult{Reason: reason}
+	p.emit(engine.Event{Type: engine.EventRyuukyoku, Seat: rec.Seat, Ryuukyoku: res})
+	p.inRound = false
+	return nil
+}
+
+func checkSeat(seat int) error {
+	if seat < 0 || seat > 3 {
+		return fmt.Errorf("invalid seat %d", seat)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package majsoul
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestLoadPaipu(t *testing.T) {
+	events, err := LoadPaipu("testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadPaipu failed: %v", err)
+	}
+	if _, err := engine.Replay(events, -1); err != nil {
+		t.Errorf("events do not replay: %v", err)
+	}
+
+	want := []string{
+		"game_start",
+		"round_start",
+		"draw seat=0 1z",
+		"discard seat=0 1z",
+		"draw seat=1 7z",
+		"discard seat=1 8p",
+		"draw seat=2 5s",
+		"call seat=2 ankan(1s1s1s1s)",
+		"dora 6m",
+		"draw seat=2 9p",
+		"discard seat=2 9m",
+		"call seat=3 pon(9m9m9m)",
+		"discard seat=3 4p",
+		"call seat=0 chi(4p2p3p)",
+		"discard seat=0 9s",
+		"draw seat=1 4p",
+		"riichi seat=1",
+		"discard seat=1 6p",
+		"riichi_accepted seat=1",
+		"draw seat=2 8m",
+		"discard seat=2 8m",
+		"draw seat=3 9m",
+		"call seat=3 shouminkan(9m9m9m9m)",
+		"draw seat=3 5p",
+		"dora 1p",
+		"discard seat=3 5p",
+		"win",
+		"game_end",
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events:\n got  %q\n want %q", got, want)
+	}
+
+	game := events[0].Game
+	if game.Players != [4]string{"A", "B", "C", "D"} || game.Rules.Length != engine.GameEast {
+		t.Errorf("unexpected game start: %+v", game)
+	}
+	round := events[1].Round
+	if round.Dealer != 0 || len(round.Hands[0]) != 13 || round.DoraIndicator.String() != "3s" {
+		t.Errorf("unexpected round: %+v", round)
+	}
+	if !events[3].Tsumogiri || events[5].Tsumogiri {
+		t.Error("tsumogiri flags not carried over")
+	}
+	if m := events[11].Meld; m.From != 2 {
+		t.Errorf("pon From = %d, want 2", m.From)
+	}
+	if m := events[13].Meld; m.From != 3 || m.Called.String() != "4p" {
+		t.Errorf("chi From = %d, Called = %v", m.From, m.Called)
+	}
+	if m := events[22].Meld; m.From != 2 || len(m.Tiles) != 4 {
+		t.Errorf("kakan meld = %+v, want 4 tiles from seat 2", m)
+	}
+
+	win := events[26].Win
+	if win.Seat != 1 || win.From != 3 || win.Tile.String() != "5p" || len(win.Hand) != 14 {
+		t.Errorf("unexpected win: %+v", win)
+	}
+	if win.Han != 6 || win.Fu != 40 || win.Points != 12000 {
+		t.Errorf("han/fu/points = %d/%d/%d", win.Han, win.Fu, win.Points)
+	}
+	// Ura dora is listed with zero han and dropped.
+	if len(win.Yaku) != 4 || win.Yaku[1].Yaku != engine.YakuChun {
+		t.Errorf("unexpected yaku: %v", win.Yaku)
+	}
+	if win.Deltas != [4]int{0, 13000, 0, -12000} {
+		t.Errorf("deltas = %v", win.Deltas)
+	}
+
+	if end := events[27].End; end.Scores != [4]int{25000, 37000, 25000, 13000} {
+		t.Errorf("final scores = %v", end.Scores)
+	}
+}
+
+func TestParsePaipu_DoubleRon(t *testing.T) {
+	doc := `{"head": {"config": {"mode": {"mode": 2}}}, "records": [
+		{"name": "RecordNewRound", "data": {"chang": 1, "ju": 2, "ben": 1, "liqibang": 1, "doras": ["1m"],
+			"scores": [25000, 25000, 25000, 25000],
+			"tiles0": ["1m","1m","1m","2m","2m","2m","3m","3m","3m","4m","4m","4m","5m"],
+			"tiles1": ["1p","1p","1p","2p","2p","2p","3p","3p","3p","4p","4p","4p","5p"],
+			"tiles2": ["1s","1s","1s","2s","2s","2s","3s","3s","3s","4s","4s","4s","5s","9s"],
+			"tiles3": ["1z","1z","1z","2z","2z","2z","3z","3z","3z","4z","4z","4z","7z"]}},
+		{"name": "RecordDiscardTile", "data": {"seat": 2, "tile": "9s"}},
+		{"name": "RecordHule", "data": {"hules": [
+			{"hand": ["1m","1m","1m","2m","2m","2m","3m","3m","3m","4m","4m","4m","5m"], "hu_tile": "9s", "seat": 0,
+			 "fu": 30, "fans": [{"id": 12, "val": 1}], "point_rong": 1000},
+			{"hand": ["1p","1p","1p","2p","2p","2p","3p","3p","3p","4p","4p","4p","5p"], "hu_tile": "9s", "seat": 1,
+			 "fu": 30, "fans": [{"id": 12, "val": 1}], "point_rong": 1000}],
+			"delta_scores": [2300, 1000, -2300, 0]}}]}`
+
+	events, err := ParsePaipu(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ParsePaipu failed: %v", err)
+	}
+	if events[0].Game.Rules.Length != engine.GameEastSouth {
+		t.Errorf("mode 2 should be a hanchan")
+	}
+	if rs := events[1].Round; rs.Wind != engine.WindSouth || rs.Number != 2 || rs.Dealer != 2 {
+		t.Errorf("unexpected round: %+v", rs)
+	}
+
+	wins := events[len(events)-2:]
+	first, second := wins[0].Win, wins[1].Win
+	if first.From != 2 || second.From != 2 {
+		t.Errorf("ron From = %d, %d, want 2", first.From, second.From)
+	}
+	if first.Deltas != [4]int{2300, 0, -1300, 0} || second.Deltas != [4]int{0, 1000, -1000, 0} {
+		t.Errorf("deltas = %v, %v", first.Deltas, second.Deltas)
+	}
+}
+
+func TestParsePaipu_Draws(t *testing.T) {
+	tests := []struct {
+		record string
+		reason engine.RyuukyokuReason
+		deltas [4]int
+	}{
+		{`{"name": "RecordNoTile", "data": {"players": [{"tingpai": true}, {}, {}, {}],
+			"scores": [{"delta_scores": [3000, -1000, -1000, -1000]}]}}`,
+			engine.RyuukyokuExhaustive, [4]int{3000, -1000, -1000, -1000}},
+		{`{"name": "RecordNoTile", "data": {"liujumanguan": true,
+			"scores": [{"delta_scores": [12000, -4000, -4000, -4000]}, {"delta_scores": [-4000, 8000, -2000, -2000]}]}}`,
+			engine.RyuukyokuNagashiMangan, [4]int{8000, 4000, -6000, -6000}},
+		{`{"name": "RecordLiuJu", "data": {"type": 1, "seat": 0}}`, engine.RyuukyokuKyuushu, [4]int{}},
+	}
+	for _, tt := range tests {
+		doc := `{"head": {}, "records": [` + newRound + `,` + tt.record + `]}`
+		events, err := ParsePaipu(strings.NewReader(doc))
+		if err != nil {
+			t.Errorf("%s: %v", tt.record, err)
+			continue
+		}
+		res := events[len(events)-1].Ryuukyoku
+		if res == nil || res.Reason != tt.reason || res.Deltas != tt.deltas {
+			t.Errorf("%s: got %+v", tt.record, res)
+		}
+	}
+}
+
+const newRound = `{"name": "RecordNewRound", "data": {"doras": ["1m"], "scores": [25000, 25000, 25000, 25000],
+	"tiles0": ["1m","1m","1m","2m","2m","2m","3m","3m","3m","4m","4m","4m","5m","5m"],
+	"tiles1": ["1p","1p","1p","2p","2p","2p","3p","3p","3p","4p","4p","4p","5p"],
+	"tiles2": ["1s","1s","1s","2s","2s","2s","3s","3s","3s","4s","4s","4s","5s"],
+	"tiles3": ["1z","1z","1z","2z","2z","2z","3z","3z","3z","4z","4z","4z","7z"]}}`
+
+func TestParsePaipu_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"not json", `{`},
+		{"no rounds", `{"head": {}, "records": []}`},
+		{"sanma mode", `{"head": {"config": {"mode": {"mode": 11}}}, "records": [` + newRound + `]}`},
+		{"before round", `{"head": {}, "records": [{"name": "RecordDealTile", "data": {"seat": 0, "tile": "1m"}}]}`},
+		{"unknown record", `{"head": {}, "records": [` + newRound + `, {"name": "RecordFoo", "data": {}}]}`},
+		{"kita", `{"head": {}, "records": [` + newRound + `, {"name": "RecordBaBei", "data": {"seat": 0}}]}`},
+		{"bad tile", `{"head": {}, "records": [` + newRound + `, {"name": "RecordDealTile", "data": {"seat": 1, "tile": "5x"}}]}`},
+		{"bad seat", `{"head": {}, "records": [` + newRound + `, {"name": "RecordDealTile", "data": {"seat": 4, "tile": "5m"}}]}`},
+		{"discard not in hand", `{"head": {}, "records": [` + newRound + `, {"name": "RecordDiscardTile", "data": {"seat": 0, "tile": "9p"}}]}`},
+		{"kakan without pon", `{"head": {}, "records": [` + newRound + `, {"name": "RecordAnGangAddGang", "data": {"seat": 0, "type": 2, "tiles": "1m"}}]}`},
+		{"ankan short", `{"head": {}, "records": [` + newRound + `, {"name": "RecordAnGangAddGang", "data": {"seat": 0, "type": 3, "tiles": "5m"}}]}`},
+		{"unknown fan", `{"head": {}, "records": [` + newRound + `, {"name": "RecordHule", "data": {"hules": [
+			{"hand": [], "hu_tile": "5m", "seat": 0, "zimo": true, "fans": [{"id": 46, "val": 13}]}]}}]}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePaipu(strings.NewReader(tt.doc)); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
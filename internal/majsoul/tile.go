@@ -0,0 +1,32 @@
+package majsoul
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Mahjong Soul writes tiles like our compact notation: "0m" is a red five
+// and honors are 1z-7z in the same order as NewTile.
+func parseTile(s string) (engine.Tile, error) {
+	if len(s) != 2 || s[0] < '0' || s[0] > '9' || s[1] != 'm' && s[1] != 'p' && s[1] != 's' && s[1] != 'z' {
+		return 0, fmt.Errorf("invalid tile %q", s)
+	}
+	t, err := engine.ParseTile(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tile %q", s)
+	}
+	return t, nil
+}
+
+func parseTiles(names []string) ([]engine.Tile, error) {
+	out := make([]engine.Tile, 0, len(names))
+	for _, n := range names {
+		t, err := parseTile(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,34 @@
+package majsoul
+
+import "testing"
+
+func TestParseTile(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1m", want: "1m"},
+		{in: "0p", want: "0p"},
+		{in: "9s", want: "9s"},
+		{in: "5z", want: "5z"},
+		{in: "7z", want: "7z"},
+		{in: "8z", wantErr: true},
+		{in: "0z", wantErr: true},
+		{in: "E", wantErr: true},
+		{in: "5mr", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTile(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTile(%q) = %s, expected error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("parseTile(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package mjai
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/jsonl"
+)
+
+// Conn reads and writes mjai messages as JSON lines.
+type Conn struct {
+	s *jsonl.Stream
+}
+
+// NewConn wraps a reader and writer, e.g. a bot's stdout and stdin.
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{s: jsonl.New(r, w, nil)}
+}
+
+// Send writes one message.
+func (c *Conn) Send(m Message) error {
+	return c.s.Send(m)
+}
+
+// Recv reads the next message. Blank lines are skipped.
+func (c *Conn) Recv() (Message, error) {
+	var m Message
+	err := c.s.Recv(&m)
+	return m, err
+}
+
+// Bot is an mjai client sitting in one seat. Every message sent to it is
+// answered with an action or "none".
+type Bot struct {
+	Name string
+	Seat int
+
+	conn   *Conn
+	tr     *Translator
+	closer func() error
+}
+
+// NewBot wraps an established connection for the given seat.
+func NewBot(conn *Conn, seat int, closer func() error) *Bot {
+	return &Bot{Seat: seat, conn: conn, tr: NewTranslator(seat), closer: closer}
+}
+
+// StartProcess launches a bot that speaks mjai on stdin and stdout.
+func StartProcess(seat int, name string, args ...string) (*Bot, error) {
+	s, err := jsonl.Start(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBot(&Conn{s: s}, seat, s.Close)
+	b.Name = name
+	return b, nil
+}
+
+// Update sends the messages for one engine event and collects the bot's
+// replies. The returned events are the actions the bot wants to take;
+// "none" replies are dropped.
+func (b *Bot) Update(e engine.Event) ([]engine.Event, error) {
+	var actions []engine.Event
+	for _, m := range b.tr.Messages(e) {
+		reply, err := b.React(m)
+		if err != nil {
+			return nil, err
+		}
+		action, ok, err := reply.Event()
+		if err != nil {
+			return nil, fmt.Errorf("bot %q: %w", b.Name, err)
+		}
+		if ok {
+			if action.Seat != b.Seat {
+				return nil, fmt.Errorf("bot %q in seat %d acted for seat %d", b.Name, b.Seat, action.Seat)
+			}
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// React sends one message and waits for the reply.
+func (b *Bot) React(m Message) (Message, error) {
+	if err := b.conn.Send(m); err != nil {
+		return Message{}, fmt.Errorf("bot %q: send %s: %w", b.Name, m.Type, err)
+	}
+	reply, err := b.conn.Recv()
+	if err != nil {
+		return Message{}, fmt.Errorf("bot %q: reply to %s: %w", b.Name, m.Type, err)
+	}
+	return reply, nil
+}
+
+// Close ends the session and releases the process or connection.
+func (b *Bot) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer()
+}
+
+// Server accepts mjai bots over TCP.
+type Server struct {
+	*jsonl.Server
+}
+
+// Listen starts a server for mjai bots on addr.
+func Listen(addr string) (*Server, error) {
+	s, err := jsonl.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{s}, nil
+}
+
+// Accept waits for a bot to connect, performs the hello/join handshake and
+// seats it.
+func (s *Server) Accept(seat int) (*Bot, error) {
+	st, name, err := s.accept()
+	if err != nil {
+		return nil, err
+	}
+	b := NewBot(&Conn{s: st}, seat, st.Close)
+	b.Name = name
+	return b, nil
+}
+
+// AcceptPlayer is Accept for a bot that plays through package table.
+func (s *Server) AcceptPlayer() (*Player, error) {
+	st, name, err := s.accept()
+	if err != nil {
+		return nil, err
+	}
+	return &Player{Name: name, s: st}, nil
+}
+
+// accept waits for a bot and performs the handshake, returning the name
+// it joined with.
+func (s *Server) accept() (*jsonl.Stream, string, error) {
+	st, err := s.Server.Accept()
+	if err != nil {
+		return nil, "", err
+	}
+	conn := &Conn{s: st}
+	if err := conn.Send(Message{Type: TypeHello, Protocol: "mjsonp", ProtocolVersion: 3}); err != nil {
+		st.Close()
+		return nil, "", err
+	}
+	join, err := conn.Recv()
+	if err != nil {
+		st.Close()
+		return nil, "", err
+	}
+	if join.Type != TypeJoin {
+		st.Close()
+		return nil, "", fmt.Errorf("expected join, got %q", join.Type)
+	}
+	return st, join.Name, nil
+}
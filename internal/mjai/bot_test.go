@@ -0,0 +1,160 @@
+package mjai
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// fakeBot answers every message with "none", except that it discards the
+// tile it just drew.
+func fakeBot(t *testing.T, conn *Conn, seat int) {
+	t.Helper()
+	for {
+		m, err := conn.Recv()
+		if err != nil {
+			return
+		}
+		reply := Message{Type: TypeNone}
+		if m.Type == TypeTsumo && *m.Actor == seat {
+			reply = Message{Type: TypeDahai, Actor: intp(seat), Pai: m.Pai, Tsumogiri: boolp(true)}
+		}
+		if err := conn.Send(reply); err != nil {
+			return
+		}
+	}
+}
+
+func TestBot_Update(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	go fakeBot(t, NewConn(client, client), 1)
+
+	b := NewBot(NewConn(server, server), 1, server.Close)
+	defer b.Close()
+
+	five, _ := engine.ParseTile("0s")
+	actions, err := b.Update(engine.Event{Type: engine.EventDrawTile, Seat: 1, Tile: five})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(actions) != 1 || actions[0].String() != "discard seat=1 0s" || !actions[0].Tsumogiri {
+		t.Errorf("actions = %v", actions)
+	}
+
+	actions, err = b.Update(engine.Event{Type: engine.EventDrawTile, Seat: 2, Tile: five})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no actions for another seat's draw, got %v", actions)
+	}
+}
+
+func TestBot_RejectsActionForOtherSeat(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	// The bot believes it sits in seat 0.
+	go fakeBot(t, NewConn(client, client), 0)
+
+	b := NewBot(NewConn(server, server), 2, server.Close)
+	defer b.Close()
+
+	if _, err := b.Update(engine.Event{Type: engine.EventDrawTile, Seat: 0}); err == nil {
+		t.Error("expected error for an action in another seat")
+	}
+}
+
+func TestServer_Accept(t *testing.T) {
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer s.Close()
+
+	go func() {
+		nc, err := net.Dial("tcp", s.Addr().String())
+		if err != nil {
+			return
+		}
+		defer nc.Close()
+		conn := NewConn(nc, nc)
+		if hello, err := conn.Recv(); err != nil || hello.Type != TypeHello {
+			return
+		}
+		conn.Send(Message{Type: TypeJoin, Name: "tsumogiri", Room: "default"})
+		fakeBot(t, conn, 3)
+	}()
+
+	b, err := s.Accept(3)
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer b.Close()
+	if b.Name != "tsumogiri" || b.Seat != 3 {
+		t.Errorf("bot = %q in seat %d", b.Name, b.Seat)
+	}
+
+	tile, _ := engine.ParseTile("7z")
+	actions, err := b.Update(engine.Event{Type: engine.EventDrawTile, Seat: 3, Tile: tile})
+	if err != nil || len(actions) != 1 || actions[0].Tile != tile {
+		t.Errorf("actions = %v, err = %v", actions, err)
+	}
+}
+
+func TestServer_AcceptPlayer(t *testing.T) {
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer s.Close()
+
+	go func() {
+		nc, err := net.Dial("tcp", s.Addr().String())
+		if err != nil {
+			return
+		}
+		defer nc.Close()
+		conn := NewConn(nc, nc)
+		if hello, err := conn.Recv(); err != nil || hello.Type != TypeHello {
+			return
+		}
+		conn.Send(Message{Type: TypeJoin, Name: "tsumogiri"})
+		fakeBot(t, conn, 1)
+	}()
+
+	p, err := s.AcceptPlayer()
+	if err != nil {
+		t.Fatalf("AcceptPlayer failed: %v", err)
+	}
+	defer p.Close()
+	if p.Name != "tsumogiri" {
+		t.Errorf("Name = %q", p.Name)
+	}
+	tile, _ := engine.ParseTile("7z")
+	p.Observe(1, engine.Event{Type: engine.EventDrawTile, Seat: 1, Tile: tile})
+	a, err := p.Turn(context.Background(), nil, []table.Action{{Type: table.ActionDiscard, Tile: tile}})
+	if err != nil || a.Tile != tile {
+		t.Errorf("Turn = %s, %v", a, err)
+	}
+}
+
+func TestConn_RecvSkipsBlankLines(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		w.Write([]byte("\n  \n{\"type\":\"none\"}\n"))
+		w.Close()
+	}()
+	conn := NewConn(r, io.Discard)
+	m, err := conn.Recv()
+	if err != nil || m.Type != TypeNone {
+		t.Fatalf("Recv = %+v, %v", m, err)
+	}
+	if _, err := conn.Recv(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
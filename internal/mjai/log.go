@@ -0,0 +1,67 @@
+package mjai
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// ReadLog reads an mjai log (one message per line, full information) into
+// engine events.
+func ReadLog(r io.Reader) ([]engine.Event, error) {
+	conn := NewConn(r, io.Discard)
+	var events []engine.Event
+	for line := 1; ; line++ {
+		m, err := conn.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		e, ok, err := m.Event()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !ok {
+			continue
+		}
+		if e.Type == engine.EventCall && e.Meld.Kind == engine.MeldShouminkan {
+			e.Meld.From = ponSource(events, e.Seat, e.Meld.Called)
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("mjai log contains no events")
+	}
+	return events, nil
+}
+
+// ponSource finds who the pon upgraded by a kakan was called from.
+func ponSource(events []engine.Event, seat int, added engine.Tile) int {
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if e.Type == engine.EventRoundStart {
+			break
+		}
+		if e.Type == engine.EventCall && e.Seat == seat && e.Meld.Kind == engine.MeldPon && e.Meld.Called.Plain() == added.Plain() {
+			return e.Meld.From
+		}
+	}
+	return seat
+}
+
+// WriteLog writes events as a full-information mjai log.
+func WriteLog(w io.Writer, events []engine.Event) error {
+	conn := NewConn(nil, w)
+	tr := NewTranslator(-1)
+	for _, e := range events {
+		for _, m := range tr.Messages(e) {
+			if err := conn.Send(m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
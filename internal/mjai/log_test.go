@@ -0,0 +1,97 @@
+package mjai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestLog_RoundTrip(t *testing.T) {
+	events := loadSample(t)
+
+	var buf bytes.Buffer
+	if err := WriteLog(&buf, events); err != nil {
+		t.Fatalf("WriteLog failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"type":"daiminkan"`) {
+		t.Errorf("log missing daiminkan:\n%s", buf.String())
+	}
+
+	got, err := ReadLog(&buf)
+	if err != nil {
+		t.Fatalf("ReadLog failed: %v", err)
+	}
+	str := func(events []engine.Event) []string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.String())
+		}
+		return out
+	}
+	if !slices.Equal(str(got), str(events)) {
+		t.Fatalf("events:\n got  %q\n want %q", str(got), str(events))
+	}
+
+	win, want := got[len(got)-1].Win, events[len(events)-1].Win
+	if win.Seat != want.Seat || win.From != want.From || win.Points != want.Points || win.Deltas != want.Deltas {
+		t.Errorf("win = %+v, want %+v", win, want)
+	}
+	if !slices.Equal(win.Hand, want.Hand) {
+		t.Errorf("win hand = %v, want %v", win.Hand, want.Hand)
+	}
+}
+
+func TestReadLog_KakanSource(t *testing.T) {
+	log := strings.Join([]string{
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m"}`,
+		`{"type":"pon","actor":0,"target":2,"pai":"P","consumed":["P","P"]}`,
+		`{"type":"kakan","actor":0,"pai":"P","consumed":["P","P","P"]}`,
+	}, "\n")
+	events, err := ReadLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ReadLog failed: %v", err)
+	}
+	if m := events[2].Meld; m.Kind != engine.MeldShouminkan || m.From != 2 {
+		t.Errorf("kakan meld = %+v, want shouminkan from 2", m)
+	}
+}
+
+func TestReadLog_Hidden(t *testing.T) {
+	// A log recorded from seat 0's point of view.
+	log := strings.Join([]string{
+		`{"type":"start_game","id":0,"names":["a","b","c","d"]}`,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","scores":[25000,25000,25000,25000],` +
+			`"tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","4p"],` +
+			`["?","?","?","?","?","?","?","?","?","?","?","?","?"],` +
+			`["?","?","?","?","?","?","?","?","?","?","?","?","?"],` +
+			`["?","?","?","?","?","?","?","?","?","?","?","?","?"]]}`,
+		`{"type":"tsumo","actor":0,"pai":"5pr"}`,
+		`{"type":"dahai","actor":0,"pai":"1m","tsumogiri":false}`,
+		`{"type":"tsumo","actor":1,"pai":"?"}`,
+	}, "\n")
+	_, err := ReadLog(strings.NewReader(log))
+	if !errors.Is(err, ErrHidden) {
+		t.Errorf("ReadLog of a player's view: %v, want ErrHidden", err)
+	}
+
+	var m Message
+	if err := json.Unmarshal([]byte(`{"type":"tsumo","actor":1,"pai":"?"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.Event(); !errors.Is(err, ErrHidden) {
+		t.Errorf("hidden tsumo: %v, want ErrHidden", err)
+	}
+}
+
+func TestReadLog_Errors(t *testing.T) {
+	for _, log := range []string{"", "not json\n", `{"type":"dahai","actor":9,"pai":"1m"}`} {
+		if _, err := ReadLog(strings.NewReader(log)); err == nil {
+			t.Errorf("ReadLog(%q) expected error", log)
+		}
+	}
+}
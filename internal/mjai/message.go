@@ -0,0 +1,437 @@
+package mjai
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// ErrHidden is returned for messages with tiles hidden from the viewer,
+// which cannot be turned into engine events.
+var ErrHidden = errors.New("hidden tile in a full-information message")
+
+// Message is one line of the mjai protocol. Only the fields used by Type
+// are set. Seat-valued fields are pointers because seat 0 must still be
+// written out.
+type Message struct {
+	Type string `json:"type"`
+
+	// Handshake (TCP only).
+	Protocol        string `json:"protocol,omitempty"`
+	ProtocolVersion int    `json:"protocol_version,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Room            string `json:"room,omitempty"`
+
+	ID    *int     `json:"id,omitempty"`
+	Names []string `json:"names,omitempty"`
+
+	Bakaze     string     `json:"bakaze,omitempty"`
+	Kyoku      int        `json:"kyoku,omitempty"`
+	Honba      *int       `json:"honba,omitempty"`
+	Kyotaku    *int       `json:"kyotaku,omitempty"`
+	Oya        *int       `json:"oya,omitempty"`
+	DoraMarker string     `json:"dora_marker,omitempty"`
+	Tehais     [][]string `json:"tehais,omitempty"`
+
+	Actor     *int     `json:"actor,omitempty"`
+	Target    *int     `json:"target,omitempty"`
+	Pai       string   `json:"pai,omitempty"`
+	Consumed  []string `json:"consumed,omitempty"`
+	Tsumogiri *bool    `json:"tsumogiri,omitempty"`
+
+	UradoraMarkers []string `json:"uradora_markers,omitempty"`
+	HoraTehais     []string `json:"hora_tehais,omitempty"`
+	Fu             int      `json:"fu,omitempty"`
+	Fan            int      `json:"fan,omitempty"`
+	HoraPoints     int      `json:"hora_points,omitempty"`
+	Reason         string   `json:"reason,omitempty"`
+	Tenpais        []bool   `json:"tenpais,omitempty"`
+	Deltas         []int    `json:"deltas,omitempty"`
+	Scores         []int    `json:"scores,omitempty"`
+
+	Message string `json:"message,omitempty"` // error text
+}
+
+// Message types.
+const (
+	TypeHello         = "hello"
+	TypeJoin          = "join"
+	TypeError         = "error"
+	TypeNone          = "none"
+	TypeStartGame     = "start_game"
+	TypeStartKyoku    = "start_kyoku"
+	TypeTsumo         = "tsumo"
+	TypeDahai         = "dahai"
+	TypeChi           = "chi"
+	TypePon           = "pon"
+	TypeDaiminkan     = "daiminkan"
+	TypeAnkan         = "ankan"
+	TypeKakan         = "kakan"
+	TypeReach         = "reach"
+	TypeReachAccepted = "reach_accepted"
+	TypeDora          = "dora"
+	TypeHora          = "hora"
+	TypeRyukyoku      = "ryukyoku"
+	TypeEndKyoku      = "end_kyoku"
+	TypeEndGame       = "end_game"
+)
+
+func intp(v int) *int    { return &v }
+func boolp(v bool) *bool { return &v }
+
+// ryukyokuReasons maps draw reasons to the mjai reason strings.
+var ryukyokuReasons = map[engine.RyuukyokuReason]string{
+	engine.RyuukyokuExhaustive:    "fanpai",
+	engine.RyuukyokuKyuushu:       "kyushukyuhai",
+	engine.RyuukyokuSuufon:        "sufonrenta",
+	engine.RyuukyokuSuuchaRiichi:  "suchareach",
+	engine.RyuukyokuSuukaikan:     "sukaikan",
+	engine.RyuukyokuSanchahou:     "sanchaho",
+	engine.RyuukyokuNagashiMangan: "nagashimangan",
+}
+
+var callTypes = map[engine.MeldKind]string{
+	engine.MeldChi:        TypeChi,
+	engine.MeldPon:        TypePon,
+	engine.MeldKan:        TypeDaiminkan,
+	engine.MeldAnkan:      TypeAnkan,
+	engine.MeldShouminkan: TypeKakan,
+}
+
+// Translator turns engine events into the messages one seat receives.
+// It hides other players' hands and draws and keeps the running scores
+// that mjai attaches to score changes.
+type Translator struct {
+	viewer  int
+	scores  [4]int
+	inKyoku bool
+}
+
+// NewTranslator returns a translator for seat viewer. A viewer of -1 sees
+// every tile, which is what mjai log files contain.
+func NewTranslator(viewer int) *Translator {
+	return &Translator{viewer: viewer}
+}
+
+func (tr *Translator) hidden(seat int) bool {
+	return tr.viewer >= 0 && seat != tr.viewer
+}
+
+// Messages returns the messages for one event. Most events map to one
+// message; round results are followed by end_kyoku when the next round
+// or the game end arrives.
+func (tr *Translator) Messages(e engine.Event) []Message {
+	var out []Message
+	if tr.inKyoku && (e.Type == engine.EventRoundStart || e.Type == engine.EventGameEnd) {
+		out = append(out, Message{Type: TypeEndKyoku})
+		tr.inKyoku = false
+	}
+
+	switch e.Type {
+	case engine.EventGameStart:
+		out = append(out, Message{Type: TypeStartGame, ID: intp(max(tr.viewer, 0)), Names: e.Game.Players[:]})
+
+	case engine.EventRoundStart:
+		rs := e.Round
+		tr.scores = rs.Scores
+		tr.inKyoku = true
+		m := Message{
+			Type:       TypeStartKyoku,
+			Bakaze:     rs.Wind.String(),
+			Kyoku:      rs.Number + 1,
+			Honba:      intp(rs.Honba),
+			Kyotaku:    intp(rs.RiichiSticks),
+			Oya:        intp(rs.Dealer),
+			DoraMarker: formatTile(rs.DoraIndicator),
+			Scores:     rs.Scores[:],
+		}
+		for seat, hand := range rs.Hands {
+			tiles := formatTiles(hand)
+			if tr.hidden(seat) {
+				for i := range tiles {
+					tiles[i] = Unknown
+				}
+			}
+			m.Tehais = append(m.Tehais, tiles)
+		}
+		out = append(out, m)
+
+	case engine.EventDrawTile:
+		pai := formatTile(e.Tile)
+		if tr.hidden(e.Seat) {
+			pai = Unknown
+		}
+		out = append(out, Message{Type: TypeTsumo, Actor: intp(e.Seat), Pai: pai})
+
+	case engine.EventDiscard:
+		out = append(out, Message{Type: TypeDahai, Actor: intp(e.Seat), Pai: formatTile(e.Tile), Tsumogiri: boolp(e.Tsumogiri)})
+
+	case engine.EventCall:
+		out = append(out, callMessage(e.Seat, *e.Meld))
+
+	case engine.EventRiichi:
+		out = append(out, Message{Type: TypeReach, Actor: intp(e.Seat)})
+
+	case engine.EventRiichiAccepted:
+		var deltas [4]int
+		deltas[e.Seat] = -1000
+		tr.scores[e.Seat] -= 1000
+		out = append(out, Message{Type: TypeReachAccepted, Actor: intp(e.Seat), Deltas: deltas[:], Scores: slices.Clone(tr.scores[:])})
+
+	case engine.EventDora:
+		out = append(out, Message{Type: TypeDora, DoraMarker: formatTile(e.Tile)})
+
+	case engine.EventWin:
+		w := e.Win
+		for i, d := range w.Deltas {
+			tr.scores[i] += d
+		}
+		out = append(out, Message{
+			Type:           TypeHora,
+			Actor:          intp(w.Seat),
+			Target:         intp(w.From),
+			Pai:            formatTile(w.Tile),
+			UradoraMarkers: formatTiles(w.UraIndicators),
+			HoraTehais:     formatTiles(w.Hand),
+			Fu:             w.Fu,
+			Fan:            w.Han,
+			HoraPoints:     w.Points,
+			Deltas:         w.Deltas[:],
+			Scores:         slices.Clone(tr.scores[:]),
+		})
+
+	case engine.EventRyuukyoku:
+		r := e.Ryuukyoku
+		for i, d := range r.Deltas {
+			tr.scores[i] += d
+		}
+		out = append(out, Message{
+			Type:    TypeRyukyoku,
+			Reason:  ryukyokuReasons[r.Reason],
+			Tenpais: r.Tenpai[:],
+			Deltas:  r.Deltas[:],
+			Scores:  slices.Clone(tr.scores[:]),
+		})
+
+	case engine.EventGameEnd:
+		out = append(out, Message{Type: TypeEndGame, Scores: e.End.Scores[:]})
+	}
+	return out
+}
+
+// callMessage encodes a meld. consumed lists the caller's own tiles: all
+// four for ankan, the original pon for kakan.
+func callMessage(seat int, m engine.Meld) Message {
+	msg := Message{Type: callTypes[m.Kind], Actor: intp(seat)}
+	if m.Kind == engine.MeldAnkan {
+		msg.Consumed = formatTiles(m.Tiles)
+		return msg
+	}
+
+	own := slices.Clone(m.Tiles)
+	if i := slices.Index(own, m.Called); i >= 0 {
+		own = slices.Delete(own, i, i+1)
+	}
+	msg.Pai = formatTile(m.Called)
+	msg.Consumed = formatTiles(own)
+	if m.Kind != engine.MeldShouminkan {
+		msg.Target = intp(m.From)
+	}
+	return msg
+}
+
+// Event converts a message into an engine event. ok is false for messages
+// that have no engine counterpart (none, hello, join, end_kyoku, ...).
+//
+// Engine events carry every tile, so a hidden tile ("?") in a hand, draw
+// or discard is an ErrHidden error. A kakan message does not say where the
+// original pon came from, so the meld's From is left as the actor; ReadLog
+// fills it in from the pon.
+func (m Message) Event() (e engine.Event, ok bool, err error) {
+	actor := -1
+	if m.Actor != nil {
+		actor = *m.Actor
+		if actor < 0 || actor > 3 {
+			return e, false, fmt.Errorf("%s: invalid actor %d", m.Type, actor)
+		}
+	}
+	needActor := func() error {
+		if actor < 0 {
+			return fmt.Errorf("%s: missing actor", m.Type)
+		}
+		return nil
+	}
+
+	switch m.Type {
+	case TypeStartGame:
+		g := &engine.GameStart{Rules: engine.DefaultRules()}
+		copy(g.Players[:], m.Names)
+		return engine.Event{Type: engine.EventGameStart, Game: g}, true, nil
+
+	case TypeStartKyoku:
+		rs := &engine.RoundStart{Number: m.Kyoku - 1}
+		wind, err := ParseTile(m.Bakaze)
+		if err != nil || !wind.IsWind() {
+			return e, false, fmt.Errorf("start_kyoku: invalid bakaze %q", m.Bakaze)
+		}
+		rs.Wind = engine.Wind(wind.Rank() - 1)
+		if m.Honba != nil {
+			rs.Honba = *m.Honba
+		}
+		if m.Kyotaku != nil {
+			rs.RiichiSticks = *m.Kyotaku
+		}
+		if m.Oya != nil {
+			rs.Dealer = *m.Oya
+		}
+		if rs.DoraIndicator, err = ParseTile(m.DoraMarker); err != nil {
+			return e, false, fmt.Errorf("start_kyoku: %w", err)
+		}
+		copy(rs.Scores[:], m.Scores)
+		for i := 0; i < len(m.Tehais) && i < 4; i++ {
+			if slices.Contains(m.Tehais[i], Unknown) {
+				return e, false, fmt.Errorf("start_kyoku: seat %d: %w", i, ErrHidden)
+			}
+			if rs.Hands[i], err = parseTiles(m.Tehais[i]); err != nil {
+				return e, false, fmt.Errorf("start_kyoku: %w", err)
+			}
+		}
+		return engine.Event{Type: engine.EventRoundStart, Round: rs}, true, nil
+
+	case TypeTsumo, TypeDahai:
+		if err := needActor(); err != nil {
+			return e, false, err
+		}
+		e = engine.Event{Type: engine.EventDrawTile, Seat: actor}
+		if m.Type == TypeDahai {
+			e.Type = engine.EventDiscard
+			e.Tsumogiri = m.Tsumogiri != nil && *m.Tsumogiri
+		}
+		if m.Pai == Unknown {
+			return e, false, fmt.Errorf("%s: seat %d: %w", m.Type, actor, ErrHidden)
+		}
+		if e.Tile, err = ParseTile(m.Pai); err != nil {
+			return e, false, fmt.Errorf("%s: %w", m.Type, err)
+		}
+		return e, true, nil
+
+	case TypeChi, TypePon, TypeDaiminkan, TypeAnkan, TypeKakan:
+		if err := needActor(); err != nil {
+			return e, false, err
+		}
+		meld, err := m.meld(actor)
+		if err != nil {
+			return e, false, fmt.Errorf("%s: %w", m.Type, err)
+		}
+		return engine.Event{Type: engine.EventCall, Seat: actor, Meld: &meld}, true, nil
+
+	case TypeReach, TypeReachAccepted:
+		if err := needActor(); err != nil {
+			return e, false, err
+		}
+		e = engine.Event{Type: engine.EventRiichi, Seat: actor}
+		if m.Type == TypeReachAccepted {
+			e.Type = engine.EventRiichiAccepted
+		}
+		return e, true, nil
+
+	case TypeDora:
+		t, err := ParseTile(m.DoraMarker)
+		if err != nil {
+			return e, false, fmt.Errorf("dora: %w", err)
+		}
+		return engine.Event{Type: engine.EventDora, Tile: t}, true, nil
+
+	case TypeHora:
+		if err := needActor(); err != nil {
+			return e, false, err
+		}
+		w := &engine.WinResult{Seat: actor, From: actor, Fu: m.Fu, Han: m.Fan, Points: m.HoraPoints}
+		if m.Target != nil {
+			w.From = *m.Target
+		}
+		if m.Pai != "" {
+			if w.Tile, err = ParseTile(m.Pai); err != nil {
+				return e, false, fmt.Errorf("hora: %w", err)
+			}
+		}
+		if w.UraIndicators, err = parseTiles(m.UradoraMarkers); err != nil {
+			return e, false, fmt.Errorf("hora: %w", err)
+		}
+		if w.Hand, err = parseTiles(m.HoraTehais); err != nil {
+			return e, false, fmt.Errorf("hora: %w", err)
+		}
+		copy(w.Deltas[:], m.Deltas)
+		return engine.Event{Type: engine.EventWin, Seat: actor, Tile: w.Tile, Win: w}, true, nil
+
+	case TypeRyukyoku:
+		r := &engine.RyuukyokuResult{Reason: engine.RyuukyokuExhaustive}
+		for reason, name := range ryukyokuReasons {
+			if name == m.Reason {
+				r.Reason = reason
+			}
+		}
+		copy(r.Tenpai[:], m.Tenpais)
+		copy(r.Deltas[:], m.Deltas)
+		return engine.Event{Type: engine.EventRyuukyoku, Seat: max(actor, 0), Ryuukyoku: r}, true, nil
+
+	case TypeEndGame:
+		end := &engine.GameEnd{}
+		copy(end.Scores[:], m.Scores)
+		return engine.Event{Type: engine.EventGameEnd, End: end}, true, nil
+
+	case TypeNone, TypeHello, TypeJoin, TypeEndKyoku:
+		return e, false, nil
+
+	case TypeError:
+		return e, false, fmt.Errorf("mjai error: %s", m.Message)
+	}
+	return e, false, fmt.Errorf("unknown message type %q", m.Type)
+}
+
+func (m Message) meld(actor int) (engine.Meld, error) {
+	consumed, err := parseTiles(m.Consumed)
+	if err != nil {
+		return engine.Meld{}, err
+	}
+
+	var kind engine.MeldKind
+	for k, name := range callTypes {
+		if name == m.Type {
+			kind = k
+		}
+	}
+	meld := engine.Meld{Kind: kind, From: actor}
+
+	if kind == engine.MeldAnkan {
+		if len(consumed) != 4 {
+			return engine.Meld{}, fmt.Errorf("ankan needs 4 consumed tiles, got %d", len(consumed))
+		}
+		meld.Tiles = consumed
+		meld.Called = consumed[len(consumed)-1]
+		return meld, nil
+	}
+
+	called, err := ParseTile(m.Pai)
+	if err != nil {
+		return engine.Meld{}, err
+	}
+	want := 2
+	if kind.IsKan() {
+		want = 3
+	}
+	if len(consumed) != want {
+		return engine.Meld{}, fmt.Errorf("%s needs %d consumed tiles, got %d", m.Type, want, len(consumed))
+	}
+	if kind != engine.MeldShouminkan {
+		if m.Target == nil || *m.Target < 0 || *m.Target > 3 || *m.Target == actor {
+			return engine.Meld{}, fmt.Errorf("invalid target")
+		}
+		meld.From = *m.Target
+	}
+	meld.Called = called
+	meld.Tiles = append([]engine.Tile{called}, consumed...)
+	return meld, nil
+}
@@ -0,0 +1,133 @@
+package mjai
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/tenhou"
+)
+
+func loadSample(t *testing.T) []engine.Event {
+	t.Helper()
+	events, err := tenhou.LoadJSON("../tenhou/testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	return events
+}
+
+func TestTranslator_Messages(t *testing.T) {
+	tr := NewTranslator(-1)
+	var types []string
+	for _, e := range loadSample(t) {
+		for _, m := range tr.Messages(e) {
+			types = append(types, m.Type)
+		}
+	}
+	want := []string{
+		"start_game", "start_kyoku",
+		"tsumo", "dahai", "daiminkan", "dora",
+		"tsumo", "dahai", "tsumo", "dahai", "tsumo", "dahai",
+		"tsumo", "reach", "dahai", "reach_accepted",
+		"tsumo", "dahai", "chi", "dahai", "hora",
+	}
+	if !slices.Equal(types, want) {
+		t.Fatalf("types:\n got  %q\n want %q", types, want)
+	}
+}
+
+func TestTranslator_MasksOtherSeats(t *testing.T) {
+	tr := NewTranslator(3)
+	for _, e := range loadSample(t) {
+		for _, m := range tr.Messages(e) {
+			switch m.Type {
+			case TypeStartKyoku:
+				for seat, hand := range m.Tehais {
+					masked := slices.Contains(hand, Unknown)
+					if masked != (seat != 3) {
+						t.Errorf("seat %d tehai masked = %v", seat, masked)
+					}
+				}
+			case TypeTsumo:
+				if hidden := m.Pai == Unknown; hidden != (*m.Actor != 3) {
+					t.Errorf("tsumo for seat %d: pai %q", *m.Actor, m.Pai)
+				}
+			}
+		}
+	}
+}
+
+func TestTranslator_EndKyoku(t *testing.T) {
+	tr := NewTranslator(0)
+	rs := &engine.RoundStart{Scores: [4]int{25000, 25000, 25000, 25000}}
+	tr.Messages(engine.Event{Type: engine.EventRoundStart, Round: rs})
+	msgs := tr.Messages(engine.Event{Type: engine.EventRiichiAccepted, Seat: 2})
+	if got := msgs[0].Scores[2]; got != 24000 {
+		t.Errorf("score after riichi = %d, want 24000", got)
+	}
+
+	msgs = tr.Messages(engine.Event{Type: engine.EventGameEnd, End: &engine.GameEnd{}})
+	if len(msgs) != 2 || msgs[0].Type != TypeEndKyoku || msgs[1].Type != TypeEndGame {
+		t.Errorf("expected end_kyoku then end_game, got %+v", msgs)
+	}
+}
+
+func TestMessage_Event(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`{"type":"tsumo","actor":2,"pai":"1p"}`, "draw seat=2 1p"},
+		{`{"type":"dahai","actor":0,"pai":"5mr","tsumogiri":true}`, "discard seat=0 0m"},
+		{`{"type":"pon","actor":1,"target":3,"pai":"C","consumed":["C","C"]}`, "call seat=1 pon(7z7z7z)"},
+		{`{"type":"chi","actor":1,"target":0,"pai":"3s","consumed":["4s","5sr"]}`, "call seat=1 chi(3s4s0s)"},
+		{`{"type":"ankan","actor":3,"consumed":["E","E","E","E"]}`, "call seat=3 ankan(1z1z1z1z)"},
+		{`{"type":"reach","actor":0}`, "riichi seat=0"},
+		{`{"type":"dora","dora_marker":"9p"}`, "dora 9p"},
+	}
+	for _, tt := range tests {
+		var m Message
+		if err := json.Unmarshal([]byte(tt.line), &m); err != nil {
+			t.Fatalf("unmarshal %s: %v", tt.line, err)
+		}
+		e, ok, err := m.Event()
+		if err != nil || !ok {
+			t.Errorf("%s: ok=%v err=%v", tt.line, ok, err)
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMessage_EventErrors(t *testing.T) {
+	for _, line := range []string{
+		`{"type":"dahai","pai":"1m"}`,
+		`{"type":"dahai","actor":4,"pai":"1m"}`,
+		`{"type":"dahai","actor":0,"pai":"1x"}`,
+		`{"type":"pon","actor":1,"pai":"C","consumed":["C","C"]}`,
+		`{"type":"pon","actor":1,"target":1,"pai":"C","consumed":["C","C"]}`,
+		`{"type":"ankan","actor":1,"consumed":["C","C","C"]}`,
+		`{"type":"error","message":"bad"}`,
+		`{"type":"bogus"}`,
+	} {
+		var m Message
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("unmarshal %s: %v", line, err)
+		}
+		if _, _, err := m.Event(); err == nil {
+			t.Errorf("%s: expected error", line)
+		}
+	}
+}
+
+func TestMessage_NoEvent(t *testing.T) {
+	for _, typ := range []string{TypeNone, TypeHello, TypeJoin, TypeEndKyoku} {
+		if _, ok, err := (Message{Type: typ}).Event(); ok || err != nil {
+			t.Errorf("%s: ok=%v err=%v", typ, ok, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package mjai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/jsonl"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Player seats an mjai bot at a table game. It passes the bot the game's
+// events as its seat sees them. The bot answers every message, with
+// "none" or with what it does in response: a dahai after its own tsumo,
+// a pon after another seat's dahai, and so on. Its decision is the last
+// answer that is not "none".
+//
+// Events are held until the next decision is asked for, so waiting on a
+// slow bot is bounded by the table's deadline. Answers still owed for a
+// decision that timed out are read and dropped before the next one.
+type Player struct {
+	Name string
+
+	s       *jsonl.Stream
+	tr      *Translator
+	seat    int
+	queue   []Message
+	owed    int  // answers to sent messages not yet read
+	reached bool // reach sent ahead of the riichi the table plays next
+}
+
+// NewPlayer plays over a reader and writer, e.g. a bot's stdout and
+// stdin. closer, if not nil, is called by Close.
+func NewPlayer(r io.Reader, w io.Writer, closer func() error) *Player {
+	return &Player{s: jsonl.New(r, w, closer)}
+}
+
+// StartPlayer launches a bot that speaks mjai on stdin and stdout.
+func StartPlayer(name string, args ...string) (*Player, error) {
+	s, err := jsonl.Start(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Player{Name: name, s: s}, nil
+}
+
+// Observe queues the messages for e.
+func (p *Player) Observe(seat int, e engine.Event) {
+	if p.tr == nil || e.Type == engine.EventGameStart {
+		p.tr, p.seat = NewTranslator(seat), seat
+	}
+	if p.reached {
+		p.reached = false
+		if e.Type == engine.EventRiichi && e.Seat == seat {
+			return
+		}
+	}
+	p.queue = append(p.queue, p.tr.Messages(e)...)
+}
+
+func (p *Player) Turn(ctx context.Context, v *table.View, legal []table.Action) (table.Action, error) {
+	m, err := p.flush(ctx)
+	if err != nil {
+		return table.Action{}, err
+	}
+	riichi := false
+	if m.Type == TypeReach {
+		// The bot names its riichi discard in answer to its own reach.
+		p.queue = append(p.queue, p.tr.Messages(engine.Event{Type: engine.EventRiichi, Seat: p.seat})...)
+		p.reached, riichi = true, true
+		if m, err = p.flush(ctx); err != nil {
+			return table.Action{}, err
+		}
+	}
+	if m.Type == "" {
+		return table.Action{}, fmt.Errorf("bot %q: no action on its turn", p.Name)
+	}
+	return p.action(m, legal, true, riichi)
+}
+
+func (p *Player) Call(ctx context.Context, v *table.View, legal []table.Action) (table.Action, error) {
+	m, err := p.flush(ctx)
+	if err != nil {
+		return table.Action{}, err
+	}
+	if m.Type == "" {
+		return table.Action{Type: table.ActionPass}, nil
+	}
+	return p.action(m, legal, false, false)
+}
+
+// Close sends the events not yet sent, so the bot sees the game end, and
+// hangs up. Answers are read and dropped meanwhile.
+func (p *Player) Close() error {
+	lines := p.s.Lines()
+	go func() {
+		for range lines {
+		}
+	}()
+	for _, m := range p.queue {
+		if p.s.Send(m) != nil {
+			break
+		}
+	}
+	p.queue = nil
+	return p.s.Close()
+}
+
+// flush sends the queued messages one at a time, as mjai servers do,
+// reading the answer to each before the next. It returns the last answer
+// that is not "none", or a zero Message if there is none.
+func (p *Player) flush(ctx context.Context) (Message, error) {
+	stale := p.owed
+	var last Message
+	lines := p.s.Lines()
+	for {
+		for p.owed > 0 {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return Message{}, fmt.Errorf("bot %q: %w", p.Name, p.s.Err())
+				}
+				p.owed--
+				var m Message
+				if err := jsonl.Decode(line, &m); err != nil {
+					return Message{}, fmt.Errorf("bot %q: %w", p.Name, err)
+				}
+				if stale > 0 {
+					stale-- // the answer to a decision that timed out
+					continue
+				}
+				if m.Type != TypeNone {
+					last = m
+				}
+			case <-ctx.Done():
+				return Message{}, ctx.Err()
+			}
+		}
+		if len(p.queue) == 0 {
+			return last, nil
+		}
+		m := p.queue[0]
+		if err := p.s.Send(m); err != nil {
+			return Message{}, fmt.Errorf("bot %q: send %s: %w", p.Name, m.Type, err)
+		}
+		p.queue, p.owed = p.queue[1:], 1
+	}
+}
+
+// action finds the legal action a bot's answer stands for.
+func (p *Player) action(m Message, legal []table.Action, turn, riichi bool) (table.Action, error) {
+	e, ok, err := m.Event()
+	if err != nil {
+		return table.Action{}, fmt.Errorf("bot %q: %w", p.Name, err)
+	}
+	if !ok || e.Seat != p.seat {
+		return table.Action{}, fmt.Errorf("bot %q in seat %d answered %s", p.Name, p.seat, m.Type)
+	}
+
+	var match func(table.Action) bool
+	switch e.Type {
+	case engine.EventDiscard:
+		typ := table.ActionDiscard
+		if riichi {
+			typ = table.ActionRiichi
+		}
+		match = func(a table.Action) bool { return a.Type == typ && a.Tile == e.Tile }
+	case engine.EventWin:
+		typ := table.ActionRon
+		if turn {
+			typ = table.ActionTsumo
+		}
+		match = func(a table.Action) bool { return a.Type == typ }
+	case engine.EventCall:
+		match = func(a table.Action) bool { return a.Meld != nil && sameMeld(*a.Meld, *e.Meld) }
+	default:
+		return table.Action{}, fmt.Errorf("bot %q answered %s, which is not an action", p.Name, m.Type)
+	}
+	if i := slices.IndexFunc(legal, match); i >= 0 {
+		return legal[i], nil
+	}
+	return table.Action{}, fmt.Errorf("bot %q: %s is not legal", p.Name, e)
+}
+
+// sameMeld compares melds by kind and tiles; mjai lists the tiles in its
+// own order and does not say where an added kan's pon came from.
+func sameMeld(a, b engine.Meld) bool {
+	sorted := func(ts []engine.Tile) []engine.Tile {
+		ts = slices.Clone(ts)
+		slices.Sort(ts)
+		return ts
+	}
+	return a.Kind == b.Kind && slices.Equal(sorted(a.Tiles), sorted(b.Tiles))
+}
@@ -0,0 +1,175 @@
+package mjai
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/bot"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// scriptBot seats a bot that answers each message with answer and
+// returns the messages it got, closed once the player hangs up.
+func scriptBot(t *testing.T, answer func(Message) Message) (*Player, <-chan Message) {
+	t.Helper()
+	server, client := net.Pipe()
+	seen := make(chan Message, 10000)
+	go func() {
+		defer close(seen)
+		conn := NewConn(client, client)
+		for {
+			m, err := conn.Recv()
+			if err != nil {
+				return
+			}
+			seen <- m
+			if conn.Send(answer(m)) != nil {
+				return
+			}
+		}
+	}()
+	p := NewPlayer(server, server, server.Close)
+	p.Name = "script"
+	t.Cleanup(func() { p.Close() })
+	return p, seen
+}
+
+func none(Message) Message { return Message{Type: TypeNone} }
+
+func TestPlayer_Game(t *testing.T) {
+	const seat = 2
+	p, seen := scriptBot(t, func(m Message) Message {
+		if m.Type == TypeTsumo && *m.Actor == seat {
+			return Message{Type: TypeDahai, Actor: intp(seat), Pai: m.Pai, Tsumogiri: boolp(true)}
+		}
+		return none(m)
+	})
+	b := table.Local(bot.Bot{})
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	res, err := table.Play(context.Background(), [4]table.Player{b, b, p, b}, table.Config{Rules: rules, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Defaults[seat] != 0 {
+		t.Errorf("%d default actions for the mjai bot", res.Defaults[seat])
+	}
+	p.Close()
+
+	var last Message
+	draws := 0
+	for m := range seen {
+		last = m
+		switch m.Type {
+		case TypeStartKyoku:
+			for s, hand := range m.Tehais {
+				if hidden := slices.Contains(hand, Unknown); hidden != (s != seat) {
+					t.Errorf("seat %d hand %v sent to seat %d", s, hand, seat)
+				}
+			}
+		case TypeTsumo:
+			if hidden := m.Pai == Unknown; hidden != (*m.Actor != seat) {
+				t.Errorf("seat %d draw %q sent to seat %d", *m.Actor, m.Pai, seat)
+			}
+			draws++
+		}
+	}
+	if draws == 0 || last.Type != TypeEndGame {
+		t.Errorf("%d draws seen, last message %q", draws, last.Type)
+	}
+}
+
+func TestPlayer_Decisions(t *testing.T) {
+	tile := func(s string) engine.Tile {
+		tt, err := engine.ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tt
+	}
+	p, seen := scriptBot(t, func(m Message) Message {
+		switch {
+		case m.Type == TypeTsumo && *m.Actor == 0:
+			return Message{Type: TypeReach, Actor: intp(0)}
+		case m.Type == TypeReach && *m.Actor == 0:
+			return Message{Type: TypeDahai, Actor: intp(0), Pai: "1m", Tsumogiri: boolp(true)}
+		case m.Type == TypeDahai && *m.Actor == 3 && m.Pai == "5p":
+			return Message{Type: TypePon, Actor: intp(0), Target: intp(3), Pai: "5p", Consumed: []string{"5p", "5pr"}}
+		}
+		return none(m)
+	})
+
+	hand, _ := engine.ParseHandCompact("2345678m1234p55z")
+	rs := &engine.RoundStart{DoraIndicator: tile("1s")}
+	for s := range rs.Hands {
+		rs.Hands[s] = hand[:13]
+	}
+	p.Observe(0, engine.Event{Type: engine.EventGameStart, Game: &engine.GameStart{Rules: engine.DefaultRules()}})
+	p.Observe(0, engine.Event{Type: engine.EventRoundStart, Round: rs})
+	p.Observe(0, engine.Event{Type: engine.EventDrawTile, Seat: 0, Tile: tile("1m")})
+	riichi := table.Action{Type: table.ActionRiichi, Tile: tile("1m")}
+	legal := []table.Action{{Type: table.ActionDiscard, Tile: tile("1m")}, riichi}
+	if a, err := p.Turn(context.Background(), nil, legal); err != nil || !a.Equal(riichi) {
+		t.Errorf("Turn = %s, %v; want %s", a, err, riichi)
+	}
+	p.Observe(0, engine.Event{Type: engine.EventRiichi, Seat: 0})
+	p.Observe(0, engine.Event{Type: engine.EventDiscard, Seat: 0, Tile: tile("1m"), Tsumogiri: true})
+
+	// Another seat's tile: passed on, then called.
+	pass := table.Action{Type: table.ActionPass}
+	p.Observe(0, engine.Event{Type: engine.EventDiscard, Seat: 1, Tile: tile("9s")})
+	if a, err := p.Call(context.Background(), nil, []table.Action{pass}); err != nil || !a.Equal(pass) {
+		t.Errorf("Call = %s, %v; want pass", a, err)
+	}
+	p.Observe(0, engine.Event{Type: engine.EventDiscard, Seat: 3, Tile: tile("5p")})
+	pon := table.Action{Type: table.ActionPon, Meld: &engine.Meld{Kind: engine.MeldPon, Tiles: []engine.Tile{tile("5p"), tile("0p"), tile("5p")}, Called: tile("5p"), From: 3}}
+	if a, err := p.Call(context.Background(), nil, []table.Action{pass, pon}); err != nil || !a.Equal(pon) {
+		t.Errorf("Call = %s, %v; want %s", a, err, pon)
+	}
+	p.Observe(0, engine.Event{Type: engine.EventDiscard, Seat: 3, Tile: tile("5p")})
+	if _, err := p.Call(context.Background(), nil, []table.Action{pass}); err == nil {
+		t.Error("a pon that is not legal was accepted")
+	}
+
+	p.Close()
+	reaches := 0
+	for m := range seen {
+		if m.Type == TypeReach {
+			reaches++
+		}
+	}
+	if reaches != 1 {
+		t.Errorf("bot told of %d reaches, want 1", reaches)
+	}
+}
+
+func TestPlayer_Timeout(t *testing.T) {
+	p, _ := scriptBot(t, func(m Message) Message {
+		if m.Type != TypeTsumo {
+			return none(m)
+		}
+		if m.Pai == "1m" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return Message{Type: TypeDahai, Actor: intp(0), Pai: m.Pai}
+	})
+	one, _ := engine.ParseTile("1m")
+	two, _ := engine.ParseTile("2m")
+	legal := []table.Action{{Type: table.ActionDiscard, Tile: one}, {Type: table.ActionDiscard, Tile: two}}
+
+	p.Observe(0, engine.Event{Type: engine.EventDrawTile, Seat: 0, Tile: one})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := p.Turn(ctx, nil, legal); err == nil {
+		t.Fatal("no error past the deadline")
+	}
+	// The late answer to the 1m draw is dropped.
+	p.Observe(0, engine.Event{Type: engine.EventDrawTile, Seat: 0, Tile: two})
+	if a, err := p.Turn(context.Background(), nil, legal); err != nil || a.Tile != two {
+		t.Errorf("Turn = %s, %v; want the 2m discard", a, err)
+	}
+}
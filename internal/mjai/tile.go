@@ -0,0 +1,107 @@
+package mjai
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Unknown is the tile string mjai uses for tiles hidden from the viewer.
+const Unknown = "?"
+
+// mjai writes honors as letters: winds E S W N, then P (haku, white),
+// F (hatsu, green) and C (chun, red). Red fives get an "r" suffix: "5mr".
+var honorNames = [8]string{1: "E", 2: "S", 3: "W", 4: "N", 5: "P", 6: "F", 7: "C"}
+
+// ParseTile converts an mjai tile string to a tile kind.
+func ParseTile(s string) (engine.Tile, error) {
+	for rank, name := range honorNames {
+		if name != "" && s == name {
+			return engine.NewTile(engine.SuitHonor, rank)
+		}
+	}
+
+	if len(s) == 3 && s[0] == '5' && s[2] == 'r' {
+		suit, err := parseSuit(s[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid mjai tile %q", s)
+		}
+		return engine.NewRedFive(suit)
+	}
+	if len(s) != 2 || s[0] < '1' || s[0] > '9' {
+		return 0, fmt.Errorf("invalid mjai tile %q", s)
+	}
+	suit, err := parseSuit(s[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid mjai tile %q", s)
+	}
+	return engine.NewTile(suit, int(s[0]-'0'))
+}
+
+func parseSuit(c byte) (engine.Suit, error) {
+	switch c {
+	case 'm':
+		return engine.SuitManzu, nil
+	case 'p':
+		return engine.SuitPinzu, nil
+	case 's':
+		return engine.SuitSouzu, nil
+	default:
+		return 0, fmt.Errorf("invalid suit %q", c)
+	}
+}
+
+// formatTile converts a riichi tile kind to its mjai string. mjai has no
+// name for flowers and jokers, which riichi games do not use; FormatTiles
+// rejects them.
+func formatTile(t engine.Tile) string {
+	if t.IsHonor() {
+		return honorNames[t.Rank()]
+	}
+	if t.IsRed() {
+		return "5" + t.Suit().String() + "r"
+	}
+	return t.String()
+}
+
+// FormatTiles sorts tiles by suit and rank, a red five before the plain
+// fives, and writes their mjai names separated by spaces: "1m 5pr 5p E".
+func FormatTiles(tiles []engine.Tile) (string, error) {
+	sorted := slices.Clone(tiles)
+	for _, t := range sorted {
+		if t.Index() < 0 {
+			return "", fmt.Errorf("mjai has no tile %s", t)
+		}
+	}
+	key := func(t engine.Tile) int {
+		if t.IsRed() {
+			return 2 * t.Index()
+		}
+		return 2*t.Index() + 1
+	}
+	slices.SortStableFunc(sorted, func(a, b engine.Tile) int { return cmp.Compare(key(a), key(b)) })
+	return strings.Join(formatTiles(sorted), " "), nil
+}
+
+func formatTiles(tiles []engine.Tile) []string {
+	out := make([]string, 0, len(tiles))
+	for _, t := range tiles {
+		out = append(out, formatTile(t))
+	}
+	return out
+}
+
+func parseTiles(names []string) ([]engine.Tile, error) {
+	out := make([]engine.Tile, 0, len(names))
+	for _, n := range names {
+		t, err := ParseTile(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package mjai
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestParseTile(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1m", "1m"},
+		{"9s", "9s"},
+		{"5mr", "0m"},
+		{"5pr", "0p"},
+		{"E", "1z"},
+		{"N", "4z"},
+		{"P", "5z"},
+		{"F", "6z"},
+		{"C", "7z"},
+	}
+	for _, tt := range tests {
+		got, err := ParseTile(tt.in)
+		if err != nil {
+			t.Errorf("ParseTile(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseTile(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if back := formatTile(got); back != tt.in {
+			t.Errorf("formatTile(%s) = %q, want %q", got, back, tt.in)
+		}
+	}
+}
+
+func TestParseTile_Invalid(t *testing.T) {
+	for _, in := range []string{"", "?", "0m", "5zr", "4mr", "1z", "10m", "X"} {
+		if _, err := ParseTile(in); err == nil {
+			t.Errorf("ParseTile(%q) expected error", in)
+		}
+	}
+}
+
+func TestFormatTile_IgnoresDoraFlags(t *testing.T) {
+	tile, _ := engine.ParseTile("3s")
+	if got := formatTile(tile.SetDora(true)); got != "3s" {
+		t.Errorf("formatTile = %q, want 3s", got)
+	}
+}
+
+func TestFormatTiles(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"11z789s604p321m", "1m 2m 3m 4p 5pr 6p 7s 8s 9s E E"},
+		{"5505m", "5mr 5m 5m 5m"},
+		{"7654321z", "E S W N P F C"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		tiles, err := engine.ParseHandCompact(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := FormatTiles(tiles)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatTiles(%s) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatTiles_Flower(t *testing.T) {
+	for _, s := range []string{"F", "J"} {
+		tile, err := engine.ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := FormatTiles([]engine.Tile{tile}); err == nil {
+			t.Errorf("FormatTiles(%s) = %q, want an error", s, got)
+		}
+	}
+}
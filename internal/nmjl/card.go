@@ -0,0 +1,245 @@
+package nmjl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Card is a yearly NMJL card: an ordered list of hand patterns grouped in
+// sections ("2468", "Consecutive Run", ...).
+//
+// Card files are plain text:
+//
+//	# comment
+//	[2468]
+//	222/a 4444/a 666/a 8888/a | 25 | X
+//	FF 2222/a 44/b 66/c 8888/a | 25 | C
+//
+// Every pattern line has three "|"-separated fields: the groups, the point
+// value and X (exposures allowed) or C (concealed only). A group is a run of
+// elements optionally followed by "/a", "/b" or "/c". Groups sharing a suit
+// variable use the same suit; different variables use different suits.
+//
+// Elements:
+//   - 1–9:     literal number in the group's suit
+//   - x, x+k:  "any like number"; x is shared by the whole line
+//   - D:       the dragon belonging to the group's suit
+//   - 0:       white dragon used as zero ("soap")
+//   - E S W N: winds
+//   - F:       flower
+type Card struct {
+	Lines []Line
+}
+
+// Line is a single hand pattern on the card.
+type Line struct {
+	Section   string
+	Pattern   string // groups as written in the card file
+	Groups    []Group
+	Points    int
+	Concealed bool
+}
+
+// Group is a pung/kong/pair/single or a mixed run like NEWS or 2025.
+type Group struct {
+	Elems   []Elem
+	SuitVar byte // 'a', 'b', 'c' or 0 when the group has no suit
+}
+
+// ElemKind tells how an element resolves to a tile.
+type ElemKind uint8
+
+const (
+	ElemNumber   ElemKind = iota // literal rank in the group's suit
+	ElemVariable                 // x + Offset in the group's suit
+	ElemDragon                   // dragon matching the group's suit
+	ElemSoap                     // white dragon as zero
+	ElemWind                     // Rank 1-4 = E, S, W, N
+	ElemFlower
+)
+
+// Elem is one tile position inside a group.
+type Elem struct {
+	Kind   ElemKind
+	Rank   int // literal rank for ElemNumber / ElemWind
+	Offset int // offset from x for ElemVariable
+}
+
+// tilesPerHand is the size of a complete American hand.
+const tilesPerHand = 14
+
+// LoadCard reads a card definition from a file.
+func LoadCard(path string) (*Card, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	card, err := ParseCard(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return card, nil
+}
+
+// ParseCard reads a card definition in the format described on Card.
+func ParseCard(r io.Reader) (*Card, error) {
+	card := &Card{}
+	section := ""
+
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		text := sc.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header %q", lineNo, text)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+
+		line, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		line.Section = section
+		card.Lines = append(card.Lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(card.Lines) == 0 {
+		return nil, fmt.Errorf("card has no hand patterns")
+	}
+	return card, nil
+}
+
+func parseLine(text string) (Line, error) {
+	fields := strings.Split(text, "|")
+	if len(fields) != 3 {
+		return Line{}, fmt.Errorf("expected \"groups | points | X|C\", got %q", text)
+	}
+
+	var line Line
+	line.Pattern = strings.Join(strings.Fields(fields[0]), " ")
+
+	points, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+	if err != nil || points <= 0 {
+		return Line{}, fmt.Errorf("invalid point value %q", strings.TrimSpace(fields[1]))
+	}
+	line.Points = points
+
+	switch strings.ToUpper(strings.TrimSpace(fields[2])) {
+	case "X":
+		line.Concealed = false
+	case "C":
+		line.Concealed = true
+	default:
+		return Line{}, fmt.Errorf("expected X or C, got %q", strings.TrimSpace(fields[2]))
+	}
+
+	total := 0
+	for _, tok := range strings.Fields(fields[0]) {
+		g, err := parseGroup(tok)
+		if err != nil {
+			return Line{}, err
+		}
+		line.Groups = append(line.Groups, g)
+		total += len(g.Elems)
+	}
+	if total != tilesPerHand {
+		return Line{}, fmt.Errorf("pattern %q has %d tiles, want %d", line.Pattern, total, tilesPerHand)
+	}
+
+	return line, nil
+}
+
+func parseGroup(tok string) (Group, error) {
+	var g Group
+	body := tok
+	if i := strings.IndexByte(tok, '/'); i >= 0 {
+		body = tok[:i]
+		v := tok[i+1:]
+		if len(v) != 1 || v[0] < 'a' || v[0] > 'c' {
+			return Group{}, fmt.Errorf("invalid suit variable in %q", tok)
+		}
+		g.SuitVar = v[0]
+	}
+	if body == "" {
+		return Group{}, fmt.Errorf("empty group %q", tok)
+	}
+
+	needsSuit := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		var e Elem
+		switch {
+		case c >= '1' && c <= '9':
+			e = Elem{Kind: ElemNumber, Rank: int(c - '0')}
+			needsSuit = true
+		case c == 'x':
+			e = Elem{Kind: ElemVariable}
+			if i+1 < len(body) && body[i+1] == '+' {
+				if i+2 >= len(body) || body[i+2] < '1' || body[i+2] > '8' {
+					return Group{}, fmt.Errorf("invalid offset in %q", tok)
+				}
+				e.Offset = int(body[i+2] - '0')
+				i += 2
+			}
+			needsSuit = true
+		case c == 'D':
+			e = Elem{Kind: ElemDragon}
+			needsSuit = true
+		case c == '0':
+			e = Elem{Kind: ElemSoap}
+		case c == 'E':
+			e = Elem{Kind: ElemWind, Rank: 1}
+		case c == 'S':
+			e = Elem{Kind: ElemWind, Rank: 2}
+		case c == 'W':
+			e = Elem{Kind: ElemWind, Rank: 3}
+		case c == 'N':
+			e = Elem{Kind: ElemWind, Rank: 4}
+		case c == 'F':
+			e = Elem{Kind: ElemFlower}
+		default:
+			return Group{}, fmt.Errorf("unexpected character %q in group %q", c, tok)
+		}
+		g.Elems = append(g.Elems, e)
+	}
+
+	if needsSuit && g.SuitVar == 0 {
+		return Group{}, fmt.Errorf("group %q needs a suit variable (/a, /b or /c)", tok)
+	}
+	return g, nil
+}
+
+// jokerable reports whether jokers may stand in for tiles of this group:
+// only pungs, kongs and larger groups of identical tiles qualify.
+func (g Group) jokerable() bool {
+	if len(g.Elems) < 3 {
+		return false
+	}
+	for _, e := range g.Elems[1:] {
+		if e != g.Elems[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package nmjl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadCard(t *testing.T) {
+	card, err := LoadCard("testdata/sample.card")
+	if err != nil {
+		t.Fatalf("LoadCard failed: %v", err)
+	}
+	if len(card.Lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d", len(card.Lines))
+	}
+
+	first := card.Lines[0]
+	if first.Section != "2468" || first.Points != 25 || first.Concealed {
+		t.Errorf("unexpected first line: %+v", first)
+	}
+	if len(first.Groups) != 4 || first.Groups[1].SuitVar != 'a' {
+		t.Errorf("unexpected groups: %+v", first.Groups)
+	}
+
+	like := card.Lines[2]
+	if !like.Concealed || like.Section != "Like Numbers" {
+		t.Errorf("unexpected like-numbers line: %+v", like)
+	}
+
+	run := card.Lines[3]
+	if got := run.Groups[4].Elems[0]; got.Kind != ElemVariable || got.Offset != 4 {
+		t.Errorf("expected x+4 element, got %+v", got)
+	}
+}
+
+func TestParseCard_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty card", "# nothing here\n"},
+		{"missing fields", "222/a 4444/a 666/a 8888/a | 25\n"},
+		{"bad points", "222/a 4444/a 666/a 8888/a | lots | X\n"},
+		{"bad exposure flag", "222/a 4444/a 666/a 8888/a | 25 | Y\n"},
+		{"wrong tile count", "222/a 4444/a 666/a | 25 | X\n"},
+		{"number without suit", "222 4444/a 666/a 8888/a | 25 | X\n"},
+		{"dragon without suit", "DDD 4444/a 666/a 8888/a | 25 | X\n"},
+		{"bad suit variable", "222/d 4444/a 666/a 8888/a | 25 | X\n"},
+		{"bad offset", "x+9x+9x+9/a 4444/a 666/a 8888/a | 25 | X\n"},
+		{"unknown element", "QQQ 4444/a 666/a 8888/a | 25 | X\n"},
+		{"unterminated section", "[2468\n222/a 4444/a 666/a 8888/a | 25 | X\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCard(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("expected error for %q, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestGroup_Jokerable(t *testing.T) {
+	tests := []struct {
+		tok  string
+		want bool
+	}{
+		{"FF", false},
+		{"222/a", true},
+		{"FFFF", true},
+		{"NEWS", false},
+		{"2025/a", false},
+		{"x+1x+1x+1/b", true},
+	}
+	for _, tt := range tests {
+		g, err := parseGroup(tt.tok)
+		if err != nil {
+			t.Fatalf("parseGroup(%q) failed: %v", tt.tok, err)
+		}
+		if got := g.jokerable(); got != tt.want {
+			t.Errorf("parseGroup(%q).jokerable() = %v, want %v", tt.tok, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package nmjl
+
+import (
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Match is how close a hand is to one line of the card.
+type Match struct {
+	Line       Line
+	Index      int           // position of the line on the card
+	Missing    int           // tiles still needed to complete the line
+	Needed     []engine.Tile // concrete tiles that would complete the line
+	JokersUsed int
+}
+
+// Complete reports whether the hand already matches the line.
+func (m Match) Complete() bool {
+	return m.Missing == 0
+}
+
+// Check compares a hand against every line of the card and returns one
+// Match per line, closest first. Ties are broken by higher point value and
+// then by card order.
+func (c *Card) Check(hand []engine.Tile) []Match {
+	counts, jokers := countHand(hand)
+
+	matches := make([]Match, 0, len(c.Lines))
+	for i, line := range c.Lines {
+		m := bestMatch(line, counts, jokers)
+		m.Index = i
+		matches = append(matches, m)
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		if a.Missing != b.Missing {
+			return a.Missing - b.Missing
+		}
+		return b.Line.Points - a.Line.Points
+	})
+	return matches
+}
+
+// Closest returns the n best matches for the hand.
+func (c *Card) Closest(hand []engine.Tile, n int) []Match {
+	matches := c.Check(hand)
+	if n < len(matches) {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// countHand normalizes the hand to plain tile kinds (red fives count as
+// fives, dora flags are dropped) and separates out the jokers.
+func countHand(hand []engine.Tile) (map[engine.Tile]int, int) {
+	counts := make(map[engine.Tile]int)
+	jokers := 0
+	for _, t := range hand {
+		t = t.SetDora(false).SetUra(false)
+		if t.IsJoker() {
+			jokers++
+			continue
+		}
+		if t.IsRed() {
+			t, _ = engine.NewTile(t.Suit(), 5)
+		}
+		counts[t]++
+	}
+	return counts, jokers
+}
+
+// assignment binds the line variables to concrete values.
+type assignment struct {
+	suits map[byte]engine.Suit
+	x     int
+}
+
+// requirement is what a line needs of a single tile kind once the
+// variables are bound.
+type requirement struct {
+	plain     int // positions that must be filled with natural tiles
+	jokerable int // positions that may be filled with jokers
+}
+
+func bestMatch(line Line, counts map[engine.Tile]int, jokers int) Match {
+	best := Match{Line: line, Missing: tilesPerHand + 1}
+
+	for _, a := range assignments(line) {
+		req, order := requirements(line, a)
+
+		var needed []engine.Tile
+		var jokerDeficit []engine.Tile
+		for _, t := range order {
+			r := req[t]
+			have := counts[t]
+			for i := have; i < r.plain; i++ {
+				needed = append(needed, t)
+			}
+			left := max(0, have-r.plain)
+			for i := left; i < r.jokerable; i++ {
+				jokerDeficit = append(jokerDeficit, t)
+			}
+		}
+		used := min(jokers, len(jokerDeficit))
+		needed = append(needed, jokerDeficit[used:]...)
+		missing := len(needed)
+
+		if missing < best.Missing || (missing == best.Missing && used < best.JokersUsed) {
+			best.Missing = missing
+			best.Needed = needed
+			best.JokersUsed = used
+		}
+	}
+	return best
+}
+
+// assignments enumerates every way to bind the suit variables (distinct
+// suits for distinct variables) and x (so that every x+k stays in 1–9).
+func assignments(line Line) []assignment {
+	var vars []byte
+	maxOffset := -1
+	for _, g := range line.Groups {
+		if g.SuitVar != 0 && !slices.Contains(vars, g.SuitVar) {
+			vars = append(vars, g.SuitVar)
+		}
+		for _, e := range g.Elems {
+			if e.Kind == ElemVariable {
+				maxOffset = max(maxOffset, e.Offset)
+			}
+		}
+	}
+
+	xs := []int{0}
+	if maxOffset >= 0 {
+		xs = xs[:0]
+		for x := 1; x+maxOffset <= 9; x++ {
+			xs = append(xs, x)
+		}
+	}
+
+	suits := []engine.Suit{engine.SuitManzu, engine.SuitPinzu, engine.SuitSouzu}
+	var out []assignment
+	var bind func(i int, cur map[byte]engine.Suit)
+	bind = func(i int, cur map[byte]engine.Suit) {
+		if i == len(vars) {
+			for _, x := range xs {
+				m := make(map[byte]engine.Suit, len(cur))
+				for k, v := range cur {
+					m[k] = v
+				}
+				out = append(out, assignment{suits: m, x: x})
+			}
+			return
+		}
+		for _, s := range suits {
+			taken := false
+			for _, used := range cur {
+				if used == s {
+					taken = true
+					break
+				}
+			}
+			if taken {
+				continue
+			}
+			cur[vars[i]] = s
+			bind(i+1, cur)
+			delete(cur, vars[i])
+		}
+	}
+	bind(0, make(map[byte]engine.Suit))
+	return out
+}
+
+// requirements resolves the line into per-kind needs. order lists the
+// kinds in first-seen order so the Needed slice is deterministic.
+func requirements(line Line, a assignment) (map[engine.Tile]requirement, []engine.Tile) {
+	req := make(map[engine.Tile]requirement)
+	var order []engine.Tile
+
+	for _, g := range line.Groups {
+		jokerable := g.jokerable()
+		for _, e := range g.Elems {
+			t := resolve(e, a.suits[g.SuitVar], a.x)
+			if _, seen := req[t]; !seen {
+				order = append(order, t)
+			}
+			r := req[t]
+			if jokerable {
+				r.jokerable++
+			} else {
+				r.plain++
+			}
+			req[t] = r
+		}
+	}
+	return req, order
+}
+
+// dragonForSuit pairs dragons with suits the way the NMJL card does:
+// red with craks, green with bams, white (soap) with dots.
+var dragonForSuit = map[engine.Suit]int{
+	engine.SuitManzu: 7,
+	engine.SuitSouzu: 6,
+	engine.SuitPinzu: 5,
+}
+
+// resolve turns an element into a tile kind. The card parser only produces
+// elements whose ranks are in range, so NewTile cannot fail here.
+func resolve(e Elem, suit engine.Suit, x int) engine.Tile {
+	var t engine.Tile
+	switch e.Kind {
+	case ElemNumber:
+		t, _ = engine.NewTile(suit, e.Rank)
+	case ElemVariable:
+		t, _ = engine.NewTile(suit, x+e.Offset)
+	case ElemDragon:
+		t, _ = engine.NewTile(engine.SuitHonor, dragonForSuit[suit])
+	case ElemSoap:
+		t, _ = engine.NewTile(engine.SuitHonor, 5)
+	case ElemWind:
+		t, _ = engine.NewTile(engine.SuitHonor, e.Rank)
+	case ElemFlower:
+		t = engine.NewFlower()
+	}
+	return t
+}
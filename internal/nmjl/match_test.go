@@ -0,0 +1,116 @@
+package nmjl
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// mustHand builds a hand from compact notation plus flowers and jokers.
+func mustHand(t *testing.T, compact string, flowers, jokers int) []engine.Tile {
+	t.Helper()
+	hand, err := engine.ParseHandCompact(compact)
+	if err != nil {
+		t.Fatalf("ParseHandCompact(%q) failed: %v", compact, err)
+	}
+	for i := 0; i < flowers; i++ {
+		hand = append(hand, engine.NewFlower())
+	}
+	for i := 0; i < jokers; i++ {
+		hand = append(hand, engine.NewJoker())
+	}
+	return hand
+}
+
+func mustCard(t *testing.T) *Card {
+	t.Helper()
+	card, err := LoadCard("testdata/sample.card")
+	if err != nil {
+		t.Fatalf("LoadCard failed: %v", err)
+	}
+	return card
+}
+
+func TestCheck_CompleteHands(t *testing.T) {
+	card := mustCard(t)
+
+	tests := []struct {
+		name      string
+		hand      []engine.Tile
+		wantIndex int
+		wantJoker int
+	}{
+		{"2468 single suit", mustHand(t, "22244446668888p", 0, 0), 0, 0},
+		{"2468 with jokers in pung and kong", mustHand(t, "2244466888s", 0, 4), 0, 4},
+		{"like numbers in three suits", mustHand(t, "7777m77p7777s77z", 2, 0), 2, 0},
+		{"consecutive run with red five", mustHand(t, "33444555066677m", 0, 0), 3, 0},
+		{"winds", mustHand(t, "22223334444z", 0, 3), 4, 3},
+		{"news 2025 with dragons", mustHand(t, "225m12345556666z", 0, 0), 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best := card.Check(tt.hand)[0]
+			if !best.Complete() {
+				t.Fatalf("expected a complete match, best is line %d missing %v", best.Index, best.Needed)
+			}
+			if best.Index != tt.wantIndex {
+				t.Errorf("matched line %d, want %d", best.Index, tt.wantIndex)
+			}
+			if best.JokersUsed != tt.wantJoker {
+				t.Errorf("JokersUsed = %d, want %d", best.JokersUsed, tt.wantJoker)
+			}
+		})
+	}
+}
+
+func TestCheck_JokersNotAllowedInPairs(t *testing.T) {
+	card := mustCard(t)
+
+	// Line 1 needs a natural 44p and 66s pair; jokers cannot fill pairs.
+	hand := mustHand(t, "22228888m44p6s", 2, 1)
+	var line1 Match
+	for _, m := range card.Check(hand) {
+		if m.Index == 1 {
+			line1 = m
+		}
+	}
+	if line1.Missing != 1 {
+		t.Fatalf("expected 1 missing tile, got %d (%v)", line1.Missing, line1.Needed)
+	}
+	want, _ := engine.ParseTile("6s")
+	if line1.Needed[0] != want {
+		t.Errorf("Needed = %v, want [%v]", line1.Needed, want)
+	}
+}
+
+func TestCheck_ClosestReportsNeededTiles(t *testing.T) {
+	card := mustCard(t)
+
+	// One 8p short of the 2468 single-suit line.
+	hand := mustHand(t, "2224444666888p3m", 0, 0)
+	best := card.Closest(hand, 1)
+	if len(best) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(best))
+	}
+	if best[0].Index != 0 || best[0].Missing != 1 {
+		t.Fatalf("expected line 0 missing 1, got line %d missing %d", best[0].Index, best[0].Missing)
+	}
+	want, _ := engine.ParseTile("8p")
+	if best[0].Needed[0] != want {
+		t.Errorf("Needed = %v, want [%v]", best[0].Needed, want)
+	}
+}
+
+func TestCheck_OrderedByMissing(t *testing.T) {
+	card := mustCard(t)
+	matches := card.Check(mustHand(t, "123m456p789s1234z", 0, 0))
+	if len(matches) != len(card.Lines) {
+		t.Fatalf("expected %d matches, got %d", len(card.Lines), len(matches))
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Missing < matches[i-1].Missing {
+			t.Errorf("matches not sorted: %d before %d", matches[i-1].Missing, matches[i].Missing)
+		}
+	}
+}
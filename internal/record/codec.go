@@ -0,0 +1,571 @@
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Every event starts with an op byte. Draws and discards by the seat
+// whose turn it is, the bulk of a game, are that byte alone:
+//
+//	bit 7 set | bit 6 discard | bits 5-0 tile
+//
+// The seat is implied by the turn order (see flow), the tile is the Tile
+// byte without its dora flags, and the tile code 63, which no tile uses,
+// is a tsumogiri discard of the tile just drawn. Every other event uses
+//
+//	bit 7 clear | bits 6-3 type | bits 2-1 seat | bit 0 flag
+//
+// where the flag is tsumogiri for discards, and is followed by the
+// event's payload: a tile byte for draws, discards and dora flips. Scores
+// in round starts and the game end are stored as differences from the
+// running scores, which are zero for consistent logs.
+const (
+	opTypeShift = 3
+	opSeatShift = 1
+	opFlag      = 1
+
+	opShort     = 1 << 7
+	opDiscard   = 1 << 6
+	opTileMask  = 1<<6 - 1
+	opTsumogiri = opTileMask
+)
+
+// flow follows the turn order so that draws and discards can leave out
+// the seat. The encoder and decoder update it with every event.
+type flow struct {
+	drawer int         // seat expected to draw next
+	actor  int         // seat expected to discard next
+	drawn  engine.Tile // the actor's draw, if drew is set
+	drew   bool
+}
+
+func (f *flow) update(e engine.Event) {
+	switch e.Type {
+	case engine.EventRoundStart:
+		*f = flow{drawer: e.Round.Dealer, actor: e.Round.Dealer}
+	case engine.EventDrawTile:
+		f.actor, f.drawn, f.drew = e.Seat, e.Tile, true
+	case engine.EventDiscard:
+		f.drawer, f.drew = (e.Seat+1)%4, false
+	case engine.EventCall:
+		// A kan's replacement draw and a chi or pon's discard are both
+		// the caller's.
+		f.drawer, f.actor, f.drew = e.Seat, e.Seat, false
+	}
+}
+
+// short returns the one-byte form of e, if it has one.
+func (f *flow) short(e engine.Event) (byte, bool) {
+	plain := byte(e.Tile) <= opTileMask && byte(e.Tile) != opTsumogiri
+	switch {
+	case e.Type == engine.EventDrawTile && e.Seat == f.drawer && plain:
+		return opShort | byte(e.Tile), true
+	case e.Type != engine.EventDiscard || e.Seat != f.actor:
+		return 0, false
+	case e.Tsumogiri && f.drew && e.Tile == f.drawn:
+		return opShort | opDiscard | opTsumogiri, true
+	case !e.Tsumogiri && plain:
+		return opShort | opDiscard | byte(e.Tile), true
+	}
+	return 0, false
+}
+
+var errTruncated = errors.New("record truncated")
+
+// encoder appends one game to a byte slice.
+type encoder struct {
+	buf    []byte
+	scores [4]int
+	flow   flow
+}
+
+func (enc *encoder) byte(b byte)        { enc.buf = append(enc.buf, b) }
+func (enc *encoder) uvarint(v int)      { enc.buf = binary.AppendUvarint(enc.buf, uint64(v)) }
+func (enc *encoder) varint(v int)       { enc.buf = binary.AppendVarint(enc.buf, int64(v)) }
+func (enc *encoder) tile(t engine.Tile) { enc.buf = append(enc.buf, byte(t)) }
+
+func (enc *encoder) bool(v bool) {
+	if v {
+		enc.byte(1)
+	} else {
+		enc.byte(0)
+	}
+}
+
+func (enc *encoder) string(s string) {
+	enc.uvarint(len(s))
+	enc.buf = append(enc.buf, s...)
+}
+
+func (enc *encoder) tiles(ts []engine.Tile) {
+	enc.uvarint(len(ts))
+	for _, t := range ts {
+		enc.tile(t)
+	}
+}
+
+func (enc *encoder) ints(vs [4]int) {
+	for _, v := range vs {
+		enc.varint(v)
+	}
+}
+
+// scoreDiffs writes scores relative to the running scores and makes them
+// the new running scores.
+func (enc *encoder) scoreDiffs(scores [4]int) {
+	for i, s := range scores {
+		enc.varint(s - enc.scores[i])
+	}
+	enc.scores = scores
+}
+
+func (enc *encoder) addScores(deltas [4]int) {
+	for i, d := range deltas {
+		enc.scores[i] += d
+	}
+}
+
+func (enc *encoder) event(e engine.Event) error {
+	if err := enc.payload(e); err != nil {
+		return err
+	}
+	enc.flow.update(e)
+	return nil
+}
+
+func (enc *encoder) payload(e engine.Event) error {
+	if op, ok := enc.flow.short(e); ok {
+		enc.byte(op)
+		return nil
+	}
+	if e.Type > engine.EventScoreChange {
+		return fmt.Errorf("unknown event type %d", e.Type)
+	}
+	if e.Seat < 0 || e.Seat > 3 {
+		return fmt.Errorf("%s: invalid seat %d", e.Type, e.Seat)
+	}
+	op := byte(e.Type)<<opTypeShift | byte(e.Seat)<<opSeatShift
+	if e.Type == engine.EventDiscard && e.Tsumogiri {
+		op |= opFlag
+	}
+	enc.byte(op)
+
+	switch e.Type {
+	case engine.EventDrawTile, engine.EventDiscard, engine.EventDora:
+		enc.tile(e.Tile)
+
+	case engine.EventCall:
+		if e.Meld == nil {
+			return fmt.Errorf("call without meld")
+		}
+		return enc.meld(*e.Meld)
+
+	case engine.EventRiichiAccepted:
+		enc.scores[e.Seat] -= 1000
+
+	case engine.EventGameStart:
+		if e.Game == nil {
+			return fmt.Errorf("game start without game")
+		}
+		for _, p := range e.Game.Players {
+			enc.string(p)
+		}
+		enc.rules(e.Game.Rules)
+
+	case engine.EventRoundStart:
+		rs := e.Round
+		if rs == nil {
+			return fmt.Errorf("round start without round")
+		}
+		enc.byte(byte(rs.Wind))
+		enc.uvarint(rs.Number)
+		enc.uvarint(rs.Honba)
+		enc.uvarint(rs.RiichiSticks)
+		enc.uvarint(rs.Dealer)
+		enc.scoreDiffs(rs.Scores)
+		enc.tile(rs.DoraIndicator)
+		for _, h := range rs.Hands {
+			enc.tiles(h)
+		}
+
+	case engine.EventWin:
+		if e.Win == nil {
+			return fmt.Errorf("win without result")
+		}
+		return enc.win(e.Win)
+
+	case engine.EventRyuukyoku:
+		r := e.Ryuukyoku
+		if r == nil {
+			return fmt.Errorf("ryuukyoku without result")
+		}
+		enc.byte(byte(r.Reason))
+		var tenpai byte
+		for i, t := range r.Tenpai {
+			if t {
+				tenpai |= 1 << i
+			}
+		}
+		enc.byte(tenpai)
+		enc.ints(r.Deltas)
+		enc.addScores(r.Deltas)
+
+	case engine.EventGameEnd:
+		if e.End == nil {
+			return fmt.Errorf("game end without scores")
+		}
+		enc.scoreDiffs(e.End.Scores)
+
+	case engine.EventScoreChange:
+		if e.Score == nil {
+			return fmt.Errorf("score change without deltas")
+		}
+		enc.ints(e.Score.Deltas)
+		enc.string(e.Score.Reason)
+		enc.addScores(e.Score.Deltas)
+	}
+	return nil
+}
+
+func (enc *encoder) meld(m engine.Meld) error {
+	if m.From < 0 || m.From > 3 {
+		return fmt.Errorf("meld from invalid seat %d", m.From)
+	}
+	enc.byte(byte(m.Kind)<<2 | byte(m.From))
+	enc.tile(m.Called)
+	enc.tiles(m.Tiles)
+	return nil
+}
+
+func (enc *encoder) win(w *engine.WinResult) error {
+	if w.Seat < 0 || w.Seat > 3 || w.From < 0 || w.From > 3 {
+		return fmt.Errorf("win with invalid seats %d/%d", w.Seat, w.From)
+	}
+	enc.byte(byte(w.Seat)<<2 | byte(w.From))
+	enc.tile(w.Tile)
+	enc.tiles(w.Hand)
+	enc.uvarint(len(w.Melds))
+	for _, m := range w.Melds {
+		if err := enc.meld(m); err != nil {
+			return err
+		}
+	}
+	enc.uvarint(w.Han)
+	enc.uvarint(w.Fu)
+	enc.uvarint(w.Points)
+	enc.uvarint(len(w.Yaku))
+	for _, y := range w.Yaku {
+		enc.byte(byte(y.Yaku))
+		enc.uvarint(y.Han)
+	}
+	enc.tiles(w.DoraIndicators)
+	enc.tiles(w.UraIndicators)
+	enc.ints(w.Deltas)
+	enc.addScores(w.Deltas)
+	return nil
+}
+
+// rules writes every Rules field in declaration order. Adding a field
+// to Rules needs a new format version.
+func (enc *encoder) rules(r engine.Rules) {
+	enc.uvarint(r.RedFivesMan)
+	enc.uvarint(r.RedFivesPin)
+	enc.uvarint(r.RedFivesSou)
+	enc.uvarint(r.StartingDora)
+	enc.bool(r.KanDora)
+	enc.bool(r.UraDora)
+	enc.bool(r.Ippatsu)
+	enc.bool(r.OpenTanyao)
+	enc.bool(r.Atozuke)
+	enc.uvarint(r.DoubleWindFu)
+	enc.bool(r.Kiriage)
+	enc.bool(r.KazoeYakuman)
+	enc.byte(byte(r.MultipleRon))
+	enc.byte(byte(r.Length))
+	enc.bool(r.AbortiveDraws)
+	enc.bool(r.Bust)
+	enc.bool(r.AgariYame)
+	enc.varint(r.StartingPoints)
+	enc.varint(r.ReturnPoints)
+	enc.ints(r.Uma)
+}
+
+// decoder reads one game. The first error sticks; later reads return
+// zero values.
+type decoder struct {
+	data   []byte
+	pos    int
+	err    error
+	scores [4]int
+	flow   flow
+}
+
+func (dec *decoder) fail(err error) {
+	if dec.err == nil {
+		dec.err = err
+	}
+}
+
+func (dec *decoder) byte() byte {
+	if dec.err != nil {
+		return 0
+	}
+	if dec.pos >= len(dec.data) {
+		dec.fail(errTruncated)
+		return 0
+	}
+	b := dec.data[dec.pos]
+	dec.pos++
+	return b
+}
+
+func (dec *decoder) uvarint() int {
+	if dec.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(dec.data[dec.pos:])
+	if n <= 0 || v > 1<<31 {
+		dec.fail(errTruncated)
+		return 0
+	}
+	dec.pos += n
+	return int(v)
+}
+
+func (dec *decoder) varint() int {
+	if dec.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(dec.data[dec.pos:])
+	if n <= 0 || v > 1<<31 || v < -1<<31 {
+		dec.fail(errTruncated)
+		return 0
+	}
+	dec.pos += n
+	return int(v)
+}
+
+func (dec *decoder) tile() engine.Tile { return engine.Tile(dec.byte()) }
+func (dec *decoder) bool() bool        { return dec.byte() != 0 }
+
+// count reads a length and checks that at least per bytes per element
+// are left, so corrupt lengths cannot trigger huge allocations.
+func (dec *decoder) count(per int) int {
+	n := dec.uvarint()
+	if dec.err == nil && n*per > len(dec.data)-dec.pos {
+		dec.fail(errTruncated)
+		return 0
+	}
+	return n
+}
+
+func (dec *decoder) string() string {
+	n := dec.count(1)
+	if dec.err != nil {
+		return ""
+	}
+	s := string(dec.data[dec.pos : dec.pos+n])
+	dec.pos += n
+	return s
+}
+
+func (dec *decoder) tiles() []engine.Tile {
+	n := dec.count(1)
+	if n == 0 {
+		return nil
+	}
+	ts := make([]engine.Tile, n)
+	for i := range ts {
+		ts[i] = dec.tile()
+	}
+	return ts
+}
+
+func (dec *decoder) ints() [4]int {
+	var vs [4]int
+	for i := range vs {
+		vs[i] = dec.varint()
+	}
+	return vs
+}
+
+func (dec *decoder) scoreDiffs() [4]int {
+	for i := range dec.scores {
+		dec.scores[i] += dec.varint()
+	}
+	return dec.scores
+}
+
+func (dec *decoder) addScores(deltas [4]int) {
+	for i, d := range deltas {
+		dec.scores[i] += d
+	}
+}
+
+func (dec *decoder) event() (engine.Event, error) {
+	e, err := dec.payload()
+	if err == nil {
+		dec.flow.update(e)
+	}
+	return e, err
+}
+
+func (dec *decoder) payload() (engine.Event, error) {
+	op := dec.byte()
+	if op&opShort != 0 {
+		return dec.shortEvent(op), dec.err
+	}
+	e := engine.Event{
+		Type: engine.EventType(op >> opTypeShift),
+		Seat: int(op>>opSeatShift) & 3,
+	}
+	if e.Type > engine.EventScoreChange {
+		return e, fmt.Errorf("unknown event type %d", e.Type)
+	}
+	if op&opFlag != 0 {
+		if e.Type != engine.EventDiscard {
+			return e, fmt.Errorf("%s with tsumogiri flag", e.Type)
+		}
+		e.Tsumogiri = true
+	}
+
+	switch e.Type {
+	case engine.EventDrawTile, engine.EventDiscard, engine.EventDora:
+		e.Tile = dec.tile()
+
+	case engine.EventCall:
+		m := dec.meld()
+		e.Meld = &m
+
+	case engine.EventRiichiAccepted:
+		dec.scores[e.Seat] -= 1000
+
+	case engine.EventGameStart:
+		g := &engine.GameStart{}
+		for i := range g.Players {
+			g.Players[i] = dec.string()
+		}
+		g.Rules = dec.rules()
+		e.Game = g
+
+	case engine.EventRoundStart:
+		rs := &engine.RoundStart{
+			Wind:         engine.Wind(dec.byte()),
+			Number:       dec.uvarint(),
+			Honba:        dec.uvarint(),
+			RiichiSticks: dec.uvarint(),
+			Dealer:       dec.uvarint(),
+		}
+		rs.Scores = dec.scoreDiffs()
+		rs.DoraIndicator = dec.tile()
+		for i := range rs.Hands {
+			rs.Hands[i] = dec.tiles()
+		}
+		e.Round = rs
+
+	case engine.EventWin:
+		e.Win = dec.win()
+		e.Tile = e.Win.Tile
+
+	case engine.EventRyuukyoku:
+		r := &engine.RyuukyokuResult{Reason: engine.RyuukyokuReason(dec.byte())}
+		tenpai := dec.byte()
+		for i := range r.Tenpai {
+			r.Tenpai[i] = tenpai&(1<<i) != 0
+		}
+		r.Deltas = dec.ints()
+		dec.addScores(r.Deltas)
+		e.Ryuukyoku = r
+
+	case engine.EventGameEnd:
+		e.End = &engine.GameEnd{Scores: dec.scoreDiffs()}
+
+	case engine.EventScoreChange:
+		sc := &engine.ScoreChange{Deltas: dec.ints(), Reason: dec.string()}
+		dec.addScores(sc.Deltas)
+		e.Score = sc
+	}
+	return e, dec.err
+}
+
+func (dec *decoder) shortEvent(op byte) engine.Event {
+	f := &dec.flow
+	if op&opDiscard == 0 {
+		return engine.Event{Type: engine.EventDrawTile, Seat: f.drawer, Tile: engine.Tile(op & opTileMask)}
+	}
+	e := engine.Event{Type: engine.EventDiscard, Seat: f.actor, Tile: engine.Tile(op & opTileMask)}
+	if op&opTileMask == opTsumogiri {
+		if !f.drew {
+			dec.fail(fmt.Errorf("tsumogiri without a draw"))
+		}
+		e.Tile, e.Tsumogiri = f.drawn, true
+	}
+	return e
+}
+
+func (dec *decoder) meld() engine.Meld {
+	b := dec.byte()
+	m := engine.Meld{Kind: engine.MeldKind(b >> 2), From: int(b & 3)}
+	if m.Kind > engine.MeldShouminkan {
+		dec.fail(fmt.Errorf("unknown meld kind %d", m.Kind))
+	}
+	m.Called = dec.tile()
+	m.Tiles = dec.tiles()
+	return m
+}
+
+func (dec *decoder) win() *engine.WinResult {
+	b := dec.byte()
+	w := &engine.WinResult{Seat: int(b>>2) & 3, From: int(b & 3)}
+	w.Tile = dec.tile()
+	w.Hand = dec.tiles()
+	if n := dec.count(3); n > 0 {
+		w.Melds = make([]engine.Meld, n)
+		for i := range w.Melds {
+			w.Melds[i] = dec.meld()
+		}
+	}
+	w.Han = dec.uvarint()
+	w.Fu = dec.uvarint()
+	w.Points = dec.uvarint()
+	if n := dec.count(2); n > 0 {
+		w.Yaku = make([]engine.YakuHan, n)
+		for i := range w.Yaku {
+			w.Yaku[i] = engine.YakuHan{Yaku: engine.Yaku(dec.byte()), Han: dec.uvarint()}
+		}
+	}
+	w.DoraIndicators = dec.tiles()
+	w.UraIndicators = dec.tiles()
+	w.Deltas = dec.ints()
+	dec.addScores(w.Deltas)
+	return w
+}
+
+func (dec *decoder) rules() engine.Rules {
+	return engine.Rules{
+		RedFivesMan:    dec.uvarint(),
+		RedFivesPin:    dec.uvarint(),
+		RedFivesSou:    dec.uvarint(),
+		StartingDora:   dec.uvarint(),
+		KanDora:        dec.bool(),
+		UraDora:        dec.bool(),
+		Ippatsu:        dec.bool(),
+		OpenTanyao:     dec.bool(),
+		Atozuke:        dec.bool(),
+		DoubleWindFu:   dec.uvarint(),
+		Kiriage:        dec.bool(),
+		KazoeYakuman:   dec.bool(),
+		MultipleRon:    engine.MultipleRon(dec.byte()),
+		Length:         engine.GameLength(dec.byte()),
+		AbortiveDraws:  dec.bool(),
+		Bust:           dec.bool(),
+		AgariYame:      dec.bool(),
+		StartingPoints: dec.varint(),
+		ReturnPoints:   dec.varint(),
+		Uma:            dec.ints(),
+	}
+}
@@ -0,0 +1,154 @@
+// Package record stores games in a compact binary format meant for large
+// numbers of simulated games.
+//
+// A file starts with the magic "MJRC" and a uvarint format version, then
+// holds any number of games. Each game is framed as
+//
+//	uvarint payload length | payload | CRC-32 (IEEE) of the payload, little endian
+//
+// and the payload is the game's events in order; see codec.go. Decoding a
+// game yields the same events as the canonical event log.
+package record
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Version is the format version written by NewWriter.
+const Version = 1
+
+const magic = "MJRC"
+
+// maxGameSize bounds a single game's payload so a corrupt length cannot
+// exhaust memory.
+const maxGameSize = 1 << 24
+
+// ErrChecksum is returned when a game's payload does not match its CRC.
+var ErrChecksum = errors.New("record checksum mismatch")
+
+// Writer streams games to an underlying writer. Call Flush when done.
+type Writer struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+// NewWriter writes the file header and returns a writer for games.
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	header := binary.AppendUvarint([]byte(magic), Version)
+	if _, err := bw.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// WriteGame appends one game.
+func (w *Writer) WriteGame(events []engine.Event) error {
+	enc := encoder{buf: w.buf[:0]}
+	for i, e := range events {
+		if err := enc.event(e); err != nil {
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+	w.buf = enc.buf
+	if len(enc.buf) > maxGameSize {
+		return fmt.Errorf("game of %d bytes exceeds the %d byte limit", len(enc.buf), maxGameSize)
+	}
+
+	frame := binary.AppendUvarint(nil, uint64(len(enc.buf)))
+	if _, err := w.w.Write(frame); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(enc.buf); err != nil {
+		return err
+	}
+	_, err := w.w.Write(binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(enc.buf)))
+	return err
+}
+
+// Flush writes buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Reader streams games from a record file.
+type Reader struct {
+	r       *bufio.Reader
+	version int
+	buf     []byte
+}
+
+// NewReader checks the file header. Files from another format version
+// are rejected.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, head); err != nil || string(head) != magic {
+		return nil, fmt.Errorf("not a game record file")
+	}
+	v, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading version: %w", err)
+	}
+	if v != Version {
+		return nil, fmt.Errorf("unsupported record version %d (want %d)", v, Version)
+	}
+	return &Reader{r: br, version: int(v)}, nil
+}
+
+// Version returns the format version of the file being read.
+func (r *Reader) Version() int {
+	return r.version
+}
+
+// ReadGame returns the next game's events, or io.EOF after the last game.
+func (r *Reader) ReadGame() ([]engine.Event, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading game length: %w", unexpected(err))
+	}
+	if n > maxGameSize {
+		return nil, fmt.Errorf("game length %d exceeds the %d byte limit", n, maxGameSize)
+	}
+
+	if cap(r.buf) < int(n)+4 {
+		r.buf = make([]byte, int(n)+4)
+	}
+	buf := r.buf[:n+4]
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, fmt.Errorf("reading game: %w", unexpected(err))
+	}
+	payload := buf[:n]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(buf[n:]) {
+		return nil, ErrChecksum
+	}
+
+	dec := decoder{data: payload}
+	var events []engine.Event
+	for dec.pos < len(payload) {
+		e, err := dec.event()
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", len(events), err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// unexpected turns a clean EOF in the middle of a game into an error.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
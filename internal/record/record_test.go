@@ -0,0 +1,264 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/majsoul"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/tenhou"
+)
+
+func sampleGames(t *testing.T) [][]engine.Event {
+	t.Helper()
+	canonical, err := engine.LoadEventLog("../engine/testdata/events.jsonl")
+	if err != nil {
+		t.Fatalf("LoadEventLog failed: %v", err)
+	}
+	tenhou6, err := tenhou.LoadJSON("../tenhou/testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	paipu, err := majsoul.LoadPaipu("../majsoul/testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadPaipu failed: %v", err)
+	}
+	return [][]engine.Event{canonical, tenhou6, paipu}
+}
+
+func TestRoundTrip(t *testing.T) {
+	games := sampleGames(t)
+	games[0] = append(games[0], engine.Event{Type: engine.EventScoreChange, Score: &engine.ScoreChange{Deltas: [4]int{-8000, 0, 0, 8000}, Reason: "chombo"}})
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, g := range games {
+		if err := w.WriteGame(g); err != nil {
+			t.Fatalf("WriteGame failed: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if r.Version() != Version {
+		t.Errorf("Version() = %d", r.Version())
+	}
+	for i, want := range games {
+		got, err := r.ReadGame()
+		if err != nil {
+			t.Fatalf("game %d: ReadGame failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(canonical(t, got), canonical(t, want)) {
+			t.Errorf("game %d changed:\n got  %v\n want %v", i, got, want)
+		}
+	}
+	if _, err := r.ReadGame(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last game, got %v", err)
+	}
+}
+
+// canonical passes events through the canonical event log so both sides
+// of a comparison use the same representation of empty lists.
+func canonical(t *testing.T, events []engine.Event) []engine.Event {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := engine.WriteEventLog(&buf, events); err != nil {
+		t.Fatalf("WriteEventLog failed: %v", err)
+	}
+	out, err := engine.ReadEventLog(&buf)
+	if err != nil {
+		t.Fatalf("ReadEventLog failed: %v", err)
+	}
+	return out
+}
+
+func TestCompact(t *testing.T) {
+	events := sampleGames(t)[0]
+
+	var bin, log bytes.Buffer
+	w, _ := NewWriter(&bin)
+	if err := w.WriteGame(events); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	if err := engine.WriteEventLog(&log, events); err != nil {
+		t.Fatal(err)
+	}
+	if bin.Len()*5 > log.Len() {
+		t.Errorf("binary record is %d bytes, event log %d", bin.Len(), log.Len())
+	}
+
+	// Draws and discards in turn order cost one byte each.
+	var enc encoder
+	tiles := 0
+	for _, e := range events {
+		n := len(enc.buf)
+		if err := enc.event(e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type == engine.EventDrawTile || e.Type == engine.EventDiscard {
+			tiles++
+			if len(enc.buf)-n != 1 {
+				t.Errorf("%s encoded in %d bytes", e, len(enc.buf)-n)
+			}
+		}
+	}
+	if tiles == 0 {
+		t.Fatal("no draws or discards in the sample")
+	}
+}
+
+func TestShortForm(t *testing.T) {
+	tile := func(s string) engine.Tile {
+		tt, err := engine.ParseTile(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tt
+	}
+	pon := &engine.Meld{Kind: engine.MeldPon, Tiles: []engine.Tile{tile("7z"), tile("7z"), tile("7z")}, Called: tile("7z"), From: 0}
+	tests := []struct {
+		e    engine.Event
+		size int // encoded bytes; 0 for not checked
+	}{
+		{engine.Event{Type: engine.EventRoundStart, Round: &engine.RoundStart{Dealer: 3}}, 0},
+		{engine.Event{Type: engine.EventDrawTile, Seat: 3, Tile: tile("0p")}, 1},
+		{engine.Event{Type: engine.EventDiscard, Seat: 3, Tile: tile("0p"), Tsumogiri: true}, 1},
+		{engine.Event{Type: engine.EventDrawTile, Seat: 0, Tile: tile("1m")}, 1},
+		{engine.Event{Type: engine.EventDiscard, Seat: 0, Tile: tile("7z")}, 1},
+		{engine.Event{Type: engine.EventCall, Seat: 2, Meld: pon}, 0},
+		{engine.Event{Type: engine.EventDiscard, Seat: 2, Tile: tile("9s")}, 1},
+		{engine.Event{Type: engine.EventDrawTile, Seat: 3, Tile: tile("5s").SetDora(true)}, 2},   // flagged tile
+		{engine.Event{Type: engine.EventDiscard, Seat: 3, Tile: tile("1m"), Tsumogiri: true}, 2}, // not the drawn tile
+		{engine.Event{Type: engine.EventDrawTile, Seat: 1, Tile: tile("2m")}, 2},                 // out of turn
+		{engine.Event{Type: engine.EventDiscard, Seat: 1, Tile: tile("2m")}, 1},
+	}
+	var enc encoder
+	var events []engine.Event
+	for _, tt := range tests {
+		n := len(enc.buf)
+		if err := enc.event(tt.e); err != nil {
+			t.Fatal(err)
+		}
+		if tt.size > 0 && len(enc.buf)-n != tt.size {
+			t.Errorf("%s encoded in %d bytes, want %d", tt.e, len(enc.buf)-n, tt.size)
+		}
+		events = append(events, tt.e)
+	}
+
+	dec := decoder{data: enc.buf}
+	for i, want := range events {
+		got, err := dec.event()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if got.Type != want.Type || got.Seat != want.Seat || got.Tile != want.Tile || got.Tsumogiri != want.Tsumogiri {
+			t.Errorf("event %d: %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func encodeGame(t *testing.T, events []engine.Event) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteGame(events); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func TestReader_Errors(t *testing.T) {
+	data := encodeGame(t, sampleGames(t)[0])
+
+	corrupt := bytes.Clone(data)
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := readAll(corrupt); !errors.Is(err, ErrChecksum) {
+		t.Errorf("corrupt payload: got %v, want ErrChecksum", err)
+	}
+
+	if _, err := readAll(data[:len(data)-3]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated file: got %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	future := bytes.Clone(data)
+	future[len(magic)] = Version + 1
+	if _, err := NewReader(bytes.NewReader(future)); err == nil || !strings.Contains(err.Error(), "unsupported record version") {
+		t.Errorf("future version: got %v", err)
+	}
+
+	if _, err := NewReader(strings.NewReader("MJAI\x01")); err == nil {
+		t.Error("bad magic: expected error")
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf)
+	tests := []engine.Event{
+		{Type: engine.EventDiscard, Seat: 4},
+		{Type: engine.EventType(200)},
+		{Type: engine.EventCall, Seat: 1},
+		{Type: engine.EventWin, Seat: 1},
+		{Type: engine.EventCall, Seat: 1, Meld: &engine.Meld{Kind: engine.MeldPon, From: -1}},
+	}
+	for _, e := range tests {
+		if err := w.WriteGame([]engine.Event{e}); err == nil {
+			t.Errorf("%+v: expected error", e)
+		}
+	}
+}
+
+func readAll(data []byte) ([][]engine.Event, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var games [][]engine.Event
+	for {
+		g, err := r.ReadGame()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+}
+
+func TestReader_TruncatedPayload(t *testing.T) {
+	var enc encoder
+	for _, e := range sampleGames(t)[0] {
+		if err := enc.event(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Cut the payload short but give it a valid frame and checksum: the
+	// decoder must fail cleanly instead of panicking.
+	for n := 1; n < len(enc.buf); n += 7 {
+		dec := decoder{data: enc.buf[:n]}
+		for dec.pos < n {
+			if _, err := dec.event(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package render draws tiles and hands for terminals and images.
+package render
+
+import (
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Code points in the Unicode Mahjong Tiles block (U+1F000–U+1F02B).
+const (
+	glyphEast   = '\U0001F000' // winds: E S W N
+	glyphChun   = '\U0001F004' // dragons in Unicode order: red, green, white
+	glyphHatsu  = '\U0001F005'
+	glyphHaku   = '\U0001F006'
+	glyphMan1   = '\U0001F007'
+	glyphSou1   = '\U0001F010'
+	glyphPin1   = '\U0001F019'
+	glyphFlower = '\U0001F022' // plum, the first flower
+	glyphJoker  = '\U0001F02A'
+
+	// Back is the glyph of a face-down tile.
+	Back = "\U0001F02B"
+)
+
+// ANSI escapes for highlighting red fives.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+// Glyph returns the Unicode glyph for a tile. Red fives use the plain five
+// glyph; see Options.Color. Unknown tiles render as "?".
+func Glyph(t engine.Tile) string {
+	if t.IsFlower() {
+		return string(glyphFlower)
+	}
+	if t.IsJoker() {
+		return string(glyphJoker)
+	}
+
+	rank := t.Rank()
+	if t.IsRed() {
+		rank = 5
+	}
+	switch t.Suit() {
+	case engine.SuitManzu:
+		return string(glyphMan1 + rune(rank-1))
+	case engine.SuitSouzu:
+		return string(glyphSou1 + rune(rank-1))
+	case engine.SuitPinzu:
+		return string(glyphPin1 + rune(rank-1))
+	}
+
+	switch {
+	case t.IsWind():
+		return string(glyphEast + rune(rank-1))
+	case rank == 5:
+		return string(glyphHaku)
+	case rank == 6:
+		return string(glyphHatsu)
+	case rank == 7:
+		return string(glyphChun)
+	}
+	return "?"
+}
+
+// Options control terminal output.
+type Options struct {
+	// Wide puts a space after every glyph. Many terminals draw mahjong
+	// glyphs two cells wide but advance the cursor by one, so glyphs
+	// overlap without it.
+	Wide bool
+
+	// Color highlights red fives with ANSI red.
+	Color bool
+}
+
+// Tile renders one tile.
+func (o Options) Tile(t engine.Tile) string {
+	s := Glyph(t)
+	if o.Color && t.IsRed() {
+		s = ansiRed + s + ansiReset
+	}
+	if o.Wide {
+		s += " "
+	}
+	return s
+}
+
+// Tiles renders tiles in the given order.
+func (o Options) Tiles(tiles []engine.Tile) string {
+	var b strings.Builder
+	for _, t := range tiles {
+		b.WriteString(o.Tile(t))
+	}
+	return b.String()
+}
+
+// Meld renders a meld in brackets. The two outer tiles of a closed kan
+// are shown face down, as on the table.
+func (o Options) Meld(m engine.Meld) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, t := range m.Tiles {
+		if faceDown(m, i) {
+			b.WriteString(Back)
+			if o.Wide {
+				b.WriteString(" ")
+			}
+			continue
+		}
+		b.WriteString(o.Tile(t))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// faceDown reports whether tile i of a meld is shown face down: the two
+// outer tiles of a closed kan.
+func faceDown(m engine.Meld, i int) bool {
+	return m.Kind == engine.MeldAnkan && (i == 0 || i == len(m.Tiles)-1)
+}
+
+// Hand renders concealed tiles followed by the melds.
+func (o Options) Hand(tiles []engine.Tile, melds []engine.Meld) string {
+	var b strings.Builder
+	b.WriteString(o.Tiles(tiles))
+	for _, m := range melds {
+		b.WriteString(" ")
+		b.WriteString(o.Meld(m))
+	}
+	return b.String()
+}
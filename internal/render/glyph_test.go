@@ -0,0 +1,91 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func mustHand(t *testing.T, s string) []engine.Tile {
+	t.Helper()
+	tiles, err := engine.ParseHandCompact(s)
+	if err != nil {
+		t.Fatalf("ParseHandCompact(%q): %v", s, err)
+	}
+	return tiles
+}
+
+func TestGlyph(t *testing.T) {
+	tests := []struct {
+		hand string
+		want string
+	}{
+		{"1m", "🀇"},
+		{"9m", "🀏"},
+		{"1p", "🀙"},
+		{"9p", "🀡"},
+		{"1s", "🀐"},
+		{"9s", "🀘"},
+		{"0m", "🀋"},
+		{"1234z", "🀀🀁🀂🀃"},
+		{"567z", "🀆🀅🀄"},
+	}
+	for _, tt := range tests {
+		got := Options{}.Tiles(mustHand(t, tt.hand))
+		if got != tt.want {
+			t.Errorf("Tiles(%s) = %q, want %q", tt.hand, got, tt.want)
+		}
+	}
+
+	if got := Glyph(engine.NewFlower()); got != "🀢" {
+		t.Errorf("flower glyph = %q", got)
+	}
+	if got := Glyph(engine.NewJoker()); got != "🀪" {
+		t.Errorf("joker glyph = %q", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	hand := mustHand(t, "405m")
+
+	if got := (Options{Wide: true}).Tiles(hand); got != "🀊 🀋 🀋 " {
+		t.Errorf("wide = %q", got)
+	}
+	want := "🀊\x1b[31m🀋\x1b[0m🀋"
+	if got := (Options{Color: true}).Tiles(hand); got != want {
+		t.Errorf("color = %q, want %q", got, want)
+	}
+}
+
+func TestHand(t *testing.T) {
+	tiles := mustHand(t, "123m")
+	five := mustHand(t, "5555p")
+	melds := []engine.Meld{
+		{Kind: engine.MeldAnkan, Tiles: five, Called: five[3]},
+		{Kind: engine.MeldPon, Tiles: mustHand(t, "777z"), From: 1},
+	}
+	got := Options{}.Hand(tiles, melds)
+	want := "🀇🀈🀉 [🀫🀝🀝🀫] [🀄🀄🀄]"
+	if got != want {
+		t.Errorf("Hand = %q, want %q", got, want)
+	}
+}
+
+func TestMeld(t *testing.T) {
+	kan := mustHand(t, "5505p")
+	tests := []struct {
+		name string
+		o    Options
+		m    engine.Meld
+		want string
+	}{
+		{"ankan", Options{}, engine.Meld{Kind: engine.MeldAnkan, Tiles: kan, Called: kan[3]}, "[🀫🀝🀝🀫]"},
+		{"ankan wide", Options{Wide: true}, engine.Meld{Kind: engine.MeldAnkan, Tiles: kan, Called: kan[3]}, "[🀫 🀝 🀝 🀫 ]"},
+		{"daiminkan", Options{}, engine.Meld{Kind: engine.MeldKan, Tiles: kan, Called: kan[0], From: 2}, "[🀝🀝🀝🀝]"},
+	}
+	for _, tt := range tests {
+		if got := tt.o.Meld(tt.m); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// DrawImage rasterizes the scene. It needs no system fonts: faces use the
+// built-in bitmap font with ASCII labels.
+func DrawImage(s Scene) *image.RGBA {
+	l := layoutScene(s)
+	img := image.NewRGBA(image.Rect(0, 0, l.w, l.h))
+	fill(img, img.Bounds(), colorTable)
+	for _, lb := range l.labels {
+		text(img, lb.text, lb.x, lb.y, colorLabel)
+	}
+	for _, p := range l.tiles {
+		drawTile(img, p)
+	}
+	return img
+}
+
+// WritePNG draws the scene as a PNG image.
+func WritePNG(w io.Writer, s Scene) error {
+	return png.Encode(w, DrawImage(s))
+}
+
+func drawTile(img *image.RGBA, p placed) {
+	width, height := p.size()
+	shade := func(c color.RGBA) color.RGBA {
+		if p.dim {
+			return blend(c, colorTable, dimOpacity)
+		}
+		return c
+	}
+	r := image.Rect(p.x, p.y, p.x+width, p.y+height)
+	fill(img, r, shade(colorBorder))
+	inner := colorFace
+	if p.faceDown {
+		inner = colorBack
+	}
+	fill(img, r.Inset(1), shade(inner))
+	if p.faceDown {
+		return
+	}
+
+	top, bottom := face(p.tile, false)
+	c := shade(ink(p.tile))
+	cx, cy := p.x+width/2, p.y+height/2
+	if bottom == "" || p.sideways {
+		top += bottom
+		text(img, top, cx-textWidth(top)/2, cy+4, c)
+		return
+	}
+	text(img, top, cx-textWidth(top)/2, cy-2, c)
+	text(img, bottom, cx-textWidth(bottom)/2, cy+11, c)
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// blend mixes c over bg with the given opacity.
+func blend(c, bg color.RGBA, a float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x)*a + float64(y)*(1-a)) }
+	return color.RGBA{mix(c.R, bg.R), mix(c.G, bg.G), mix(c.B, bg.B), 0xff}
+}
+
+// text draws s with its baseline starting at (x, y).
+func text(img *image.RGBA, s string, x, y int, c color.RGBA) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Round()
+}
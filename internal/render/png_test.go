@@ -0,0 +1,47 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	s := puzzleScene(t)
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := layoutScene(s)
+	if b := img.Bounds(); b.Dx() != l.w || b.Dy() != l.h {
+		t.Fatalf("image is %v, want %dx%d", b, l.w, l.h)
+	}
+	at := func(x, y int) [3]uint32 {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return [3]uint32{r >> 8, g >> 8, b >> 8}
+	}
+	rgb := func(c color.RGBA) [3]uint32 { return [3]uint32{uint32(c.R), uint32(c.G), uint32(c.B)} }
+
+	if got := at(0, 0); got != rgb(colorTable) {
+		t.Errorf("corner = %v, want the table color", got)
+	}
+	for _, p := range l.tiles {
+		want := colorFace
+		switch {
+		case p.faceDown:
+			want = colorBack
+		case p.dim:
+			want = blend(colorFace, colorTable, dimOpacity)
+		}
+		// Just inside the border, clear of the face text.
+		if got := at(p.x+2, p.y+2); got != rgb(want) {
+			t.Errorf("tile %v at (%d,%d) = %v, want %v", p.tile, p.x, p.y, got, want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package render
+
+import (
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Scene is what an image shows: one player's hand and melds, their river
+// and the dora indicators. Empty parts are left out.
+type Scene struct {
+	Seat  int // owner of the melds; decides which called tile turns sideways
+	Hand  []engine.Tile
+	Drawn *engine.Tile // shown apart from the hand when set
+	Melds []engine.Meld
+	River []engine.Discard
+	Dora  []engine.Tile
+}
+
+// SceneFromRound takes the scene for seat from a replayed round. The last
+// tile of a fourteen-tile hand is shown as the drawn tile.
+func SceneFromRound(r *engine.Round, seat int) Scene {
+	s := Scene{
+		Seat:  seat,
+		Hand:  slices.Clone(r.Hands[seat]),
+		Melds: r.Melds[seat],
+		River: r.Discards[seat],
+		Dora:  r.DoraIndicators,
+	}
+	if len(s.Hand)%3 == 2 {
+		drawn := s.Hand[len(s.Hand)-1]
+		s.Hand = s.Hand[:len(s.Hand)-1]
+		s.Drawn = &drawn
+	}
+	return s
+}
+
+// Tile and layout sizes in pixels.
+const (
+	tileW     = 30
+	tileH     = 40
+	tileGap   = 2
+	groupGap  = 12
+	margin    = 10
+	riverRow  = 6
+	labelSize = 14
+)
+
+// placed is a tile at its position. A sideways tile occupies tileH×tileW.
+type placed struct {
+	tile     engine.Tile
+	x, y     int
+	sideways bool
+	faceDown bool
+	dim      bool // discarded tile that was called away
+}
+
+func (p placed) size() (w, h int) {
+	if p.sideways {
+		return tileH, tileW
+	}
+	return tileW, tileH
+}
+
+// label is a text caption at its baseline.
+type label struct {
+	text string
+	x, y int
+}
+
+type layout struct {
+	tiles  []placed
+	labels []label
+	w, h   int
+}
+
+// sidewaysIndex returns which tile of an open meld is turned to show where
+// it came from: the left one from the player on the left, the middle from
+// across and the right one from the right. It is -1 for a closed kan.
+func sidewaysIndex(m engine.Meld, seat int) int {
+	if !m.IsOpen() {
+		return -1
+	}
+	n := len(m.Tiles)
+	if m.Kind == engine.MeldShouminkan {
+		n = 3 // the added tile lies on top of the turned one
+	}
+	switch (m.From - seat + 4) % 4 {
+	case 3:
+		return 0
+	case 2:
+		return 1
+	default:
+		return n - 1
+	}
+}
+
+// meldTiles returns the meld's tiles in display order with the called
+// tile moved to its sideways slot. For a shouminkan the added tile comes
+// last.
+func meldTiles(m engine.Meld, seat int) []engine.Tile {
+	if !m.IsOpen() || len(m.Tiles) == 0 {
+		return m.Tiles
+	}
+	rest := slices.Clone(m.Tiles)
+	i := max(slices.Index(rest, m.Called), 0)
+	called := rest[i]
+	rest = slices.Delete(rest, i, i+1)
+	if m.Kind == engine.MeldShouminkan {
+		// The pon's tiles are alike, so whichever sits in the sideways
+		// slot stands in for the one it called.
+		return append(rest, called)
+	}
+	return slices.Insert(rest, sidewaysIndex(m, seat), called)
+}
+
+func (l *layout) add(p placed) int {
+	l.tiles = append(l.tiles, p)
+	w, h := p.size()
+	l.w = max(l.w, p.x+w+margin)
+	l.h = max(l.h, p.y+h+margin)
+	return p.x + w
+}
+
+// layoutScene places the dora indicators on top, the river below them and
+// the hand with its melds at the bottom.
+func layoutScene(s Scene) layout {
+	var l layout
+	y := margin
+
+	if len(s.Dora) > 0 {
+		y += labelSize
+		l.labels = append(l.labels, label{text: "Dora", x: margin, y: y - 3})
+		x := margin
+		for _, t := range s.Dora {
+			x = l.add(placed{tile: t, x: x, y: y}) + tileGap
+		}
+		y += tileH + groupGap
+	}
+
+	if len(s.River) > 0 {
+		rowH := tileH
+		for i := 0; i < len(s.River); i += riverRow {
+			x := margin
+			for _, d := range s.River[i:min(i+riverRow, len(s.River))] {
+				p := placed{tile: d.Tile, x: x, y: y, sideways: d.Riichi, dim: d.Called}
+				if p.sideways {
+					// Sideways tiles sit on the row's bottom edge.
+					p.y += tileH - tileW
+				}
+				x = l.add(p) + tileGap
+			}
+			y += rowH + tileGap
+		}
+		y += groupGap - tileGap
+	}
+
+	if slices.ContainsFunc(s.Melds, func(m engine.Meld) bool { return m.Kind == engine.MeldShouminkan }) {
+		y += 2*tileW - tileH // room for an added kan tile on top
+	}
+	x := margin
+	for _, t := range s.Hand {
+		x = l.add(placed{tile: t, x: x, y: y}) + tileGap
+	}
+	if s.Drawn != nil {
+		x = l.add(placed{tile: *s.Drawn, x: x + groupGap - tileGap, y: y}) + tileGap
+	}
+	for _, m := range s.Melds {
+		x += groupGap - tileGap
+		side := sidewaysIndex(m, s.Seat)
+		tiles := meldTiles(m, s.Seat)
+		for i, t := range tiles {
+			p := placed{tile: t, x: x, y: y, faceDown: faceDown(m, i)}
+			switch {
+			case i == side:
+				p.sideways = true
+				p.y += tileH - tileW
+			case m.Kind == engine.MeldShouminkan && i == len(tiles)-1:
+				// Stack the added tile on the sideways one.
+				prev := l.tiles[len(l.tiles)-(len(tiles)-1-side)]
+				p.x, p.y, p.sideways = prev.x, prev.y-tileW, true
+				l.add(p)
+				continue
+			}
+			x = l.add(p) + tileGap
+		}
+	}
+	if l.w == 0 {
+		l.w, l.h = 2*margin, 2*margin
+	}
+	return l
+}
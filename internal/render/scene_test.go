@@ -0,0 +1,142 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestSidewaysIndex(t *testing.T) {
+	tests := []struct {
+		kind engine.MeldKind
+		from int
+		want int
+	}{
+		{engine.MeldChi, 0, 0},
+		{engine.MeldPon, 0, 0},
+		{engine.MeldPon, 3, 1},
+		{engine.MeldPon, 2, 2},
+		{engine.MeldKan, 3, 1},
+		{engine.MeldKan, 2, 3},
+		{engine.MeldShouminkan, 2, 2},
+		{engine.MeldAnkan, 1, -1},
+	}
+	for _, tt := range tests {
+		m := engine.Meld{Kind: tt.kind, From: tt.from, Tiles: make([]engine.Tile, 3)}
+		if tt.kind == engine.MeldKan || tt.kind == engine.MeldShouminkan || tt.kind == engine.MeldAnkan {
+			m.Tiles = make([]engine.Tile, 4)
+		}
+		if got := sidewaysIndex(m, 1); got != tt.want {
+			t.Errorf("%v from %d: sideways %d, want %d", tt.kind, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestMeldTiles(t *testing.T) {
+	chi := mustHand(t, "345m")
+	pon := mustHand(t, "505p")
+	tests := []struct {
+		m    engine.Meld
+		seat int
+		want string
+	}{
+		{engine.Meld{Kind: engine.MeldChi, Tiles: chi, Called: chi[1], From: 0}, 1, "4m3m5m"},
+		{engine.Meld{Kind: engine.MeldPon, Tiles: pon, Called: pon[1], From: 3}, 1, "5p0p5p"},
+		{engine.Meld{Kind: engine.MeldPon, Tiles: pon, Called: pon[1], From: 2}, 1, "5p5p0p"},
+		{engine.Meld{Kind: engine.MeldPon, Tiles: pon, Called: pon[1], From: 0}, 1, "0p5p5p"},
+	}
+	for _, tt := range tests {
+		var got string
+		for _, tile := range meldTiles(tt.m, tt.seat) {
+			got += tile.String()
+		}
+		if got != tt.want {
+			t.Errorf("meldTiles(%v) = %s, want %s", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutScene(t *testing.T) {
+	pon := mustHand(t, "777z")
+	kan := mustHand(t, "1111s")
+	drawn := mustHand(t, "9p")[0]
+	s := Scene{
+		Seat:  0,
+		Hand:  mustHand(t, "123m456p"),
+		Drawn: &drawn,
+		Melds: []engine.Meld{
+			{Kind: engine.MeldPon, Tiles: pon, Called: pon[0], From: 2},
+			{Kind: engine.MeldAnkan, Tiles: kan, Called: kan[3], From: 0},
+		},
+		River: []engine.Discard{
+			{Tile: mustHand(t, "1z")[0]},
+			{Tile: mustHand(t, "9m")[0], Called: true},
+			{Tile: mustHand(t, "2p")[0], Riichi: true},
+		},
+		Dora: mustHand(t, "3s"),
+	}
+	l := layoutScene(s)
+	if got, want := len(l.tiles), 1+3+6+1+3+4; got != want {
+		t.Fatalf("placed %d tiles, want %d", got, want)
+	}
+
+	river := l.tiles[1:4]
+	if river[0].sideways || !river[1].dim || !river[2].sideways {
+		t.Errorf("river = %+v", river)
+	}
+	pons := l.tiles[11:14]
+	if pons[0].sideways || !pons[1].sideways || pons[2].sideways {
+		t.Errorf("pon from across = %+v", pons)
+	}
+	kans := l.tiles[14:]
+	if !kans[0].faceDown || kans[1].faceDown || kans[2].faceDown || !kans[3].faceDown {
+		t.Errorf("ankan = %+v", kans)
+	}
+	for _, p := range l.tiles {
+		w, h := p.size()
+		if p.x < 0 || p.y < 0 || p.x+w > l.w || p.y+h > l.h {
+			t.Errorf("tile %+v outside %dx%d", p, l.w, l.h)
+		}
+	}
+}
+
+func TestLayoutShouminkan(t *testing.T) {
+	kan := mustHand(t, "2222z")
+	s := Scene{
+		Hand:  mustHand(t, "1m"),
+		Melds: []engine.Meld{{Kind: engine.MeldShouminkan, Tiles: kan, Called: kan[3], From: 1}},
+	}
+	l := layoutScene(s)
+	if len(l.tiles) != 5 {
+		t.Fatalf("placed %d tiles", len(l.tiles))
+	}
+	turned, added := l.tiles[3], l.tiles[4]
+	if !turned.sideways || !added.sideways || added.x != turned.x || added.y != turned.y-tileW {
+		t.Errorf("turned %+v, added %+v", turned, added)
+	}
+	if added.y < margin {
+		t.Errorf("added tile at y=%d, above the margin", added.y)
+	}
+}
+
+func TestSceneFromRound(t *testing.T) {
+	events, err := engine.LoadEventLog("../engine/testdata/events.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range events {
+		g, err := engine.Replay(events, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Round == nil {
+			continue
+		}
+		for seat := range 4 {
+			s := SceneFromRound(g.Round, seat)
+			if len(s.Hand)%3 != 1 {
+				t.Fatalf("event %d seat %d: hand of %d", n, seat, len(s.Hand))
+			}
+		}
+	}
+}
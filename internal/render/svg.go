@@ -0,0 +1,140 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Colors shared by the SVG and PNG output.
+var (
+	colorTable  = color.RGBA{0x2e, 0x6b, 0x4f, 0xff}
+	colorFace   = color.RGBA{0xfb, 0xf7, 0xec, 0xff}
+	colorBorder = color.RGBA{0x80, 0x80, 0x80, 0xff}
+	colorBack   = color.RGBA{0xd9, 0x82, 0x2b, 0xff}
+	colorLabel  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// dimOpacity is how strongly a called-away river tile is drawn.
+const dimOpacity = 0.45
+
+// ink is the color of a tile's face text.
+func ink(t engine.Tile) color.RGBA {
+	switch {
+	case t.IsRed():
+		return color.RGBA{0xd0, 0x10, 0x10, 0xff}
+	case t.Suit() == engine.SuitPinzu:
+		return color.RGBA{0x1a, 0x4c, 0xa8, 0xff}
+	case t.Suit() == engine.SuitSouzu:
+		return color.RGBA{0x1d, 0x7a, 0x32, 0xff}
+	case t.IsHonor() && t.Rank() == 5:
+		return color.RGBA{0x1a, 0x4c, 0xa8, 0xff}
+	case t.IsHonor() && t.Rank() == 6:
+		return color.RGBA{0x1d, 0x7a, 0x32, 0xff}
+	case t.IsHonor() && t.Rank() == 7:
+		return color.RGBA{0xd0, 0x10, 0x10, 0xff}
+	}
+	return color.RGBA{0x20, 0x20, 0x20, 0xff}
+}
+
+var (
+	honorKanji = [...]string{"東", "南", "西", "北", "白", "發", "中"}
+	honorASCII = [...]string{"E", "S", "W", "N", "P", "F", "C"}
+)
+
+// face returns the text printed on a tile: the rank over the suit for
+// suited tiles and a short symbol for the rest. With kanji false it
+// sticks to ASCII, for fonts without CJK glyphs.
+func face(t engine.Tile, kanji bool) (top, bottom string) {
+	switch {
+	case t.IsFlower():
+		if kanji {
+			return "花", ""
+		}
+		return "Fl", ""
+	case t.IsJoker():
+		return "J", ""
+	case t.IsHonor():
+		if t.Rank() < 1 || int(t.Rank()) > len(honorKanji) {
+			return "?", ""
+		}
+		if kanji {
+			return honorKanji[t.Rank()-1], ""
+		}
+		return honorASCII[t.Rank()-1], ""
+	}
+	rank := t.Rank()
+	if t.IsRed() {
+		rank = 5
+	}
+	suit := t.String()[1:]
+	if kanji {
+		switch t.Suit() {
+		case engine.SuitManzu:
+			suit = "萬"
+		case engine.SuitPinzu:
+			suit = "筒"
+		case engine.SuitSouzu:
+			suit = "索"
+		}
+	}
+	return fmt.Sprint(rank), suit
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// WriteSVG draws the scene as an SVG document.
+func WriteSVG(w io.Writer, s Scene) error {
+	l := layoutScene(s)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		l.w, l.h, l.w, l.h)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", l.w, l.h, hexColor(colorTable))
+	for _, lb := range l.labels {
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" fill="%s">%s</text>`+"\n",
+			lb.x, lb.y, labelSize-2, hexColor(colorLabel), lb.text)
+	}
+	for _, p := range l.tiles {
+		writeSVGTile(bw, p)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func writeSVGTile(w io.Writer, p placed) {
+	width, height := p.size()
+	fill := colorFace
+	if p.faceDown {
+		fill = colorBack
+	}
+	fmt.Fprintf(w, `<g`)
+	if p.dim {
+		fmt.Fprintf(w, ` opacity="%g"`, dimOpacity)
+	}
+	fmt.Fprintf(w, `><rect x="%d" y="%d" width="%d" height="%d" rx="3" fill="%s" stroke="%s"/>`,
+		p.x, p.y, width, height, hexColor(fill), hexColor(colorBorder))
+	if !p.faceDown {
+		top, bottom := face(p.tile, true)
+		cx, cy := p.x+width/2, p.y+height/2
+		rotate := ""
+		if p.sideways {
+			rotate = fmt.Sprintf(` transform="rotate(-90 %d %d)"`, cx, cy)
+		}
+		fmt.Fprintf(w, `<text x="%d" y="%d" font-size="16" font-weight="bold" text-anchor="middle" fill="%s"%s>`,
+			cx, cy, hexColor(ink(p.tile)), rotate)
+		if bottom == "" {
+			fmt.Fprintf(w, `<tspan x="%d" dy="6">%s</tspan>`, cx, top)
+		} else {
+			fmt.Fprintf(w, `<tspan x="%d" dy="-1">%s</tspan><tspan x="%d" dy="14" font-size="11">%s</tspan>`,
+				cx, top, cx, bottom)
+		}
+		fmt.Fprint(w, `</text>`)
+	}
+	fmt.Fprintln(w, `</g>`)
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func puzzleScene(t *testing.T) Scene {
+	t.Helper()
+	pon := mustHand(t, "555z")
+	kan := mustHand(t, "0555m")
+	return Scene{
+		Hand: mustHand(t, "34067p11s"),
+		Melds: []engine.Meld{
+			{Kind: engine.MeldPon, Tiles: pon, Called: pon[2], From: 3},
+			{Kind: engine.MeldAnkan, Tiles: kan, Called: kan[3], From: 0},
+		},
+		River: []engine.Discard{
+			{Tile: mustHand(t, "1z")[0]},
+			{Tile: mustHand(t, "9m")[0], Called: true},
+			{Tile: mustHand(t, "8p")[0], Riichi: true},
+		},
+		Dora: mustHand(t, "4s"),
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, puzzleScene(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	rects, texts, rotated := 0, 0, 0
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch el.Name.Local {
+		case "rect":
+			rects++
+		case "text":
+			texts++
+			for _, a := range el.Attr {
+				if a.Name.Local == "transform" && strings.HasPrefix(a.Value, "rotate(-90") {
+					rotated++
+				}
+			}
+		}
+	}
+	// Background plus dora, river, hand and melds.
+	if want := 1 + 1 + 3 + 7 + 3 + 4; rects != want {
+		t.Errorf("%d rects, want %d", rects, want)
+	}
+	// One label, and no text on the two face-down kan tiles.
+	if want := 1 + 1 + 3 + 7 + 3 + 2; texts != want {
+		t.Errorf("%d texts, want %d", texts, want)
+	}
+	if rotated != 2 {
+		t.Errorf("%d rotated tiles, want the riichi tile and the called one", rotated)
+	}
+}
+
+func TestWriteSVGFaces(t *testing.T) {
+	var buf bytes.Buffer
+	s := Scene{Hand: mustHand(t, "0m7z")}
+	if err := WriteSVG(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{">5<", ">萬<", ">中<", hexColor(ink(s.Hand[0]))} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG lacks %q", want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package rl exposes games played by package table as a reinforcement
+// learning environment: a fixed-size observation of what a seat knows, a
+// fixed action space with a mask of the legal actions, and step/reset
+// driven episodes with shaped rewards.
+package rl
+
+import (
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/score"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Observation planes. Each plane has one entry per tile kind; tile planes
+// hold counts from 0 to 4 and scalar planes repeat one value across the
+// plane. Seats are counted from the observing seat: 0 is the seat
+// itself, 1 the next seat in turn order (shimocha), and so on.
+const (
+	PlaneHand           = 0  // concealed tiles
+	PlaneRed            = 1  // red fives in hand, on the five of their suit
+	PlaneDrawn          = 2  // the tile just drawn
+	PlaneOffer          = 3  // the tile that can be called
+	PlaneRivers         = 4  // 4 planes: each seat's discards
+	PlaneRiichiTiles    = 8  // 4 planes: each seat's riichi declaration tile
+	PlaneMelds          = 12 // 4 planes: each seat's called and kan tiles
+	PlaneDoraIndicators = 16
+	PlaneDora           = 17 // the tiles the indicators make dora
+	PlaneRoundWind      = 18
+	PlaneSeatWind       = 19
+	PlaneScores         = 20 // 4 planes: scores / ScoreScale
+	PlaneRiichi         = 24 // 4 planes: 1 for a seat in riichi
+	PlaneDealer         = 28 // 4 planes: 1 for the dealer
+	PlaneHonba          = 32 // honba / 10
+	PlaneSticks         = 33 // riichi sticks on the table / 10
+	PlaneTilesLeft      = 34 // live wall draws left / 70
+	NumPlanes           = 35
+)
+
+// ScoreScale is what scores are divided by in the score planes.
+const ScoreScale = 100000
+
+// liveWall is the number of draws in a round without kans.
+const liveWall = 70
+
+// Observation is what one seat knows at a decision.
+type Observation [NumPlanes][engine.NumKinds]float32
+
+// Encode builds the observation of the seat deciding in v.
+func Encode(v *table.View) Observation {
+	var o Observation
+	r := v.Round
+	rel := func(s int) int { return (s - v.Seat + 4) % 4 }
+
+	for _, t := range r.Hands[v.Seat] {
+		o[PlaneHand][t.Index()]++
+		if t.IsRed() {
+			o[PlaneRed][t.Index()]++
+		}
+	}
+	if v.Drawn != nil {
+		o[PlaneDrawn][v.Drawn.Index()] = 1
+	}
+	if v.Offer != nil {
+		o[PlaneOffer][v.Offer.Index()] = 1
+	}
+	for s := range 4 {
+		p := rel(s)
+		for _, d := range r.Discards[s] {
+			o[PlaneRivers+p][d.Tile.Index()]++
+			if d.Riichi {
+				o[PlaneRiichiTiles+p][d.Tile.Index()] = 1
+			}
+		}
+		for _, m := range r.Melds[s] {
+			for _, t := range m.Tiles {
+				o[PlaneMelds+p][t.Index()]++
+			}
+		}
+		fill(&o[PlaneScores+p], float32(v.Scores[s])/ScoreScale)
+		if r.Riichi[s] {
+			fill(&o[PlaneRiichi+p], 1)
+		}
+	}
+	for _, t := range r.DoraIndicators {
+		o[PlaneDoraIndicators][t.Index()]++
+		o[PlaneDora][score.DoraFromIndicator(t)]++
+	}
+	o[PlaneRoundWind][r.Wind.Tile().Index()] = 1
+	o[PlaneSeatWind][engine.Wind((v.Seat-r.Dealer+4)%4).Tile().Index()] = 1
+	fill(&o[PlaneDealer+rel(r.Dealer)], 1)
+	fill(&o[PlaneHonba], float32(r.Honba)/10)
+	fill(&o[PlaneSticks], float32(r.RiichiSticks)/10)
+	fill(&o[PlaneTilesLeft], float32(r.TilesLeft)/liveWall)
+	return o
+}
+
+func fill(p *[engine.NumKinds]float32, v float32) {
+	for i := range p {
+		p[i] = v
+	}
+}
+
+// The action space. Discards and kans are by tile kind: a five is
+// discarded as a plain five while one is held, keeping the red one.
+const (
+	ActionDiscard = 0                               // +kind
+	ActionRiichi  = ActionDiscard + engine.NumKinds // +kind: declare riichi discarding it
+	ActionTsumo   = ActionRiichi + engine.NumKinds
+	ActionRon     = ActionTsumo + 1
+	ActionPass    = ActionRon + 1
+	ActionChi     = ActionPass + 1 // +0, 1, 2: the called tile is the lowest, middle or highest of the run
+	ActionPon     = ActionChi + 3
+	ActionOpenKan = ActionPon + 1
+	ActionKan     = ActionOpenKan + 1 // +kind: closed kan or added kan
+	NumActions    = ActionKan + engine.NumKinds
+)
+
+// Mask marks the legal actions.
+type Mask [NumActions]bool
+
+// Index returns the place of a in the action space.
+func Index(a table.Action) int {
+	switch a.Type {
+	case table.ActionDiscard:
+		return ActionDiscard + a.Tile.Index()
+	case table.ActionRiichi:
+		return ActionRiichi + a.Tile.Index()
+	case table.ActionTsumo:
+		return ActionTsumo
+	case table.ActionRon:
+		return ActionRon
+	case table.ActionPass:
+		return ActionPass
+	case table.ActionChi:
+		lo := a.Meld.Called.Index()
+		for _, t := range a.Meld.Tiles {
+			lo = min(lo, t.Index())
+		}
+		return ActionChi + a.Meld.Called.Index() - lo
+	case table.ActionPon:
+		return ActionPon
+	case table.ActionOpenKan:
+		return ActionOpenKan
+	default:
+		return ActionKan + a.Meld.Called.Index()
+	}
+}
+
+// Actions maps legal onto the action space. It returns the mask and, for
+// each legal index, the action played for it. Where several actions
+// share an index (a red or a plain five), the plain five is discarded and
+// otherwise the first is taken.
+func Actions(legal []table.Action) (Mask, [NumActions]*table.Action) {
+	var mask Mask
+	var acts [NumActions]*table.Action
+	for i := range legal {
+		a := &legal[i]
+		k := Index(*a)
+		if mask[k] && !(acts[k].Tile.IsRed() && (a.Type == table.ActionDiscard || a.Type == table.ActionRiichi)) {
+			continue
+		}
+		mask[k], acts[k] = true, a
+	}
+	return mask, acts
+}
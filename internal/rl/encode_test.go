@@ -0,0 +1,107 @@
+package rl
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+func tile(t *testing.T, s string) engine.Tile {
+	t.Helper()
+	tt, err := engine.ParseTile(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tt
+}
+
+func TestEncode(t *testing.T) {
+	hand, err := engine.ParseHandCompact("1120m55p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &engine.Round{Dealer: 3, Wind: engine.WindSouth, Honba: 2, TilesLeft: 35,
+		DoraIndicators: []engine.Tile{tile(t, "9s")}}
+	r.Hands[1] = hand
+	r.Discards[2] = []engine.Discard{{Tile: tile(t, "E")}, {Tile: tile(t, "7p"), Riichi: true}}
+	r.Riichi[2] = true
+	p := tile(t, "5p")
+	r.Melds[0] = []engine.Meld{{Kind: engine.MeldPon, Tiles: []engine.Tile{p, p, p}, Called: p, From: 2}}
+	drawn := tile(t, "2m")
+	v := &table.View{Seat: 1, Round: r, Scores: [4]int{10000, 20000, 30000, 40000}, Drawn: &drawn}
+	o := Encode(v)
+
+	idx := func(s string) int { return tile(t, s).Index() }
+	tests := []struct {
+		name         string
+		plane, index int
+		want         float32
+	}{
+		{"two 1m in hand", PlaneHand, idx("1m"), 2},
+		{"red 5m in hand", PlaneHand, idx("5m"), 1},
+		{"red 5m marked", PlaneRed, idx("5m"), 1},
+		{"drawn", PlaneDrawn, idx("2m"), 1},
+		{"no offer", PlaneOffer, idx("2m"), 0},
+		{"next seat's river", PlaneRivers + 1, idx("E"), 1},
+		{"riichi tile", PlaneRiichiTiles + 1, idx("7p"), 1},
+		{"previous seat's meld", PlaneMelds + 3, idx("5p"), 3},
+		{"dora indicator", PlaneDoraIndicators, idx("9s"), 1},
+		{"dora", PlaneDora, idx("1s"), 1},
+		{"round wind", PlaneRoundWind, idx("S"), 1},
+		{"seat wind", PlaneSeatWind, idx("W"), 1},
+		{"own score", PlaneScores, 0, 0.2},
+		{"previous seat's score", PlaneScores + 3, 9, 0.1},
+		{"riichi", PlaneRiichi + 1, 5, 1},
+		{"dealer", PlaneDealer + 2, 0, 1},
+		{"honba", PlaneHonba, 33, 0.2},
+		{"tiles left", PlaneTilesLeft, 0, 0.5},
+	}
+	for _, tt := range tests {
+		if got := o[tt.plane][tt.index]; got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	five, red := tile(t, "5m"), tile(t, "0m")
+	three, four, six := tile(t, "3m"), tile(t, "4m"), tile(t, "6m")
+	chi := func(called engine.Tile, own ...engine.Tile) table.Action {
+		return table.Action{Type: table.ActionChi, Meld: &engine.Meld{Kind: engine.MeldChi, Tiles: append([]engine.Tile{called}, own...), Called: called}}
+	}
+	legal := []table.Action{
+		{Type: table.ActionPass},
+		{Type: table.ActionDiscard, Tile: red},
+		{Type: table.ActionDiscard, Tile: five},
+		chi(four, three, five),
+		chi(four, three, red),
+		chi(four, five, six),
+		{Type: table.ActionKan, Meld: &engine.Meld{Kind: engine.MeldAnkan, Tiles: []engine.Tile{six, six, six, six}, Called: six}},
+	}
+	mask, acts := Actions(legal)
+	tests := []struct {
+		index int
+		want  table.Action
+	}{
+		{ActionPass, legal[0]},
+		{ActionDiscard + five.Index(), legal[2]}, // keeps the red five
+		{ActionChi + 1, legal[3]},
+		{ActionChi + 0, legal[5]},
+		{ActionKan + six.Index(), legal[6]},
+	}
+	n := 0
+	for _, ok := range mask {
+		if ok {
+			n++
+		}
+	}
+	if n != len(tests) {
+		t.Errorf("%d legal indexes, want %d", n, len(tests))
+	}
+	for _, tt := range tests {
+		if !mask[tt.index] || acts[tt.index] == nil || !acts[tt.index].Equal(tt.want) {
+			t.Errorf("index %d: %v %v, want %s", tt.index, mask[tt.index], acts[tt.index], tt.want)
+		}
+	}
+}
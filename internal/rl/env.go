@@ -0,0 +1,229 @@
+package rl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/bot"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Reward shapes what an Env pays the agent. Every term is optional.
+type Reward struct {
+	// Placement is paid at the end of the game by final place, first to
+	// fourth.
+	Placement [4]float64
+	// Score is paid per point gained or lost, at the end of each round.
+	Score float64
+	// Win and DealIn are paid when the agent wins a hand or deals into
+	// one.
+	Win, DealIn float64
+}
+
+// DefaultReward pays only for the final place, in proportion to Tenhou's
+// ranked points: 90, 45, 0 and -135, scaled so first place earns 1.
+func DefaultReward() Reward {
+	return Reward{Placement: [4]float64{1, 0.5, 0, -1.5}}
+}
+
+// Config sets up an Env.
+type Config struct {
+	Seat  int // the agent's seat; seat 0 deals first
+	Rules engine.Rules
+	// Opponents sit in the other seats, in turn order after Seat. A nil
+	// opponent is a bot.Bot.
+	Opponents [3]table.Player
+	Reward    Reward
+	// SkipForced plays the only legal action for the agent, as when in
+	// riichi without a win, instead of stopping for a step.
+	SkipForced bool
+}
+
+// Timestep is what the agent sees after Reset or Step.
+type Timestep struct {
+	Obs    Observation
+	Mask   Mask
+	Reward float64 // earned since the last step
+	Done   bool
+	Result *table.Result // the finished game, once Done
+}
+
+// Env is one game at a time with the agent in one seat. The game runs on
+// its own goroutine and waits at each of the agent's decisions for Step.
+// An Env is not safe for concurrent use; run one per goroutine.
+type Env struct {
+	cfg     Config
+	players [4]table.Player
+
+	running bool
+	cancel  context.CancelFunc
+	asks    chan decision
+	ended   chan gameEnd
+	pending *decision
+	acts    [NumActions]*table.Action
+
+	// Kept by observe on the game's goroutine between decisions.
+	reward float64
+	score  int
+}
+
+type decision struct {
+	v     *table.View
+	legal []table.Action
+	reply chan table.Action
+}
+
+type gameEnd struct {
+	res *table.Result
+	err error
+}
+
+// New makes an environment. Call Reset to start a game.
+func New(cfg Config) (*Env, error) {
+	if cfg.Seat < 0 || cfg.Seat > 3 {
+		return nil, fmt.Errorf("invalid seat %d", cfg.Seat)
+	}
+	e := &Env{cfg: cfg}
+	for i, p := range cfg.Opponents {
+		if p == nil {
+			p = table.Local(bot.Bot{})
+		}
+		e.players[(cfg.Seat+1+i)%4] = p
+	}
+	return e, nil
+}
+
+// Reset abandons any game in progress and starts a new one whose walls
+// are drawn from seed, returning the agent's first decision.
+func (e *Env) Reset(seed uint64) (Timestep, error) {
+	e.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	e.running, e.cancel = true, cancel
+	e.asks, e.ended = make(chan decision), make(chan gameEnd, 1)
+	e.pending, e.reward, e.score = nil, 0, e.cfg.Rules.StartingPoints
+
+	players := e.players
+	players[e.cfg.Seat] = agent{e.asks}
+	cfg := table.Config{Rules: e.cfg.Rules, Seed: seed, OnEvent: e.observe}
+	ended := e.ended
+	go func() {
+		res, err := table.Play(ctx, players, cfg)
+		ended <- gameEnd{res, err}
+	}()
+	return e.next()
+}
+
+// Step plays action, which must be legal in the last Timestep's Mask,
+// and runs the game to the agent's next decision or the end.
+func (e *Env) Step(action int) (Timestep, error) {
+	if e.pending == nil {
+		return Timestep{}, errors.New("no decision pending; call Reset")
+	}
+	if action < 0 || action >= NumActions || e.acts[action] == nil {
+		return Timestep{}, fmt.Errorf("action %d is not legal", action)
+	}
+	e.pending.reply <- *e.acts[action]
+	e.pending = nil
+	return e.next()
+}
+
+// Close abandons any game in progress.
+func (e *Env) Close() {
+	e.stop()
+}
+
+func (e *Env) stop() {
+	if !e.running {
+		return
+	}
+	e.cancel()
+	<-e.ended
+	e.running = false
+}
+
+// next waits for the agent's next decision or the end of the game.
+func (e *Env) next() (Timestep, error) {
+	for {
+		select {
+		case d := <-e.asks:
+			if e.cfg.SkipForced && len(d.legal) == 1 {
+				d.reply <- d.legal[0]
+				continue
+			}
+			mask, acts := Actions(d.legal)
+			e.pending, e.acts = &d, acts
+			ts := Timestep{Obs: Encode(d.v), Mask: mask, Reward: e.reward}
+			e.reward = 0
+			return ts, nil
+
+		case end := <-e.ended:
+			e.running = false
+			e.cancel()
+			if end.err != nil {
+				return Timestep{}, end.err
+			}
+			ts := Timestep{Reward: e.reward, Done: true, Result: end.res}
+			e.reward = 0
+			return ts, nil
+		}
+	}
+}
+
+// observe adds up the agent's reward as the game is played.
+func (e *Env) observe(ev engine.Event) {
+	rw := &e.cfg.Reward
+	seat := e.cfg.Seat
+	settle := func(score int) {
+		e.reward += rw.Score * float64(score-e.score)
+		e.score = score
+	}
+	switch ev.Type {
+	case engine.EventRoundStart:
+		settle(ev.Round.Scores[seat])
+	case engine.EventWin:
+		switch seat {
+		case ev.Win.Seat:
+			e.reward += rw.Win
+		case ev.Win.From:
+			e.reward += rw.DealIn
+		}
+	case engine.EventGameEnd:
+		settle(ev.End.Scores[seat])
+		res := table.Result{Scores: ev.End.Scores}
+		for place, s := range res.Placement() {
+			if s == seat {
+				e.reward += rw.Placement[place]
+			}
+		}
+	}
+}
+
+// agent is the seat the Env plays: each decision is handed to Step.
+type agent struct {
+	asks chan<- decision
+}
+
+func (p agent) Turn(ctx context.Context, v *table.View, legal []table.Action) (table.Action, error) {
+	return p.ask(ctx, v, legal)
+}
+
+func (p agent) Call(ctx context.Context, v *table.View, legal []table.Action) (table.Action, error) {
+	return p.ask(ctx, v, legal)
+}
+
+func (p agent) ask(ctx context.Context, v *table.View, legal []table.Action) (table.Action, error) {
+	d := decision{v: v, legal: legal, reply: make(chan table.Action, 1)}
+	select {
+	case p.asks <- d:
+	case <-ctx.Done():
+		return table.Action{}, ctx.Err()
+	}
+	select {
+	case a := <-d.reply:
+		return a, nil
+	case <-ctx.Done():
+		return table.Action{}, ctx.Err()
+	}
+}
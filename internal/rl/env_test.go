@@ -0,0 +1,117 @@
+package rl
+
+import (
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func eastRules() engine.Rules {
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	return rules
+}
+
+// playRandom plays legal actions at random until the game ends and
+// returns the rewards added up, the steps taken and the last Timestep.
+func playRandom(t *testing.T, e *Env, seed uint64) (float64, int, Timestep) {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(seed, 1))
+	ts, err := e.Reset(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, steps := ts.Reward, 0
+	for !ts.Done {
+		var legal []int
+		for i, ok := range ts.Mask {
+			if ok {
+				legal = append(legal, i)
+			}
+		}
+		if len(legal) == 0 {
+			t.Fatal("no legal action")
+		}
+		if ts.Obs[PlaneHand] == ([engine.NumKinds]float32{}) {
+			t.Fatal("empty hand in observation")
+		}
+		if ts, err = e.Step(legal[rng.IntN(len(legal))]); err != nil {
+			t.Fatal(err)
+		}
+		total += ts.Reward
+		steps++
+	}
+	return total, steps, ts
+}
+
+func TestEnv(t *testing.T) {
+	e, err := New(Config{Seat: 2, Rules: eastRules(), Reward: DefaultReward()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	total, _, last := playRandom(t, e, 3)
+	if _, err := engine.Replay(last.Result.Events, -1); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	place := 0
+	for i, s := range last.Result.Placement() {
+		if s == 2 {
+			place = i
+		}
+	}
+	if total != DefaultReward().Placement[place] {
+		t.Errorf("reward %v in place %d", total, place+1)
+	}
+
+	_, _, again := playRandom(t, e, 3)
+	if !reflect.DeepEqual(last.Result.Events, again.Result.Events) {
+		t.Error("the same seed and actions played a different game")
+	}
+}
+
+func TestEnvScoreReward(t *testing.T) {
+	rules := eastRules()
+	e, err := New(Config{Seat: 0, Rules: rules, Reward: Reward{Score: 0.001}, SkipForced: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	total, _, last := playRandom(t, e, 8)
+	want := 0.001 * float64(last.Result.Scores[0]-rules.StartingPoints)
+	if math.Abs(total-want) > 1e-9 {
+		t.Errorf("reward %v, want %v", total, want)
+	}
+}
+
+func TestEnvErrors(t *testing.T) {
+	if _, err := New(Config{Seat: 4}); err == nil {
+		t.Error("seat 4 accepted")
+	}
+	e, err := New(Config{Rules: eastRules()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if _, err := e.Step(0); err == nil {
+		t.Error("Step before Reset accepted")
+	}
+	ts, err := e.Reset(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Mask[ActionPass] {
+		t.Fatal("the dealer's first decision offers a pass")
+	}
+	if _, err := e.Step(ActionPass); err == nil {
+		t.Error("illegal action accepted")
+	}
+	// Abandon the game half way; the next one starts cleanly.
+	if _, err := e.Reset(2); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,211 @@
+// Package score values winning riichi hands: yaku, han, fu and points.
+package score
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+var (
+	// ErrIncomplete is returned for a hand that is not a winning shape.
+	ErrIncomplete = errors.New("hand is not complete")
+	// ErrNoYaku is returned for a winning shape without a yaku.
+	ErrNoYaku = errors.New("hand has no yaku")
+)
+
+// Result is the value of a winning hand. Points and payments leave out
+// honba and riichi sticks.
+type Result struct {
+	Yaku    []engine.YakuHan
+	Han     int
+	Fu      int // 0 for yakuman
+	Yakuman int // multiples of yakuman; 0 for a regular hand
+
+	Points      int // total the winner receives
+	Ron         int // paid by the discarder
+	TsumoDealer int // paid by the dealer on a non-dealer's tsumo
+	TsumoOther  int // paid by each non-dealer on a tsumo
+}
+
+// Score values a hand whose Winning tile is set. The seat wind decides
+// whether the winner is the dealer. When the tiles can be read as more
+// than one winning shape, the one worth the most is taken.
+func Score(h engine.Hand, rules engine.Rules) (Result, error) {
+	if h.Winning == nil {
+		return Result{}, errors.New("hand has no winning tile")
+	}
+	counts, err := engine.CountTiles(h.Tiles())
+	if err != nil {
+		return Result{}, err
+	}
+
+	var (
+		best    Result
+		found   bool
+		failure = ErrIncomplete
+	)
+	try := func(r Result, err error) {
+		if err != nil {
+			if errors.Is(err, ErrNoYaku) {
+				failure = err
+			}
+			return
+		}
+		if !found || better(r, best) {
+			best, found = r, true
+		}
+	}
+
+	w := h.Winning.Index()
+	for _, groups := range decompose(counts.Kinds, 4-len(h.Melds)) {
+		for _, g := range winningGroups(groups, w) {
+			s := newShape(h, rules, groups, g)
+			try(s.score())
+		}
+	}
+	if len(h.Melds) == 0 {
+		if isChiitoi(&counts) {
+			try(scoreChiitoi(h, rules, &counts))
+		}
+		if isKokushi(&counts) {
+			try(scoreKokushi(h, rules, &counts))
+		}
+	}
+	if !found {
+		return Result{}, failure
+	}
+	return best, nil
+}
+
+func better(a, b Result) bool {
+	return cmp.Or(
+		cmp.Compare(a.Points, b.Points),
+		cmp.Compare(a.Han, b.Han),
+		cmp.Compare(a.Fu, b.Fu),
+	) > 0
+}
+
+// finish adds dora to a hand with yaku and works out the points.
+func finish(h engine.Hand, rules engine.Rules, yaku []engine.YakuHan, fu int, tiles []engine.Tile) (Result, error) {
+	if len(yaku) == 0 {
+		return Result{}, ErrNoYaku
+	}
+	r := Result{Fu: fu}
+	for _, y := range yaku {
+		if y.Yaku.IsYakuman() {
+			r.Yakuman += y.Han / 13
+		}
+	}
+	if r.Yakuman > 0 {
+		yaku = slices.DeleteFunc(yaku, func(y engine.YakuHan) bool { return !y.Yaku.IsYakuman() })
+	} else {
+		yaku = append(yaku, doraYaku(h, rules, tiles)...)
+	}
+	r.Yaku = yaku
+	for _, y := range yaku {
+		r.Han += y.Han
+	}
+	r.pay(basicPoints(r.Han, r.Fu, r.Yakuman, rules), h.SeatWind == engine.WindEast, h.Tsumo)
+	return r, nil
+}
+
+// doraYaku counts dora, ura dora (for a riichi hand) and red fives.
+func doraYaku(h engine.Hand, rules engine.Rules, tiles []engine.Tile) []engine.YakuHan {
+	count := func(indicators []engine.Tile) int {
+		n := 0
+		for _, ind := range indicators {
+			d := DoraFromIndicator(ind)
+			for _, t := range tiles {
+				if t.Index() == d {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	var out []engine.YakuHan
+	if n := count(h.Dora); n > 0 {
+		out = append(out, engine.YakuHan{Yaku: engine.YakuDora, Han: n})
+	}
+	if h.Riichi && rules.UraDora {
+		if n := count(h.Ura); n > 0 {
+			out = append(out, engine.YakuHan{Yaku: engine.YakuUraDora, Han: n})
+		}
+	}
+	red := 0
+	for _, t := range tiles {
+		if t.IsRed() {
+			red++
+		}
+	}
+	if red > 0 {
+		out = append(out, engine.YakuHan{Yaku: engine.YakuAkaDora, Han: red})
+	}
+	return out
+}
+
+// DoraFromIndicator returns the kind index of the dora an indicator
+// shows: the next tile in its suit, winds and dragons wrapping around.
+func DoraFromIndicator(t engine.Tile) int {
+	i := t.Index()
+	switch {
+	case i < 0:
+		return -1
+	case i < 27:
+		return i/9*9 + (i%9+1)%9
+	case i < 31:
+		return 27 + (i-27+1)%4
+	default:
+		return 31 + (i-31+1)%3
+	}
+}
+
+// basicPoints returns the base points a hand is worth, before the
+// dealer and payment multipliers.
+func basicPoints(han, fu, yakuman int, rules engine.Rules) int {
+	switch {
+	case yakuman > 0:
+		return 8000 * yakuman
+	case han >= 13 && rules.KazoeYakuman:
+		return 8000
+	case han >= 11:
+		return 6000
+	case han >= 8:
+		return 4000
+	case han >= 6:
+		return 3000
+	case han >= 5:
+		return 2000
+	}
+	b := fu << (han + 2)
+	if rules.Kiriage && (han == 4 && fu == 30 || han == 3 && fu == 60) {
+		return 2000
+	}
+	return min(b, 2000)
+}
+
+func (r *Result) pay(basic int, dealer, tsumo bool) {
+	switch {
+	case tsumo && dealer:
+		r.TsumoOther = roundUp(2 * basic)
+		r.Points = 3 * r.TsumoOther
+	case tsumo:
+		r.TsumoDealer = roundUp(2 * basic)
+		r.TsumoOther = roundUp(basic)
+		r.Points = r.TsumoDealer + 2*r.TsumoOther
+	case dealer:
+		r.Ron = roundUp(6 * basic)
+		r.Points = r.Ron
+	default:
+		r.Ron = roundUp(4 * basic)
+		r.Points = r.Ron
+	}
+}
+
+func roundUp(p int) int {
+	return (p + 99) / 100 * 100
+}
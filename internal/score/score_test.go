@@ -0,0 +1,100 @@
+package score
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		hand    string
+		yaku    []engine.Yaku
+		han, fu int
+		points  int
+	}{
+		{"234m567p345s88m67s +8s s:S tsumo", []engine.Yaku{engine.YakuMenzenTsumo, engine.YakuPinfu, engine.YakuTanyao}, 3, 20, 2700},
+		{"123m456p789s11z46m +5m s:S ron riichi", []engine.Yaku{engine.YakuRiichi}, 1, 40, 1300},
+		{"234m567p88m67s [555z] +8s s:S ron", []engine.Yaku{engine.YakuHaku}, 1, 30, 1000},
+		{"234m567p88m67s [234s] +8s s:S ron", []engine.Yaku{engine.YakuTanyao}, 1, 30, 1000},
+		{"234m567p88m67s [234s] +8s s:S ron d:7s", []engine.Yaku{engine.YakuTanyao, engine.YakuDora}, 2, 30, 2000},
+		{"1122m3344p5566s7z +7z s:S ron riichi", []engine.Yaku{engine.YakuRiichi, engine.YakuChiitoitsu}, 3, 25, 3200},
+		// Three concealed triplets are worth more than three runs.
+		{"111222333m78p55s +9p s:S ron riichi", []engine.Yaku{engine.YakuRiichi, engine.YakuSanankou}, 3, 50, 6400},
+		{"19m19p19s1234567z +1m ron", []engine.Yaku{engine.YakuKokushi13}, 13, 0, 48000},
+		{"1112345678999m +5m s:S ron", []engine.Yaku{engine.YakuJunseiChuuren}, 13, 0, 32000},
+		{"111m222p333s444z5z +5z s:S tsumo", []engine.Yaku{engine.YakuSuuankouTanki}, 13, 0, 32000},
+	}
+	for _, tt := range tests {
+		h, err := engine.ParseHand(tt.hand)
+		if err != nil {
+			t.Fatalf("ParseHand(%q): %v", tt.hand, err)
+		}
+		r, err := Score(h, engine.DefaultRules())
+		if err != nil {
+			t.Errorf("Score(%q): %v", tt.hand, err)
+			continue
+		}
+		var yaku []engine.Yaku
+		for _, y := range r.Yaku {
+			yaku = append(yaku, y.Yaku)
+		}
+		if !slices.Equal(yaku, tt.yaku) || r.Han != tt.han || r.Fu != tt.fu || r.Points != tt.points {
+			t.Errorf("Score(%q) = %v %d han %d fu %d, want %v %d han %d fu %d",
+				tt.hand, yaku, r.Han, r.Fu, r.Points, tt.yaku, tt.han, tt.fu, tt.points)
+		}
+	}
+}
+
+func TestScoreErrors(t *testing.T) {
+	tests := []struct {
+		hand string
+		err  error
+	}{
+		{"123m456p789s11z46m +5m s:S ron", ErrNoYaku},
+		{"123m456p789s11z46m +9m s:S ron riichi", ErrIncomplete},
+	}
+	for _, tt := range tests {
+		h, err := engine.ParseHand(tt.hand)
+		if err != nil {
+			t.Fatalf("ParseHand(%q): %v", tt.hand, err)
+		}
+		if _, err := Score(h, engine.DefaultRules()); !errors.Is(err, tt.err) {
+			t.Errorf("Score(%q) error = %v, want %v", tt.hand, err, tt.err)
+		}
+	}
+}
+
+func TestPayments(t *testing.T) {
+	tests := []struct {
+		han, fu       int
+		dealer, tsumo bool
+		want          Result
+	}{
+		{1, 30, false, false, Result{Points: 1000, Ron: 1000}},
+		{3, 40, true, false, Result{Points: 7700, Ron: 7700}},
+		{2, 30, false, true, Result{Points: 2000, TsumoDealer: 1000, TsumoOther: 500}},
+		{4, 30, true, true, Result{Points: 11700, TsumoOther: 3900}},
+		{5, 30, false, false, Result{Points: 8000, Ron: 8000}},
+		{13, 30, false, false, Result{Points: 32000, Ron: 32000}},
+	}
+	for _, tt := range tests {
+		var r Result
+		r.pay(basicPoints(tt.han, tt.fu, 0, engine.DefaultRules()), tt.dealer, tt.tsumo)
+		if r.Points != tt.want.Points || r.Ron != tt.want.Ron || r.TsumoDealer != tt.want.TsumoDealer || r.TsumoOther != tt.want.TsumoOther {
+			t.Errorf("%d han %d fu dealer=%v tsumo=%v = %+v, want %+v", tt.han, tt.fu, tt.dealer, tt.tsumo, r, tt.want)
+		}
+	}
+}
+
+func TestDoraFromIndicator(t *testing.T) {
+	for in, want := range map[string]string{"3m": "4m", "9p": "1p", "4z": "1z", "7z": "5z", "5z": "6z"} {
+		ind, _ := engine.ParseTile(in)
+		dora, _ := engine.ParseTile(want)
+		if got := DoraFromIndicator(ind); got != dora.Index() {
+			t.Errorf("DoraFromIndicator(%s) = %d, want %s", in, got, want)
+		}
+	}
+}
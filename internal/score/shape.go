@@ -0,0 +1,583 @@
+package score
+
+import (
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+type groupKind uint8
+
+const (
+	groupRun groupKind = iota
+	groupTriplet
+	groupKan
+	groupPair
+)
+
+// group is one set or the pair of a winning hand.
+type group struct {
+	kind  groupKind
+	index int  // kind index of the lowest tile
+	open  bool // called, or a triplet completed by ron
+}
+
+type wait uint8
+
+const (
+	waitRyanmen wait = iota // two-sided
+	waitKanchan             // closed
+	waitPenchan             // edge
+	waitTanki               // pair
+	waitShanpon             // one of two pairs
+)
+
+// Kind indices used by the yaku checks.
+const (
+	indexEast  = 27
+	indexHaku  = 31
+	indexHatsu = 32
+	indexChun  = 33
+)
+
+func isTerminalOrHonor(i int) bool {
+	return i >= 27 || i%9 == 0 || i%9 == 8
+}
+
+// hasTerminalOrHonor reports whether any tile of g is a terminal or honor.
+func (g group) hasTerminalOrHonor() bool {
+	if g.kind == groupRun {
+		return g.index%9 == 0 || g.index%9 == 6
+	}
+	return isTerminalOrHonor(g.index)
+}
+
+func (g group) isSet() bool {
+	return g.kind == groupTriplet || g.kind == groupKan
+}
+
+// decompose lists every way to split concealed counts into sets and one
+// pair.
+func decompose(kinds [engine.NumKinds]uint8, sets int) [][]group {
+	total := 0
+	for _, n := range kinds {
+		total += int(n)
+	}
+	if sets < 0 || total != 3*sets+2 {
+		return nil
+	}
+	var out [][]group
+	for p, n := range kinds {
+		if n < 2 {
+			continue
+		}
+		kinds[p] -= 2
+		splitSets(&kinds, 0, []group{{kind: groupPair, index: p}}, &out)
+		kinds[p] += 2
+	}
+	return out
+}
+
+func splitSets(kinds *[engine.NumKinds]uint8, i int, acc []group, out *[][]group) {
+	for i < engine.NumKinds && kinds[i] == 0 {
+		i++
+	}
+	if i == engine.NumKinds {
+		*out = append(*out, slices.Clone(acc))
+		return
+	}
+	if kinds[i] >= 3 {
+		kinds[i] -= 3
+		splitSets(kinds, i, append(acc, group{kind: groupTriplet, index: i}), out)
+		kinds[i] += 3
+	}
+	if i < 27 && i%9 <= 6 && kinds[i+1] > 0 && kinds[i+2] > 0 {
+		kinds[i]--
+		kinds[i+1]--
+		kinds[i+2]--
+		splitSets(kinds, i, append(acc, group{kind: groupRun, index: i}), out)
+		kinds[i]++
+		kinds[i+1]++
+		kinds[i+2]++
+	}
+}
+
+// winningGroups returns the positions of the distinct groups the winning
+// kind w can have completed.
+func winningGroups(groups []group, w int) []int {
+	var out []int
+	for i, g := range groups {
+		fits := g.index == w
+		if g.kind == groupRun {
+			fits = w >= g.index && w <= g.index+2
+		}
+		if fits && !slices.ContainsFunc(out, func(j int) bool { return groups[j] == g }) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// shape is one reading of a winning hand.
+type shape struct {
+	h      engine.Hand
+	rules  engine.Rules
+	groups []group // concealed groups, then the melds
+	wait   wait
+	closed bool
+	tiles  []engine.Tile // every tile, for dora
+	counts [engine.NumKinds]uint8
+}
+
+// newShape reads concealed groups with groups[win] completed by the
+// winning tile, and adds the melds.
+func newShape(h engine.Hand, rules engine.Rules, concealed []group, win int) *shape {
+	s := &shape{h: h, rules: rules, closed: true, tiles: h.Tiles()}
+	s.groups = slices.Clone(concealed)
+
+	w := h.Winning.Index()
+	g := &s.groups[win]
+	switch {
+	case g.kind == groupPair:
+		s.wait = waitTanki
+	case g.kind == groupTriplet:
+		s.wait = waitShanpon
+		g.open = !h.Tsumo
+	case w == g.index+1:
+		s.wait = waitKanchan
+	case w == g.index && g.index%9 == 6, w == g.index+2 && g.index%9 == 0:
+		s.wait = waitPenchan
+	default:
+		s.wait = waitRyanmen
+	}
+
+	for _, m := range h.Melds {
+		mg := group{index: m.Tiles[0].Index(), open: m.IsOpen()}
+		switch {
+		case m.Kind.IsKan():
+			mg.kind = groupKan
+		case m.Kind == engine.MeldPon:
+			mg.kind = groupTriplet
+		default:
+			mg.kind = groupRun
+			for _, t := range m.Tiles {
+				mg.index = min(mg.index, t.Index())
+			}
+		}
+		if m.IsOpen() {
+			s.closed = false
+		}
+		s.groups = append(s.groups, mg)
+		s.tiles = append(s.tiles, m.Tiles...)
+	}
+	for _, t := range s.tiles {
+		s.counts[t.Index()]++
+	}
+	return s
+}
+
+func (s *shape) score() (Result, error) {
+	if yaku := s.yakuman(); len(yaku) > 0 {
+		return finish(s.h, s.rules, yaku, 0, s.tiles)
+	}
+	yaku := situational(s.h, s.closed)
+	pinfu := s.pinfu()
+	if pinfu {
+		yaku = append(yaku, engine.YakuHan{Yaku: engine.YakuPinfu, Han: 1})
+	}
+	yaku = append(yaku, s.yaku()...)
+	return finish(s.h, s.rules, yaku, s.fu(pinfu), s.tiles)
+}
+
+// situational returns the yaku that come from how and when the hand won.
+func situational(h engine.Hand, closed bool) []engine.YakuHan {
+	var yaku []engine.YakuHan
+	add := func(y engine.Yaku, han int, ok bool) {
+		if ok {
+			yaku = append(yaku, engine.YakuHan{Yaku: y, Han: han})
+		}
+	}
+	add(engine.YakuDoubleRiichi, 2, h.DoubleRiichi)
+	add(engine.YakuRiichi, 1, h.Riichi && !h.DoubleRiichi)
+	add(engine.YakuIppatsu, 1, h.Ippatsu && h.Riichi)
+	add(engine.YakuMenzenTsumo, 1, closed && h.Tsumo)
+	add(engine.YakuHaitei, 1, h.Haitei && h.Tsumo)
+	add(engine.YakuHoutei, 1, h.Houtei && !h.Tsumo)
+	add(engine.YakuRinshan, 1, h.Rinshan && h.Tsumo)
+	add(engine.YakuChankan, 1, h.Chankan && !h.Tsumo)
+	return yaku
+}
+
+func (s *shape) isYakuhai(i int) bool {
+	return i >= indexHaku || i == indexEast+int(s.h.SeatWind) || i == indexEast+int(s.h.RoundWind)
+}
+
+func (s *shape) pinfu() bool {
+	if !s.closed || s.wait != waitRyanmen {
+		return false
+	}
+	for _, g := range s.groups {
+		if g.kind == groupPair && s.isYakuhai(g.index) || g.isSet() {
+			return false
+		}
+	}
+	return true
+}
+
+// yaku returns the yaku that come from the hand's shape.
+func (s *shape) yaku() []engine.YakuHan {
+	var yaku []engine.YakuHan
+	add := func(y engine.Yaku, closedHan, openHan int, ok bool) {
+		han := closedHan
+		if !s.closed {
+			han = openHan
+		}
+		if ok && han > 0 {
+			yaku = append(yaku, engine.YakuHan{Yaku: y, Han: han})
+		}
+	}
+
+	var runs, sets []group
+	var pair group
+	concealedSets, kans := 0, 0
+	for _, g := range s.groups {
+		switch {
+		case g.kind == groupRun:
+			runs = append(runs, g)
+		case g.kind == groupPair:
+			pair = g
+		default:
+			sets = append(sets, g)
+			if !g.open {
+				concealedSets++
+			}
+			if g.kind == groupKan {
+				kans++
+			}
+		}
+	}
+
+	tanyaoHan := 1
+	if !s.rules.OpenTanyao {
+		tanyaoHan = 0
+	}
+	add(engine.YakuTanyao, 1, tanyaoHan, allSimples(s.counts))
+
+	var sameRuns [27]int
+	peikou := 0
+	for _, g := range runs {
+		sameRuns[g.index]++
+		if sameRuns[g.index]%2 == 0 {
+			peikou++
+		}
+	}
+	add(engine.YakuRyanpeikou, 3, 0, peikou == 2)
+	add(engine.YakuIipeikou, 1, 0, peikou == 1)
+
+	for _, g := range sets {
+		switch {
+		case g.index == indexHaku:
+			add(engine.YakuHaku, 1, 1, true)
+		case g.index == indexHatsu:
+			add(engine.YakuHatsu, 1, 1, true)
+		case g.index == indexChun:
+			add(engine.YakuChun, 1, 1, true)
+		}
+		add(engine.YakuSeatWind, 1, 1, g.index == indexEast+int(s.h.SeatWind))
+		add(engine.YakuRoundWind, 1, 1, g.index == indexEast+int(s.h.RoundWind))
+	}
+
+	outside, honors := true, false
+	for _, g := range s.groups {
+		outside = outside && g.hasTerminalOrHonor()
+		honors = honors || g.index >= 27
+	}
+	switch {
+	case outside && len(runs) == 0:
+		add(engine.YakuHonroutou, 2, 2, true)
+	case outside && honors:
+		add(engine.YakuChanta, 2, 1, true)
+	case outside:
+		add(engine.YakuJunchan, 3, 2, true)
+	}
+
+	hasRun := func(i int) bool {
+		return slices.ContainsFunc(runs, func(g group) bool { return g.index == i })
+	}
+	hasSet := func(i int) bool {
+		return slices.ContainsFunc(sets, func(g group) bool { return g.index == i })
+	}
+	for suit := range 3 {
+		add(engine.YakuIttsu, 2, 1, hasRun(suit*9) && hasRun(suit*9+3) && hasRun(suit*9+6))
+	}
+	for n := range 9 {
+		add(engine.YakuSanshokuDoujun, 2, 1, n <= 6 && hasRun(n) && hasRun(9+n) && hasRun(18+n))
+		add(engine.YakuSanshokuDoukou, 2, 2, hasSet(n) && hasSet(9+n) && hasSet(18+n))
+	}
+
+	add(engine.YakuToitoi, 2, 2, len(runs) == 0)
+	add(engine.YakuSanankou, 2, 2, concealedSets == 3)
+	add(engine.YakuSankantsu, 2, 2, kans == 3)
+
+	dragonSets := 0
+	for _, g := range sets {
+		if g.index >= indexHaku {
+			dragonSets++
+		}
+	}
+	add(engine.YakuShousangen, 2, 2, dragonSets == 2 && pair.index >= indexHaku)
+
+	yaku = append(yaku, flush(s.counts, s.closed)...)
+	return yaku
+}
+
+func allSimples(counts [engine.NumKinds]uint8) bool {
+	for i, n := range counts {
+		if n > 0 && isTerminalOrHonor(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// flush returns honitsu or chinitsu when every suited tile is of one
+// suit.
+func flush(counts [engine.NumKinds]uint8, closed bool) []engine.YakuHan {
+	suits, honors := 0, false
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		if i >= 27 {
+			honors = true
+		} else {
+			suits |= 1 << (i / 9)
+		}
+	}
+	if suits&(suits-1) != 0 {
+		return nil
+	}
+	han := func(closedHan int) int {
+		if closed {
+			return closedHan
+		}
+		return closedHan - 1
+	}
+	switch {
+	case suits != 0 && !honors:
+		return []engine.YakuHan{{Yaku: engine.YakuChinitsu, Han: han(6)}}
+	case suits != 0:
+		return []engine.YakuHan{{Yaku: engine.YakuHonitsu, Han: han(3)}}
+	}
+	return nil
+}
+
+// yakuman returns the limit hands of a regular shape.
+func (s *shape) yakuman() []engine.YakuHan {
+	var yaku []engine.YakuHan
+	add := func(y engine.Yaku, ok bool) {
+		if ok {
+			yaku = append(yaku, engine.YakuHan{Yaku: y, Han: 13})
+		}
+	}
+
+	concealedSets, kans, dragons, winds := 0, 0, 0, 0
+	windPair := false
+	for _, g := range s.groups {
+		if g.kind == groupPair {
+			windPair = g.index >= indexEast && g.index < indexHaku
+			continue
+		}
+		if g.isSet() && !g.open {
+			concealedSets++
+		}
+		if g.kind == groupKan {
+			kans++
+		}
+		if g.isSet() && g.index >= indexHaku {
+			dragons++
+		}
+		if g.isSet() && g.index >= indexEast && g.index < indexHaku {
+			winds++
+		}
+	}
+	add(engine.YakuSuuankouTanki, concealedSets == 4 && s.wait == waitTanki)
+	add(engine.YakuSuuankou, concealedSets == 4 && s.wait != waitTanki)
+	add(engine.YakuDaisangen, dragons == 3)
+	add(engine.YakuDaisuushii, winds == 4)
+	add(engine.YakuShousuushii, winds == 3 && windPair)
+	add(engine.YakuSuukantsu, kans == 4)
+
+	honorsOnly, terminalsOnly, green := true, true, true
+	for i, n := range s.counts {
+		if n == 0 {
+			continue
+		}
+		honorsOnly = honorsOnly && i >= 27
+		terminalsOnly = terminalsOnly && i < 27 && isTerminalOrHonor(i)
+		green = green && slices.Contains(greenKinds[:], i)
+	}
+	add(engine.YakuTsuuiisou, honorsOnly)
+	add(engine.YakuChinroutou, terminalsOnly)
+	add(engine.YakuRyuuiisou, green)
+
+	if s.closed && len(s.h.Melds) == 0 {
+		if pure, ok := chuuren(s.counts, s.h.Winning.Index()); ok {
+			add(engine.YakuJunseiChuuren, pure)
+			add(engine.YakuChuuren, !pure)
+		}
+	}
+	return yaku
+}
+
+// greenKinds are the all-green tiles: 2s 3s 4s 6s 8s and hatsu.
+var greenKinds = [6]int{19, 20, 21, 23, 25, indexHatsu}
+
+// chuuren reports whether counts are the nine gates, and whether they
+// were waiting on all nine tiles before the win.
+func chuuren(counts [engine.NumKinds]uint8, win int) (pure, ok bool) {
+	suit := -1
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		if i >= 27 || suit >= 0 && i/9 != suit {
+			return false, false
+		}
+		suit = i / 9
+	}
+	if suit < 0 {
+		return false, false
+	}
+	base := [9]uint8{3, 1, 1, 1, 1, 1, 1, 1, 3}
+	for r, need := range base {
+		if counts[suit*9+r] < need {
+			return false, false
+		}
+	}
+	before := counts
+	before[win]--
+	for r, need := range base {
+		if before[suit*9+r] != need {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// fu counts the minipoints of a regular shape, rounded up to ten.
+func (s *shape) fu(pinfu bool) int {
+	switch {
+	case pinfu && s.h.Tsumo:
+		return 20
+	case pinfu:
+		return 30
+	}
+	fu := 20
+	if s.closed && !s.h.Tsumo {
+		fu += 10
+	}
+	if s.h.Tsumo {
+		fu += 2
+	}
+	for _, g := range s.groups {
+		switch g.kind {
+		case groupTriplet, groupKan:
+			f := 2
+			if !g.open {
+				f *= 2
+			}
+			if isTerminalOrHonor(g.index) {
+				f *= 2
+			}
+			if g.kind == groupKan {
+				f *= 4
+			}
+			fu += f
+		case groupPair:
+			fu += s.pairFu(g.index)
+		}
+	}
+	if s.wait == waitKanchan || s.wait == waitPenchan || s.wait == waitTanki {
+		fu += 2
+	}
+	if fu == 20 {
+		fu = 30 // an open hand with no fu
+	}
+	return (fu + 9) / 10 * 10
+}
+
+func (s *shape) pairFu(i int) int {
+	seat := i == indexEast+int(s.h.SeatWind)
+	round := i == indexEast+int(s.h.RoundWind)
+	switch {
+	case i >= indexHaku:
+		return 2
+	case seat && round:
+		return s.rules.DoubleWindFu
+	case seat || round:
+		return 2
+	}
+	return 0
+}
+
+func isChiitoi(c *engine.TileCounts) bool {
+	pairs := 0
+	for _, n := range c.Kinds {
+		switch n {
+		case 0:
+		case 2:
+			pairs++
+		default:
+			return false
+		}
+	}
+	return pairs == 7
+}
+
+func scoreChiitoi(h engine.Hand, rules engine.Rules, c *engine.TileCounts) (Result, error) {
+	tiles := h.Tiles()
+	if !slices.ContainsFunc(c.Kinds[:27], func(n uint8) bool { return n > 0 }) {
+		return finish(h, rules, []engine.YakuHan{{Yaku: engine.YakuTsuuiisou, Han: 13}}, 0, tiles)
+	}
+	yaku := situational(h, true)
+	yaku = append(yaku, engine.YakuHan{Yaku: engine.YakuChiitoitsu, Han: 2})
+	if allSimples(c.Kinds) {
+		yaku = append(yaku, engine.YakuHan{Yaku: engine.YakuTanyao, Han: 1})
+	}
+	outside := true
+	for i, n := range c.Kinds {
+		outside = outside && (n == 0 || isTerminalOrHonor(i))
+	}
+	if outside {
+		yaku = append(yaku, engine.YakuHan{Yaku: engine.YakuHonroutou, Han: 2})
+	}
+	yaku = append(yaku, flush(c.Kinds, true)...)
+	return finish(h, rules, yaku, 25, tiles)
+}
+
+func isKokushi(c *engine.TileCounts) bool {
+	pair := false
+	for i, n := range c.Kinds {
+		switch {
+		case !isTerminalOrHonor(i) && n > 0, isTerminalOrHonor(i) && n == 0:
+			return false
+		case n == 2:
+			if pair {
+				return false
+			}
+			pair = true
+		case n > 2:
+			return false
+		}
+	}
+	return pair
+}
+
+func scoreKokushi(h engine.Hand, rules engine.Rules, c *engine.TileCounts) (Result, error) {
+	y := engine.YakuKokushi
+	if c.Kinds[h.Winning.Index()] == 2 {
+		y = engine.YakuKokushi13 // all thirteen were waiting
+	}
+	return finish(h, rules, []engine.YakuHan{{Yaku: y, Han: 13}}, 0, h.Tiles())
+}
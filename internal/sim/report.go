@@ -0,0 +1,152 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// playerJSON is how an entrant's Stats are written out, rates included.
+type playerJSON struct {
+	Name         string             `json:"name"`
+	Games        int                `json:"games"`
+	Rounds       int                `json:"rounds"`
+	Placements   [4]int             `json:"placements"`
+	AvgPlacement float64            `json:"avg_placement"`
+	AvgScore     float64            `json:"avg_score"`
+	WinRate      float64            `json:"win_rate"`
+	DealInRate   float64            `json:"deal_in_rate"`
+	CallRate     float64            `json:"call_rate"`
+	RiichiRate   float64            `json:"riichi_rate"`
+	AvgWinPoints float64            `json:"avg_win_points"`
+	Defaults     int                `json:"defaults"`
+	Yaku         map[string]float64 `json:"yaku"` // share of wins with each yaku
+}
+
+// WriteJSON writes the report as one indented JSON object.
+func WriteJSON(w io.Writer, rep *Report) error {
+	out := struct {
+		Games   int          `json:"games"`
+		Seed    uint64       `json:"seed"`
+		Players []playerJSON `json:"players"`
+	}{Games: rep.Games, Seed: rep.Seed}
+	for _, s := range rep.Players {
+		p := playerJSON{
+			Name:         s.Name,
+			Games:        s.Games,
+			Rounds:       s.Rounds,
+			Placements:   s.Placements,
+			AvgPlacement: s.AvgPlacement(),
+			AvgScore:     s.AvgScore(),
+			WinRate:      s.WinRate(),
+			DealInRate:   s.DealInRate(),
+			CallRate:     s.CallRate(),
+			RiichiRate:   s.RiichiRate(),
+			AvgWinPoints: s.AvgWinPoints(),
+			Defaults:     s.Defaults,
+			Yaku:         map[string]float64{},
+		}
+		for y := range s.Yaku {
+			p.Yaku[y.String()] = s.YakuRate(y)
+		}
+		out.Players = append(out.Players, p)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// columns are the fixed CSV columns; a column per yaku seen follows.
+var columns = []string{
+	"name", "games", "rounds", "first", "second", "third", "fourth",
+	"avg_placement", "avg_score", "win_rate", "deal_in_rate", "call_rate",
+	"riichi_rate", "avg_win_points", "defaults",
+}
+
+// WriteCSV writes a header and one row per entrant. Yaku columns hold
+// the share of the entrant's wins with that yaku.
+func WriteCSV(w io.Writer, rep *Report) error {
+	yaku := yakuSeen(rep)
+	header := slices.Clone(columns)
+	for _, y := range yaku {
+		header = append(header, y.String())
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range rep.Players {
+		row := []string{
+			s.Name, strconv.Itoa(s.Games), strconv.Itoa(s.Rounds),
+			strconv.Itoa(s.Placements[0]), strconv.Itoa(s.Placements[1]),
+			strconv.Itoa(s.Placements[2]), strconv.Itoa(s.Placements[3]),
+			float(s.AvgPlacement()), float(s.AvgScore()), float(s.WinRate()),
+			float(s.DealInRate()), float(s.CallRate()), float(s.RiichiRate()),
+			float(s.AvgWinPoints()), strconv.Itoa(s.Defaults),
+		}
+		for _, y := range yaku {
+			row = append(row, float(s.YakuRate(y)))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func float(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// WriteText writes the report as aligned tables for a terminal.
+func WriteText(w io.Writer, rep *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%d games, seed %d\n\n", rep.Games, rep.Seed)
+	fmt.Fprintln(tw, "player\t1st\t2nd\t3rd\t4th\tavg place\tavg score\twin\tdeal-in\tcall\triichi\tavg win\t")
+	for _, s := range rep.Players {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.2f\t%.0f\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.0f\t\n",
+			s.Name,
+			100*ratio(s.Placements[0], s.Games), 100*ratio(s.Placements[1], s.Games),
+			100*ratio(s.Placements[2], s.Games), 100*ratio(s.Placements[3], s.Games),
+			s.AvgPlacement(), s.AvgScore(), 100*s.WinRate(), 100*s.DealInRate(),
+			100*s.CallRate(), 100*s.RiichiRate(), s.AvgWinPoints())
+	}
+
+	yaku := yakuSeen(rep)
+	if len(yaku) > 0 {
+		fmt.Fprint(tw, "\nyaku per win")
+		for _, s := range rep.Players {
+			fmt.Fprintf(tw, "\t%s", s.Name)
+		}
+		fmt.Fprintln(tw, "\t")
+		for _, y := range yaku {
+			fmt.Fprint(tw, y)
+			for _, s := range rep.Players {
+				fmt.Fprintf(tw, "\t%.1f%%", 100*s.YakuRate(y))
+			}
+			fmt.Fprintln(tw, "\t")
+		}
+	}
+	return tw.Flush()
+}
+
+// yakuSeen lists the yaku any entrant won with, in yaku order.
+func yakuSeen(rep *Report) []engine.Yaku {
+	var yaku []engine.Yaku
+	for _, s := range rep.Players {
+		for y := range s.Yaku {
+			if !slices.Contains(yaku, y) {
+				yaku = append(yaku, y)
+			}
+		}
+	}
+	slices.Sort(yaku)
+	return yaku
+}
@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func testReport() *Report {
+	rep := &Report{Games: 2, Seed: 9}
+	for e, name := range []string{"a", "b", "c", "d"} {
+		rep.Players[e] = Stats{Name: name, Games: 2, Rounds: 10, Yaku: map[engine.Yaku]int{}}
+		rep.Players[e].Placements[e%4] = 2
+	}
+	rep.Players[0].Wins = 4
+	rep.Players[0].WinPoints = 10000
+	rep.Players[0].Yaku[engine.YakuRiichi] = 3
+	return rep
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || len(rows[0]) != len(columns)+1 || rows[0][len(columns)] != "riichi" {
+		t.Fatalf("rows = %q", rows)
+	}
+	want := map[string]string{"name": "a", "first": "2", "win_rate": "0.4000", "avg_win_points": "2500.0000", "riichi": "0.7500"}
+	for i, col := range rows[0] {
+		if v, ok := want[col]; ok && rows[1][i] != v {
+			t.Errorf("%s = %q, want %q", col, rows[1][i], v)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Games   int          `json:"games"`
+		Players []playerJSON `json:"players"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	a := out.Players[0]
+	if out.Games != 2 || len(out.Players) != 4 || a.AvgPlacement != 1 || a.WinRate != 0.4 || a.Yaku["riichi"] != 0.75 {
+		t.Errorf("decoded %+v", out)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"2 games, seed 9", "100.0%", "riichi", "75.0%"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("report lacks %q:\n%s", s, buf.String())
+		}
+	}
+}
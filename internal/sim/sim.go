@@ -0,0 +1,139 @@
+// Package sim plays many games between four entrants and gathers the
+// statistics used to compare and tune them.
+package sim
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Entrant is one of the four competitors.
+type Entrant struct {
+	Name string
+	// New makes the entrant's player for one game. A player that is an
+	// io.Closer is closed when its game ends.
+	New func() (table.Player, error)
+}
+
+// Config sets up a simulation.
+type Config struct {
+	Games   int
+	Workers int    // games played at once; 0 means GOMAXPROCS
+	Seed    uint64 // the walls of every game are drawn from Seed
+	Rules   engine.Rules
+	Timeout time.Duration // per decision, as in table.Config
+}
+
+// Report is the outcome of a simulation, one Stats per entrant in the
+// order they were given.
+type Report struct {
+	Games   int
+	Seed    uint64
+	Players [4]Stats
+}
+
+// Run plays cfg.Games games. Entrants rotate seats from game to game so
+// each deals first equally often: in game g entrant e sits in seat
+// (e+g)%4. Game g's walls come from cfg.Seed and g alone, so with
+// deterministic players (and no timeouts) a run can be repeated exactly,
+// whatever the number of workers.
+func Run(ctx context.Context, entrants [4]Entrant, cfg Config) (*Report, error) {
+	if cfg.Games <= 0 {
+		return nil, fmt.Errorf("invalid number of games %d", cfg.Games)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	games := make([][4]Stats, cfg.Games)
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range min(cmp.Or(cfg.Workers, runtime.GOMAXPROCS(0)), cfg.Games) {
+		wg.Go(func() {
+			for g := range jobs {
+				stats, err := play(ctx, entrants, cfg, g)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("game %d: %w", g, err)
+						cancel()
+					})
+					continue
+				}
+				games[g] = stats
+			}
+		})
+	}
+feed:
+	for g := range cfg.Games {
+		select {
+		case jobs <- g:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rep := &Report{Games: cfg.Games, Seed: cfg.Seed}
+	for e := range rep.Players {
+		rep.Players[e].Name = entrants[e].Name
+		rep.Players[e].Yaku = map[engine.Yaku]int{}
+	}
+	for _, stats := range games {
+		for e := range rep.Players {
+			rep.Players[e].merge(&stats[e])
+		}
+	}
+	return rep, nil
+}
+
+// play runs game g and returns each entrant's share of it.
+func play(ctx context.Context, entrants [4]Entrant, cfg Config, g int) ([4]Stats, error) {
+	var players [4]table.Player
+	var names [4]string
+	defer func() {
+		for _, p := range players {
+			if c, ok := p.(io.Closer); ok {
+				c.Close()
+			}
+		}
+	}()
+	for e, en := range entrants {
+		seat := (e + g) % 4
+		p, err := en.New()
+		if err != nil {
+			return [4]Stats{}, fmt.Errorf("%s: %w", en.Name, err)
+		}
+		players[seat], names[seat] = p, en.Name
+	}
+
+	seed := rand.New(rand.NewPCG(cfg.Seed, uint64(g))).Uint64()
+	res, err := table.Play(ctx, players, table.Config{Names: names, Rules: cfg.Rules, Seed: seed, Timeout: cfg.Timeout})
+	if err != nil {
+		return [4]Stats{}, err
+	}
+	bySeat := gameStats(res)
+	var stats [4]Stats
+	for e := range stats {
+		stats[e] = bySeat[(e+g)%4]
+	}
+	return stats, nil
+}
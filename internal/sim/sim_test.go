@@ -0,0 +1,122 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/bot"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+func bots() [4]Entrant {
+	var es [4]Entrant
+	for i, name := range []string{"a", "b", "c", "d"} {
+		es[i] = Entrant{Name: name, New: func() (table.Player, error) {
+			return table.Local(bot.Bot{}), nil
+		}}
+	}
+	return es
+}
+
+func eastRules() engine.Rules {
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	return rules
+}
+
+func TestRun(t *testing.T) {
+	cfg := Config{Games: 6, Workers: 1, Seed: 5, Rules: eastRules()}
+	rep, err := Run(context.Background(), bots(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	places, score, rounds := [4]int{}, 0, rep.Players[0].Rounds
+	for e, s := range rep.Players {
+		if s.Name != bots()[e].Name || s.Games != 6 || s.Rounds != rounds {
+			t.Errorf("entrant %d: %s, %d games, %d rounds", e, s.Name, s.Games, s.Rounds)
+		}
+		for i, n := range s.Placements {
+			places[i] += n
+		}
+		score += s.Score
+	}
+	if places != [4]int{6, 6, 6, 6} {
+		t.Errorf("placements add up to %v", places)
+	}
+	if score != 6*4*cfg.Rules.StartingPoints {
+		t.Errorf("scores add up to %d", score)
+	}
+
+	cfg.Workers = 3
+	again, err := Run(context.Background(), bots(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rep, again) {
+		t.Error("the same seed gave a different report with more workers")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	es := bots()
+	broken := errors.New("no binary")
+	es[2].New = func() (table.Player, error) { return nil, broken }
+	if _, err := Run(context.Background(), es, Config{Games: 3, Rules: eastRules()}); !errors.Is(err, broken) {
+		t.Errorf("err = %v, want %v", err, broken)
+	}
+	if _, err := Run(context.Background(), bots(), Config{}); err == nil {
+		t.Error("zero games accepted")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, bots(), Config{Games: 3, Rules: eastRules()}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestGameStats(t *testing.T) {
+	m, _ := engine.ParseTile("1m")
+	pon := &engine.Meld{Kind: engine.MeldPon, Tiles: []engine.Tile{m, m, m}, Called: m, From: 0}
+	ankan := &engine.Meld{Kind: engine.MeldAnkan, Tiles: []engine.Tile{m, m, m, m}, Called: m, From: 3}
+	res := &table.Result{
+		Scores:   [4]int{30000, 20000, 25000, 25000},
+		Defaults: [4]int{0, 0, 2, 0},
+		Events: []engine.Event{
+			{Type: engine.EventRoundStart},
+			{Type: engine.EventCall, Seat: 1, Meld: pon},
+			{Type: engine.EventCall, Seat: 1, Meld: pon},
+			{Type: engine.EventRiichiAccepted, Seat: 0},
+			{Type: engine.EventWin, Win: &engine.WinResult{Seat: 0, From: 1, Points: 8000, Yaku: []engine.YakuHan{{Yaku: engine.YakuRiichi, Han: 1}, {Yaku: engine.YakuDora, Han: 2}}}},
+			{Type: engine.EventWin, Win: &engine.WinResult{Seat: 2, From: 1, Points: 1000, Yaku: []engine.YakuHan{{Yaku: engine.YakuTanyao, Han: 1}}}},
+			{Type: engine.EventRoundStart},
+			{Type: engine.EventCall, Seat: 3, Meld: ankan},
+			{Type: engine.EventWin, Win: &engine.WinResult{Seat: 3, From: 3, Points: 2000}},
+		},
+	}
+	stats := gameStats(res)
+	tests := []struct {
+		seat                                        int
+		place, wins, dealIns, calls, riichi, points int
+	}{
+		{0, 0, 1, 0, 0, 1, 8000},
+		{1, 3, 0, 1, 1, 0, 0},
+		{2, 1, 1, 0, 0, 0, 1000},
+		{3, 2, 1, 0, 0, 0, 2000}, // a closed kan is not a call
+	}
+	for _, tt := range tests {
+		s := stats[tt.seat]
+		if s.Rounds != 2 || s.Placements[tt.place] != 1 || s.Wins != tt.wins || s.DealIns != tt.dealIns ||
+			s.Calls != tt.calls || s.Riichi != tt.riichi || s.WinPoints != tt.points {
+			t.Errorf("seat %d: %+v", tt.seat, s)
+		}
+	}
+	if got := stats[0].Yaku; !reflect.DeepEqual(got, map[engine.Yaku]int{engine.YakuRiichi: 1}) {
+		t.Errorf("seat 0 yaku %v, want riichi without dora", got)
+	}
+	if stats[2].Defaults != 2 {
+		t.Errorf("seat 2 defaults %d", stats[2].Defaults)
+	}
+}
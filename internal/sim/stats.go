@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// Stats are one entrant's counts over a simulation. Rates are per round
+// unless noted.
+type Stats struct {
+	Name       string
+	Games      int
+	Rounds     int
+	Placements [4]int // games finished in each place, first to fourth
+	Score      int    // final scores added up
+
+	Wins      int
+	DealIns   int // rounds in which their discard was ronned
+	Calls     int // rounds in which they called chi, pon or an open kan
+	Riichi    int // rounds in which their riichi was accepted
+	WinPoints int // points of their wins added up, before honba and sticks
+	Defaults  int // decisions the table made for them after a timeout or error
+
+	Yaku map[engine.Yaku]int // wins with each yaku, dora left out
+}
+
+func (s *Stats) AvgScore() float64 { return ratio(s.Score, s.Games) }
+
+// AvgPlacement is the mean place, from 1 to 4.
+func (s *Stats) AvgPlacement() float64 {
+	sum := 0
+	for i, n := range s.Placements {
+		sum += (i + 1) * n
+	}
+	return ratio(sum, s.Games)
+}
+
+func (s *Stats) WinRate() float64      { return ratio(s.Wins, s.Rounds) }
+func (s *Stats) DealInRate() float64   { return ratio(s.DealIns, s.Rounds) }
+func (s *Stats) CallRate() float64     { return ratio(s.Calls, s.Rounds) }
+func (s *Stats) RiichiRate() float64   { return ratio(s.Riichi, s.Rounds) }
+func (s *Stats) AvgWinPoints() float64 { return ratio(s.WinPoints, s.Wins) }
+
+// YakuRate is the share of their wins with yaku y.
+func (s *Stats) YakuRate(y engine.Yaku) float64 { return ratio(s.Yaku[y], s.Wins) }
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func (s *Stats) merge(o *Stats) {
+	s.Games += o.Games
+	s.Rounds += o.Rounds
+	for i := range s.Placements {
+		s.Placements[i] += o.Placements[i]
+	}
+	s.Score += o.Score
+	s.Wins += o.Wins
+	s.DealIns += o.DealIns
+	s.Calls += o.Calls
+	s.Riichi += o.Riichi
+	s.WinPoints += o.WinPoints
+	s.Defaults += o.Defaults
+	for y, n := range o.Yaku {
+		s.Yaku[y] += n
+	}
+}
+
+// gameStats counts what each seat did in a finished game.
+func gameStats(res *table.Result) [4]Stats {
+	var stats [4]Stats
+	for s := range stats {
+		stats[s].Games = 1
+		stats[s].Score = res.Scores[s]
+		stats[s].Defaults = res.Defaults[s]
+		stats[s].Yaku = map[engine.Yaku]int{}
+	}
+	for place, s := range res.Placement() {
+		stats[s].Placements[place]++
+	}
+
+	// Calls and deal-ins are counted once per round, however many there
+	// were.
+	var called, dealtIn [4]bool
+	endRound := func() {
+		for s := range stats {
+			if called[s] {
+				stats[s].Calls++
+			}
+			if dealtIn[s] {
+				stats[s].DealIns++
+			}
+		}
+		called, dealtIn = [4]bool{}, [4]bool{}
+	}
+	for _, e := range res.Events {
+		switch e.Type {
+		case engine.EventRoundStart:
+			endRound()
+			for s := range stats {
+				stats[s].Rounds++
+			}
+		case engine.EventCall:
+			if e.Meld.IsOpen() {
+				called[e.Seat] = true
+			}
+		case engine.EventRiichiAccepted:
+			stats[e.Seat].Riichi++
+		case engine.EventWin:
+			w := e.Win
+			st := &stats[w.Seat]
+			st.Wins++
+			st.WinPoints += w.Points
+			for _, y := range w.Yaku {
+				if !y.Yaku.IsDora() {
+					st.Yaku[y.Yaku]++
+				}
+			}
+			if w.From != w.Seat {
+				dealtIn[w.From] = true
+			}
+		}
+	}
+	endRound()
+	return stats
+}
@@ -0,0 +1,266 @@
+// Package table plays riichi games between players: it deals seeded
+// walls, asks each seat for its decisions and records every step as
+// engine events, checked by the engine as they happen.
+package table
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// ActionType is the kind of a decision.
+type ActionType uint8
+
+const (
+	ActionDiscard ActionType = iota // discard Tile
+	ActionRiichi                    // declare riichi and discard Tile
+	ActionTsumo                     // win on the tile just drawn
+	ActionKan                       // closed kan or added kan, Meld
+	ActionPass                      // let a discard go
+	ActionRon                       // win on a discard
+	ActionChi                       // Meld
+	ActionPon                       // Meld
+	ActionOpenKan                   // kan on a discard, Meld
+)
+
+func (t ActionType) String() string {
+	switch t {
+	case ActionDiscard:
+		return "discard"
+	case ActionRiichi:
+		return "riichi"
+	case ActionTsumo:
+		return "tsumo"
+	case ActionKan:
+		return "kan"
+	case ActionPass:
+		return "pass"
+	case ActionRon:
+		return "ron"
+	case ActionChi:
+		return "chi"
+	case ActionPon:
+		return "pon"
+	case ActionOpenKan:
+		return "open_kan"
+	default:
+		return "?"
+	}
+}
+
+func (t ActionType) MarshalText() ([]byte, error) {
+	if t > ActionOpenKan {
+		return nil, fmt.Errorf("unknown action type %d", t)
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *ActionType) UnmarshalText(text []byte) error {
+	for v := ActionDiscard; v <= ActionOpenKan; v++ {
+		if string(text) == v.String() {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action type %q", text)
+}
+
+// Action is one decision. Tile is set for discards, Meld for calls and
+// kans.
+type Action struct {
+	Type ActionType
+	Tile engine.Tile
+	Meld *engine.Meld
+}
+
+func (a Action) String() string {
+	switch {
+	case a.Meld != nil:
+		return fmt.Sprintf("%s %s", a.Type, a.Meld)
+	case a.Type == ActionDiscard || a.Type == ActionRiichi:
+		return fmt.Sprintf("%s %s", a.Type, a.Tile)
+	default:
+		return a.Type.String()
+	}
+}
+
+// actionJSON is the wire form of an Action. Tile is written only for
+// discards, as the zero Tile is a real tile.
+type actionJSON struct {
+	Type ActionType   `json:"type"`
+	Tile *engine.Tile `json:"tile,omitempty"`
+	Meld *engine.Meld `json:"meld,omitempty"`
+}
+
+func (a Action) MarshalJSON() ([]byte, error) {
+	out := actionJSON{Type: a.Type, Meld: a.Meld}
+	if a.Type == ActionDiscard || a.Type == ActionRiichi {
+		out.Tile = &a.Tile
+	}
+	return json.Marshal(out)
+}
+
+func (a *Action) UnmarshalJSON(data []byte) error {
+	var in actionJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*a = Action{Type: in.Type, Meld: in.Meld}
+	if in.Tile != nil {
+		a.Tile = *in.Tile
+	}
+	return nil
+}
+
+// Equal reports whether a and b are the same decision.
+func (a Action) Equal(b Action) bool {
+	if a.Type != b.Type || a.Tile != b.Tile || (a.Meld == nil) != (b.Meld == nil) {
+		return false
+	}
+	if a.Meld == nil {
+		return true
+	}
+	m, n := a.Meld, b.Meld
+	return m.Kind == n.Kind && m.Called == n.Called && m.From == n.From && slices.Equal(m.Tiles, n.Tiles)
+}
+
+// View is what one seat knows when it decides.
+type View struct {
+	Seat   int          `json:"seat"`
+	Rules  engine.Rules `json:"rules"`
+	Scores [4]int       `json:"scores"`
+
+	// Round is a copy of the round with the other seats' hands left out.
+	Round *engine.Round `json:"round"`
+
+	// Drawn is the tile just drawn on a turn, nil after a call. Offer is
+	// the tile another seat discarded (or added to a kan) when a call is
+	// asked for.
+	Drawn *engine.Tile `json:"drawn,omitempty"`
+	Offer *engine.Tile `json:"offer,omitempty"`
+}
+
+// NewView copies r as seat sees it, leaving out the other seats' hands.
+// It is how the table builds its views, and lets replayed games be seen
+// the same way.
+func NewView(r *engine.Round, seat int, rules engine.Rules, scores [4]int, drawn, offer *engine.Tile) *View {
+	c := *r
+	c.DoraIndicators = slices.Clone(r.DoraIndicators)
+	for s := range 4 {
+		c.Hands[s] = nil
+		c.Melds[s] = slices.Clone(r.Melds[s])
+		c.Discards[s] = slices.Clone(r.Discards[s])
+	}
+	c.Hands[seat] = slices.Clone(r.Hands[seat])
+	return &View{Seat: seat, Rules: rules, Scores: scores, Round: &c, Drawn: drawn, Offer: offer}
+}
+
+// Player makes the decisions of one seat. Each method is given every
+// legal action and must return one of them before ctx is done. If it
+// returns an error instead, or misses the deadline, the table plays the
+// default action for it: the tile just drawn, or the first discard after
+// a call, and a pass on calls.
+type Player interface {
+	// Turn is asked after a draw or a call: discard, declare riichi
+	// with a discard, win by tsumo or declare a kan.
+	Turn(ctx context.Context, v *View, legal []Action) (Action, error)
+	// Call is asked when another seat's tile can be claimed: ron, pon,
+	// chi, open kan or pass.
+	Call(ctx context.Context, v *View, legal []Action) (Action, error)
+}
+
+// Observer is a player that follows the game as it is played, such as a
+// bot speaking a protocol that streams events. Play calls Observe with the
+// player's seat for every event, before the next decision is asked for.
+// The events are not masked: an Observer must only pass on what its seat
+// may see. Observe must not block; work it needs to do can wait for the
+// next decision's deadline.
+type Observer interface {
+	Player
+	Observe(seat int, e engine.Event)
+}
+
+// Agent is a player that decides in process without a deadline, such as
+// the bots in package bot. Local seats it at the table.
+type Agent interface {
+	Turn(v *View, legal []Action) Action
+	Call(v *View, legal []Action) Action
+}
+
+// Local adapts an in-process agent to Player. Each decision runs on its
+// own goroutine so a slow agent cannot hold the table past a deadline;
+// the agent is still never asked twice at once, and a decision that
+// comes in late is dropped.
+func Local(a Agent) Player {
+	return &local{agent: a, busy: make(chan struct{}, 1)}
+}
+
+type local struct {
+	agent Agent
+	busy  chan struct{} // held while the agent decides
+}
+
+func (l *local) Turn(ctx context.Context, v *View, legal []Action) (Action, error) {
+	return l.decide(ctx, func() Action { return l.agent.Turn(v, legal) })
+}
+
+func (l *local) Call(ctx context.Context, v *View, legal []Action) (Action, error) {
+	return l.decide(ctx, func() Action { return l.agent.Call(v, legal) })
+}
+
+func (l *local) decide(ctx context.Context, f func() Action) (Action, error) {
+	select {
+	case l.busy <- struct{}{}:
+	case <-ctx.Done():
+		return Action{}, ctx.Err()
+	}
+	done := make(chan Action, 1)
+	go func() {
+		defer func() { <-l.busy }()
+		done <- f()
+	}()
+	select {
+	case a := <-done:
+		return a, nil
+	case <-ctx.Done():
+		return Action{}, ctx.Err()
+	}
+}
+
+// DefaultAction is what the table plays for a seat that does not answer
+// in time: a pass when offered a tile, otherwise a discard of the tile
+// just drawn, or of the first tile in hand after a call. With no discard
+// to make it passes if it may, or plays the first legal action.
+func DefaultAction(v *View, legal []Action) Action {
+	if v.Offer != nil {
+		return Action{Type: ActionPass}
+	}
+	first := -1
+	for i, a := range legal {
+		if a.Type != ActionDiscard {
+			continue
+		}
+		if v.Drawn != nil && a.Tile == *v.Drawn {
+			return a
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		return legal[first]
+	}
+	for _, a := range legal {
+		if a.Type == ActionPass {
+			return a
+		}
+	}
+	if len(legal) == 0 {
+		return Action{Type: ActionPass}
+	}
+	return legal[0]
+}
@@ -0,0 +1,139 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/jsonl"
+)
+
+// Conn is a player at the other end of a JSON lines stream: a
+// subprocess's stdin and stdout, or a network connection. Each decision
+// is one request line,
+//
+//	{"id":1,"type":"turn","view":{...},"legal":[{"type":"discard","tile":"5m"},...]}
+//
+// of type "turn" or "call", answered by one line that picks an action by
+// its index in legal:
+//
+//	{"id":1,"choice":0}
+//
+// A reply that comes after its deadline is skipped by its id.
+type Conn struct {
+	Name string
+
+	mu   sync.Mutex // one request at a time
+	s    *jsonl.Stream
+	next int
+}
+
+type request struct {
+	ID    int      `json:"id"`
+	Type  string   `json:"type"`
+	View  *View    `json:"view"`
+	Legal []Action `json:"legal"`
+}
+
+type reply struct {
+	ID     int `json:"id"`
+	Choice int `json:"choice"`
+}
+
+// NewConn plays over a reader and writer, e.g. a subprocess's stdout and
+// stdin. closer, if not nil, is called by Close.
+func NewConn(r io.Reader, w io.Writer, closer func() error) *Conn {
+	return &Conn{s: jsonl.New(r, w, closer)}
+}
+
+func (c *Conn) Turn(ctx context.Context, v *View, legal []Action) (Action, error) {
+	return c.ask(ctx, "turn", v, legal)
+}
+
+func (c *Conn) Call(ctx context.Context, v *View, legal []Action) (Action, error) {
+	return c.ask(ctx, "call", v, legal)
+}
+
+func (c *Conn) ask(ctx context.Context, typ string, v *View, legal []Action) (Action, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next++
+	if err := c.s.Send(request{ID: c.next, Type: typ, View: v, Legal: legal}); err != nil {
+		return Action{}, fmt.Errorf("player %q: send %s: %w", c.Name, typ, err)
+	}
+	lines := c.s.Lines()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return Action{}, fmt.Errorf("player %q: reply to %s: %w", c.Name, typ, c.s.Err())
+			}
+			var rep reply
+			if err := jsonl.Decode(line, &rep); err != nil {
+				return Action{}, fmt.Errorf("player %q: %w", c.Name, err)
+			}
+			if rep.ID != c.next {
+				continue // a late reply to an earlier request
+			}
+			if rep.Choice < 0 || rep.Choice >= len(legal) {
+				return Action{}, fmt.Errorf("player %q: choice %d out of %d actions", c.Name, rep.Choice, len(legal))
+			}
+			return legal[rep.Choice], nil
+		case <-ctx.Done():
+			return Action{}, ctx.Err()
+		}
+	}
+}
+
+// Close hangs up on the player.
+func (c *Conn) Close() error {
+	return c.s.Close()
+}
+
+// StartProcess launches a player that speaks the Conn protocol on stdin
+// and stdout.
+func StartProcess(name string, args ...string) (*Conn, error) {
+	s, err := jsonl.Start(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Name: name, s: s}, nil
+}
+
+// Server accepts players that speak the Conn protocol over TCP.
+type Server struct {
+	*jsonl.Server
+}
+
+// Listen starts a server for players on addr.
+func Listen(addr string) (*Server, error) {
+	s, err := jsonl.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{s}, nil
+}
+
+// Accept waits for a player to connect and introduce itself with a line
+// such as {"type":"join","name":"alice"}.
+func (s *Server) Accept() (*Conn, error) {
+	st, err := s.Server.Accept()
+	if err != nil {
+		return nil, err
+	}
+	var join struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if err := st.Recv(&join); err != nil {
+		st.Close()
+		return nil, err
+	}
+	if join.Type != "join" {
+		st.Close()
+		return nil, fmt.Errorf("expected join, got %q", join.Type)
+	}
+	return &Conn{Name: join.Name, s: st}, nil
+}
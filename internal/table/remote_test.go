@@ -0,0 +1,152 @@
+package table
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// pipeConn returns a Conn and the client end of its stream.
+func pipeConn(t *testing.T) (*Conn, *bufio.Reader, io.Writer) {
+	t.Helper()
+	toClient, fromTable := io.Pipe()
+	fromClient, toTable := io.Pipe()
+	c := NewConn(fromClient, fromTable, func() error {
+		fromTable.Close()
+		return toTable.Close()
+	})
+	t.Cleanup(func() { c.Close() })
+	return c, bufio.NewReader(toClient), toTable
+}
+
+func readRequest(t *testing.T, r *bufio.Reader) request {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Error(err)
+		return request{}
+	}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		t.Errorf("request %q: %v", line, err)
+	}
+	return req
+}
+
+func TestConn(t *testing.T) {
+	c, r, w := pipeConn(t)
+	p, _ := engine.ParseTile("5p")
+	m, _ := engine.ParseTile("1m")
+	legal := []Action{{Type: ActionDiscard, Tile: m}, {Type: ActionRiichi, Tile: p}}
+	v := &View{Seat: 2, Round: &engine.Round{TilesLeft: 30}, Drawn: &p}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first := readRequest(t, r)
+		second := readRequest(t, r)
+		if first.Type != "turn" || first.View.Seat != 2 || *first.View.Drawn != p || !reflect.DeepEqual(first.Legal, legal) {
+			t.Errorf("request = %+v", first)
+		}
+		// Answer the first request after its deadline, then the second.
+		fmt.Fprintf(w, "{\"id\":%d,\"choice\":0}\n{\"id\":%d,\"choice\":1}\n", first.ID, second.ID)
+		readRequest(t, r)
+		fmt.Fprintln(w, `{"id":3,"choice":7}`)
+		readRequest(t, r)
+		fmt.Fprintln(w, `not json`)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Turn(ctx, v, legal); err == nil {
+		t.Error("no error past the deadline")
+	}
+	a, err := c.Turn(context.Background(), v, legal)
+	if err != nil || !a.Equal(legal[1]) {
+		t.Errorf("got %s, %v; want %s", a, err, legal[1])
+	}
+	if _, err := c.Call(context.Background(), v, legal); err == nil {
+		t.Error("out of range choice accepted")
+	}
+	if _, err := c.Call(context.Background(), v, legal); err == nil {
+		t.Error("invalid reply accepted")
+	}
+	<-done
+}
+
+func TestConnClosed(t *testing.T) {
+	c, r, w := pipeConn(t)
+	go func() {
+		readRequest(t, r)
+		w.(io.Closer).Close()
+	}()
+	if _, err := c.Turn(context.Background(), &View{Round: &engine.Round{}}, []Action{{}}); err == nil {
+		t.Error("no error from a closed stream")
+	}
+}
+
+func TestServer(t *testing.T) {
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	go func() {
+		nc, err := net.Dial("tcp", s.Addr().String())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer nc.Close()
+		fmt.Fprintln(nc, `{"type":"join","name":"alice"}`)
+		r := bufio.NewReader(nc)
+		req := readRequest(t, r)
+		fmt.Fprintf(nc, "{\"id\":%d,\"choice\":0}\n", req.ID)
+		r.ReadBytes('\n') // wait for the table to hang up
+	}()
+
+	c, err := s.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Name != "alice" {
+		t.Errorf("Name = %q", c.Name)
+	}
+	a, err := c.Call(context.Background(), &View{Round: &engine.Round{}}, []Action{{Type: ActionPass}})
+	if err != nil || a.Type != ActionPass {
+		t.Errorf("got %s, %v", a, err)
+	}
+}
+
+func TestActionJSON(t *testing.T) {
+	m, _ := engine.ParseTile("1m")
+	for _, a := range []Action{
+		{Type: ActionDiscard, Tile: m},
+		{Type: ActionPass},
+		{Type: ActionPon, Meld: &engine.Meld{Kind: engine.MeldPon, Tiles: []engine.Tile{m, m, m}, Called: m, From: 1}},
+	} {
+		data, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b Action
+		if err := json.Unmarshal(data, &b); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if !a.Equal(b) {
+			t.Errorf("%s decoded as %s", data, b)
+		}
+	}
+	if data, _ := json.Marshal(Action{Type: ActionPass}); string(data) != `{"type":"pass"}` {
+		t.Errorf("pass = %s", data)
+	}
+}
@@ -0,0 +1,603 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/analysis"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/score"
+)
+
+// Wall layout: each seat is dealt handSize tiles and the last deadWall
+// tiles hold the dora indicators, then the ura indicators, then the kan
+// replacement tiles.
+const (
+	handSize      = 13
+	deadWall      = 14
+	maxIndicators = 5
+	uraOffset     = 5
+	rinshanOffset = 10
+	maxKans       = 4
+)
+
+// round is the table's side of one round; the engine's Round holds the
+// hands, melds and rivers.
+type round struct {
+	*game
+	r             *engine.Round
+	wall          []engine.Tile
+	next, liveEnd int // live wall still to draw: wall[next:liveEnd]
+	dead          []engine.Tile
+	kans          int
+	rinshan       bool // the tile in hand came from the dead wall
+	lateDora      int  // open kans whose dora is turned after the next discard
+
+	tempFuriten   [4]bool // passed a ron since their last discard
+	riichiFuriten [4]bool // passed a ron after declaring riichi
+	ippatsu       [4]bool
+	doubleRiichi  [4]bool
+	called        bool // someone has called or declared a kan
+}
+
+// claim is a call chosen by another seat on a discard.
+type claim struct {
+	seat   int
+	action Action
+}
+
+func (gm *game) playRound(deal engine.RoundStart) (roundOutcome, error) {
+	wall, err := engine.BuildWall(gm.rules)
+	if err != nil {
+		return roundOutcome{}, err
+	}
+	gm.rng.Shuffle(len(wall), func(i, j int) { wall[i], wall[j] = wall[j], wall[i] })
+
+	rd := &round{game: gm, wall: wall, next: 4 * handSize, liveEnd: len(wall) - deadWall}
+	rd.dead = wall[rd.liveEnd:]
+	for i := range 4 {
+		seat := (deal.Dealer + i) % 4
+		deal.Hands[seat] = slices.Clone(wall[i*handSize : (i+1)*handSize])
+	}
+	deal.DoraIndicator = rd.dead[0]
+	if err := gm.emit(engine.Event{Type: engine.EventRoundStart, Seat: deal.Dealer, Round: &deal}); err != nil {
+		return roundOutcome{}, err
+	}
+	rd.r = gm.g.Round
+	for i := 1; i < min(gm.rules.StartingDora, maxIndicators); i++ {
+		if err := gm.emit(engine.Event{Type: engine.EventDora, Tile: rd.dead[i]}); err != nil {
+			return roundOutcome{}, err
+		}
+	}
+	return rd.play()
+}
+
+// play runs turns until the round ends.
+func (rd *round) play() (roundOutcome, error) {
+	seat, draw := rd.r.Dealer, true
+	var drawn *engine.Tile
+	for {
+		if draw {
+			if rd.next == rd.liveEnd {
+				return rd.exhaustive()
+			}
+			t := rd.wall[rd.next]
+			rd.next++
+			if err := rd.emit(engine.Event{Type: engine.EventDrawTile, Seat: seat, Tile: t}); err != nil {
+				return roundOutcome{}, err
+			}
+			drawn, rd.rinshan = &t, false
+		}
+
+		a, err := rd.ask(seat, rd.turnActions(seat, drawn), drawn, nil)
+		if err != nil {
+			return roundOutcome{}, err
+		}
+		switch a.Type {
+		case ActionTsumo:
+			return rd.tsumo(seat, *drawn)
+
+		case ActionKan:
+			if err := rd.kan(seat, *a.Meld); err != nil {
+				return roundOutcome{}, err
+			}
+			if a.Meld.Kind == engine.MeldShouminkan {
+				winners, _, err := rd.offer(seat, a.Meld.Called, true)
+				if err != nil {
+					return roundOutcome{}, err
+				}
+				if len(winners) > 0 {
+					return rd.ron(winners, seat, a.Meld.Called, true)
+				}
+			}
+			if drawn, err = rd.replacement(seat); err != nil {
+				return roundOutcome{}, err
+			}
+			draw = false
+
+		default:
+			out, done, c, err := rd.discard(seat, a, drawn)
+			if err != nil || done {
+				return out, err
+			}
+			if c == nil {
+				seat, draw = (seat+1)%4, true
+				continue
+			}
+			seat, draw, drawn = c.seat, false, nil
+			if c.action.Type == ActionOpenKan {
+				if err := rd.kan(seat, *c.action.Meld); err != nil {
+					return roundOutcome{}, err
+				}
+				if drawn, err = rd.replacement(seat); err != nil {
+					return roundOutcome{}, err
+				}
+			} else if err := rd.emit(engine.Event{Type: engine.EventCall, Seat: seat, Meld: c.action.Meld}); err != nil {
+				return roundOutcome{}, err
+			}
+		}
+	}
+}
+
+// ask offers legal actions to seat and checks the answer. A seat that
+// errs or runs out of time gets the default action.
+func (rd *round) ask(seat int, legal []Action, drawn, offer *engine.Tile) (Action, error) {
+	ctx, cancel := rd.ctx, context.CancelFunc(func() {})
+	if rd.timeout > 0 {
+		ctx, cancel = context.WithTimeout(rd.ctx, rd.timeout)
+	}
+	defer cancel()
+
+	v := rd.view(seat, drawn, offer)
+	var a Action
+	var err error
+	if offer == nil {
+		a, err = rd.players[seat].Turn(ctx, v, legal)
+	} else {
+		a, err = rd.players[seat].Call(ctx, v, legal)
+	}
+	if err != nil {
+		if rd.ctx.Err() != nil {
+			return a, rd.ctx.Err()
+		}
+		rd.defaults[seat]++
+		return DefaultAction(v, legal), nil
+	}
+	if !slices.ContainsFunc(legal, a.Equal) {
+		return a, fmt.Errorf("seat %d chose %s, which is not legal", seat, a)
+	}
+	return a, nil
+}
+
+// view copies the round as seat sees it.
+func (rd *round) view(seat int, drawn, offer *engine.Tile) *View {
+	return NewView(rd.r, seat, rd.rules, rd.g.Scores, drawn, offer)
+}
+
+// turnActions lists what seat may do holding drawn, or nil after a call.
+func (rd *round) turnActions(seat int, drawn *engine.Tile) []Action {
+	hand := rd.r.Hands[seat]
+	var legal []Action
+	if drawn != nil {
+		if _, ok := rd.win(seat, *drawn, true, false); ok {
+			legal = append(legal, Action{Type: ActionTsumo})
+		}
+	}
+	if rd.r.Riichi[seat] {
+		return append(legal, Action{Type: ActionDiscard, Tile: *drawn})
+	}
+
+	if drawn != nil && rd.kans < maxKans && rd.next < rd.liveEnd {
+		for _, k := range kindsOf(hand) {
+			if same := ofKind(hand, k); len(same) == 4 {
+				legal = append(legal, Action{Type: ActionKan, Meld: &engine.Meld{Kind: engine.MeldAnkan, Tiles: same, Called: same[0], From: seat}})
+			}
+		}
+		for _, m := range rd.r.Melds[seat] {
+			if m.Kind != engine.MeldPon {
+				continue
+			}
+			for _, t := range distinct(ofKind(hand, m.Called.Index())) {
+				added := engine.Meld{Kind: engine.MeldShouminkan, Tiles: append(slices.Clone(m.Tiles), t), Called: t, From: m.From}
+				legal = append(legal, Action{Type: ActionKan, Meld: &added})
+			}
+		}
+	}
+
+	discards := distinct(hand)
+	for _, t := range discards {
+		legal = append(legal, Action{Type: ActionDiscard, Tile: t})
+	}
+	closed := !slices.ContainsFunc(rd.r.Melds[seat], engine.Meld.IsOpen)
+	if drawn != nil && closed && rd.g.Scores[seat] >= 1000 && rd.liveEnd-rd.next >= 4 {
+		for _, t := range discards {
+			rest := slices.Clone(hand)
+			rest = slices.Delete(rest, slices.Index(rest, t), slices.Index(rest, t)+1)
+			if c, err := engine.CountTiles(rest); err == nil && analysis.Shanten(&c) == analysis.Tenpai {
+				legal = append(legal, Action{Type: ActionRiichi, Tile: t})
+			}
+		}
+	}
+	return legal
+}
+
+// callActions lists what seat q may do with tile t from seat p.
+func (rd *round) callActions(q, p int, t engine.Tile, chankan bool) []Action {
+	legal := []Action{{Type: ActionPass}}
+	if _, ok := rd.win(q, t, false, chankan); ok {
+		legal = append(legal, Action{Type: ActionRon})
+	}
+	if chankan || rd.r.Riichi[q] || rd.next == rd.liveEnd {
+		return legal
+	}
+
+	hand := rd.r.Hands[q]
+	k := t.Index()
+	meld := func(kind engine.MeldKind, own ...engine.Tile) *engine.Meld {
+		return &engine.Meld{Kind: kind, Tiles: append([]engine.Tile{t}, own...), Called: t, From: p}
+	}
+	same := ofKind(hand, k)
+	for _, pair := range pairs(same) {
+		legal = append(legal, Action{Type: ActionPon, Meld: meld(engine.MeldPon, pair[0], pair[1])})
+	}
+	if len(same) == 3 && rd.kans < maxKans {
+		legal = append(legal, Action{Type: ActionOpenKan, Meld: meld(engine.MeldKan, same...)})
+	}
+	if q == (p+1)%4 && k < 27 {
+		for _, lo := range []int{k - 2, k - 1, k} {
+			if lo < k/9*9 || lo+2 > k/9*9+8 {
+				continue
+			}
+			var others []int
+			for j := lo; j <= lo+2; j++ {
+				if j != k {
+					others = append(others, j)
+				}
+			}
+			for _, a := range distinct(ofKind(hand, others[0])) {
+				for _, b := range distinct(ofKind(hand, others[1])) {
+					legal = append(legal, Action{Type: ActionChi, Meld: meld(engine.MeldChi, a, b)})
+				}
+			}
+		}
+	}
+	return legal
+}
+
+// discard plays a discard (or riichi) and offers the tile to the other
+// seats. It returns the call that takes the tile, if any.
+func (rd *round) discard(seat int, a Action, drawn *engine.Tile) (roundOutcome, bool, *claim, error) {
+	riichi := a.Type == ActionRiichi
+	if riichi {
+		rd.doubleRiichi[seat] = !rd.called && len(rd.r.Discards[seat]) == 0
+		if err := rd.emit(engine.Event{Type: engine.EventRiichi, Seat: seat}); err != nil {
+			return roundOutcome{}, false, nil, err
+		}
+	}
+	tsumogiri := drawn != nil && *drawn == a.Tile
+	if err := rd.emit(engine.Event{Type: engine.EventDiscard, Seat: seat, Tile: a.Tile, Tsumogiri: tsumogiri}); err != nil {
+		return roundOutcome{}, false, nil, err
+	}
+	if err := rd.flipDora(); err != nil {
+		return roundOutcome{}, false, nil, err
+	}
+	rd.ippatsu[seat] = false
+	rd.tempFuriten[seat] = false
+
+	winners, c, err := rd.offer(seat, a.Tile, false)
+	if err != nil {
+		return roundOutcome{}, false, nil, err
+	}
+	if len(winners) > 0 {
+		out, err := rd.ron(winners, seat, a.Tile, false)
+		return out, true, nil, err
+	}
+	if riichi {
+		if err := rd.emit(engine.Event{Type: engine.EventRiichiAccepted, Seat: seat}); err != nil {
+			return roundOutcome{}, false, nil, err
+		}
+		rd.ippatsu[seat] = rd.rules.Ippatsu
+	}
+	if c != nil {
+		rd.called = true
+		rd.ippatsu = [4]bool{}
+	}
+	return roundOutcome{}, false, c, nil
+}
+
+// offer asks the other seats, in turn order, what they do with tile t
+// from seat p. It returns the seats that ron, and otherwise the call that
+// wins: pon or kan before chi.
+func (rd *round) offer(p int, t engine.Tile, chankan bool) ([]int, *claim, error) {
+	var winners []int
+	var c *claim
+	for i := 1; i < 4; i++ {
+		q := (p + i) % 4
+		legal := rd.callActions(q, p, t, chankan)
+		if len(legal) == 1 {
+			continue
+		}
+		a, err := rd.ask(q, legal, nil, &t)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch a.Type {
+		case ActionRon:
+			winners = append(winners, q)
+			continue
+		case ActionPon, ActionOpenKan:
+			if c == nil || c.action.Type == ActionChi {
+				c = &claim{q, a}
+			}
+		case ActionChi:
+			if c == nil {
+				c = &claim{q, a}
+			}
+		}
+		if slices.ContainsFunc(legal, func(a Action) bool { return a.Type == ActionRon }) {
+			rd.tempFuriten[q] = true
+			rd.riichiFuriten[q] = rd.riichiFuriten[q] || rd.r.Riichi[q]
+		}
+	}
+	return winners, c, nil
+}
+
+// kan declares a kan and turns a new dora indicator.
+func (rd *round) kan(seat int, m engine.Meld) error {
+	if err := rd.emit(engine.Event{Type: engine.EventCall, Seat: seat, Meld: &m}); err != nil {
+		return err
+	}
+	rd.kans++
+	rd.called = true
+	rd.ippatsu = [4]bool{}
+	if err := rd.flipDora(); err != nil {
+		return err
+	}
+	rd.lateDora++
+	if m.Kind == engine.MeldAnkan {
+		return rd.flipDora()
+	}
+	return nil
+}
+
+// flipDora turns the indicators of the kans that wait for one. A closed
+// kan's dora is turned at once; an open kan's after the replacement
+// tile's discard, or before the next kan, as on Tenhou and Mahjong Soul,
+// so a robbed kan and a rinshan win do not count it.
+func (rd *round) flipDora() error {
+	for ; rd.lateDora > 0; rd.lateDora-- {
+		if n := len(rd.r.DoraIndicators); rd.rules.KanDora && n < maxIndicators {
+			if err := rd.emit(engine.Event{Type: engine.EventDora, Tile: rd.dead[n]}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replacement draws the tile that follows a kan from the dead wall,
+// which takes one tile from the end of the live wall.
+func (rd *round) replacement(seat int) (*engine.Tile, error) {
+	t := rd.dead[rinshanOffset+rd.kans-1]
+	rd.liveEnd--
+	if err := rd.emit(engine.Event{Type: engine.EventDrawTile, Seat: seat, Tile: t}); err != nil {
+		return nil, err
+	}
+	rd.rinshan = true
+	return &t, nil
+}
+
+// win scores seat winning on t, or reports false when it may not: the
+// hand is incomplete, has no yaku, or a ron would be furiten.
+func (rd *round) win(seat int, t engine.Tile, tsumo, chankan bool) (engine.Hand, bool) {
+	concealed := slices.Clone(rd.r.Hands[seat])
+	if tsumo {
+		i := slices.Index(concealed, t)
+		concealed = slices.Delete(concealed, i, i+1)
+	}
+	counts, err := engine.CountTiles(concealed)
+	if err != nil {
+		return engine.Hand{}, false
+	}
+	full := counts
+	if full.Add(t) != nil || analysis.Shanten(&full) != analysis.Complete {
+		return engine.Hand{}, false
+	}
+	if !tsumo {
+		if rd.tempFuriten[seat] || rd.riichiFuriten[seat] {
+			return engine.Hand{}, false
+		}
+		for _, w := range analysis.Accept(&counts, &engine.TileCounts{}).Tiles {
+			if slices.ContainsFunc(rd.r.Discards[seat], func(d engine.Discard) bool { return d.Tile.Index() == w.Index() }) {
+				return engine.Hand{}, false
+			}
+		}
+	}
+
+	last := rd.next == rd.liveEnd
+	h := engine.Hand{
+		Concealed:    concealed,
+		Melds:        slices.Clone(rd.r.Melds[seat]),
+		Winning:      &t,
+		Dora:         slices.Clone(rd.r.DoraIndicators),
+		RoundWind:    rd.r.Wind,
+		SeatWind:     rd.seatWind(seat),
+		Tsumo:        tsumo,
+		Riichi:       rd.r.Riichi[seat],
+		DoubleRiichi: rd.r.Riichi[seat] && rd.doubleRiichi[seat],
+		Ippatsu:      rd.ippatsu[seat],
+		Rinshan:      tsumo && rd.rinshan,
+		Chankan:      chankan,
+		Haitei:       tsumo && last && !rd.rinshan,
+		Houtei:       !tsumo && last && !chankan,
+	}
+	if h.Riichi && rd.rules.UraDora {
+		h.Ura = slices.Clone(rd.dead[uraOffset : uraOffset+len(h.Dora)])
+	}
+	if _, err := score.Score(h, rd.rules); err != nil {
+		return engine.Hand{}, false
+	}
+	return h, true
+}
+
+func (rd *round) seatWind(seat int) engine.Wind {
+	return engine.Wind((seat - rd.r.Dealer + 4) % 4)
+}
+
+func (rd *round) tsumo(seat int, t engine.Tile) (roundOutcome, error) {
+	h, ok := rd.win(seat, t, true, false)
+	if !ok {
+		return roundOutcome{}, fmt.Errorf("seat %d cannot win on %s", seat, t)
+	}
+	res, _ := score.Score(h, rd.rules)
+	var deltas [4]int
+	for p := range 4 {
+		if p == seat {
+			continue
+		}
+		pay := res.TsumoOther
+		if p == rd.r.Dealer {
+			pay = res.TsumoDealer
+		}
+		pay += 100 * rd.r.Honba
+		deltas[p] -= pay
+		deltas[seat] += pay
+	}
+	deltas[seat] += 1000 * rd.r.RiichiSticks
+	if err := rd.emitWin(seat, seat, h, res, deltas); err != nil {
+		return roundOutcome{}, err
+	}
+	return roundOutcome{dealerKeeps: seat == rd.r.Dealer}, nil
+}
+
+// ron settles one or more ron on tile t from seat p. The first winner in
+// turn order takes the riichi sticks; each winner is paid the honba.
+func (rd *round) ron(winners []int, p int, t engine.Tile, chankan bool) (roundOutcome, error) {
+	switch {
+	case rd.rules.MultipleRon == engine.MultipleRonHeadBump:
+		winners = winners[:1]
+	case rd.rules.MultipleRon == engine.MultipleRonDouble && len(winners) == 3:
+		draw := &engine.RyuukyokuResult{Reason: engine.RyuukyokuSanchahou}
+		if err := rd.emit(engine.Event{Type: engine.EventRyuukyoku, Seat: p, Ryuukyoku: draw}); err != nil {
+			return roundOutcome{}, err
+		}
+		return roundOutcome{dealerKeeps: true, drawn: true}, nil
+	}
+
+	var out roundOutcome
+	sticks := rd.r.RiichiSticks
+	for i, w := range winners {
+		h, _ := rd.win(w, t, false, chankan)
+		res, _ := score.Score(h, rd.rules)
+		var deltas [4]int
+		pay := res.Ron + 300*rd.r.Honba
+		deltas[p] -= pay
+		deltas[w] += pay
+		if i == 0 {
+			deltas[w] += 1000 * sticks
+		}
+		if err := rd.emitWin(w, p, h, res, deltas); err != nil {
+			return roundOutcome{}, err
+		}
+		out.dealerKeeps = out.dealerKeeps || w == rd.r.Dealer
+	}
+	return out, nil
+}
+
+func (rd *round) emitWin(seat, from int, h engine.Hand, res score.Result, deltas [4]int) error {
+	win := &engine.WinResult{
+		Seat:           seat,
+		From:           from,
+		Tile:           *h.Winning,
+		Hand:           h.Tiles(),
+		Melds:          h.Melds,
+		Han:            res.Han,
+		Fu:             res.Fu,
+		Points:         res.Points,
+		Yaku:           res.Yaku,
+		DoraIndicators: h.Dora,
+		UraIndicators:  h.Ura,
+		Deltas:         deltas,
+	}
+	return rd.emit(engine.Event{Type: engine.EventWin, Seat: seat, Win: win})
+}
+
+// exhaustive ends the round when the wall runs out: noten seats pay 3000
+// points split among the seats in tenpai.
+func (rd *round) exhaustive() (roundOutcome, error) {
+	draw := &engine.RyuukyokuResult{Reason: engine.RyuukyokuExhaustive}
+	n := 0
+	for s := range 4 {
+		c, err := engine.CountTiles(rd.r.Hands[s])
+		if err != nil {
+			return roundOutcome{}, err
+		}
+		if analysis.Shanten(&c) == analysis.Tenpai {
+			draw.Tenpai[s] = true
+			n++
+		}
+	}
+	if n > 0 && n < 4 {
+		for s, ready := range draw.Tenpai {
+			if ready {
+				draw.Deltas[s] = 3000 / n
+			} else {
+				draw.Deltas[s] = -3000 / (4 - n)
+			}
+		}
+	}
+	if err := rd.emit(engine.Event{Type: engine.EventRyuukyoku, Seat: rd.r.Turn, Ryuukyoku: draw}); err != nil {
+		return roundOutcome{}, err
+	}
+	return roundOutcome{dealerKeeps: draw.Tenpai[rd.r.Dealer], drawn: true}, nil
+}
+
+// kindsOf lists the kind indices in hand, in order, once each.
+func kindsOf(hand []engine.Tile) []int {
+	var kinds []int
+	for _, t := range hand {
+		if k := t.Index(); !slices.Contains(kinds, k) {
+			kinds = append(kinds, k)
+		}
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// ofKind returns the tiles of hand of kind k.
+func ofKind(hand []engine.Tile, k int) []engine.Tile {
+	var out []engine.Tile
+	for _, t := range hand {
+		if t.Index() == k {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// distinct returns the different tiles of hand, a red five apart from
+// plain ones, in hand order.
+func distinct(hand []engine.Tile) []engine.Tile {
+	var out []engine.Tile
+	for _, t := range hand {
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// pairs lists the different ways to take two tiles from same.
+func pairs(same []engine.Tile) [][2]engine.Tile {
+	var out [][2]engine.Tile
+	for i := range same {
+		for j := i + 1; j < len(same); j++ {
+			p := [2]engine.Tile{same[i], same[j]}
+			if !slices.ContainsFunc(out, func(q [2]engine.Tile) bool { return q == p || q == [2]engine.Tile{p[1], p[0]} }) {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
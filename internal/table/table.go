@@ -0,0 +1,183 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Config describes a game to play.
+type Config struct {
+	Names [4]string
+	Rules engine.Rules
+	Seed  uint64 // walls are shuffled from Seed; the same seed and players replay the same game
+
+	// Timeout bounds each decision; a seat that has not answered by then
+	// gets the default action. Zero waits as long as the game's context.
+	Timeout time.Duration
+
+	// OnEvent, if set, sees every event as it is played, before the next
+	// decision is asked for.
+	OnEvent func(engine.Event)
+}
+
+// Result is a finished game.
+type Result struct {
+	Events []engine.Event
+	Scores [4]int // final scores; riichi sticks left on the table go to first place
+
+	// Defaults counts the decisions each seat missed, by timeout or
+	// error, that the table made for it with DefaultAction.
+	Defaults [4]int
+}
+
+// Placement returns the seats ordered from first to last place. Ties go
+// to the seat that was dealer first.
+func (r *Result) Placement() [4]int {
+	seats := [4]int{0, 1, 2, 3}
+	slices.SortStableFunc(seats[:], func(a, b int) int {
+		return r.Scores[b] - r.Scores[a]
+	})
+	return seats
+}
+
+// Play runs a game between four players, seat 0 dealing first. It
+// returns an error if a player picks an action that was not offered, or
+// ctx's error once ctx is done.
+//
+// Rounds end by tsumo, ron (with multiple ron as the rules allow) or an
+// exhaustive draw with noten payments. Abortive draws other than a triple
+// ron are not declared, and nagashi mangan, pao and kuikae are not
+// played. Kan dora is turned as on Tenhou and Mahjong Soul under every
+// preset: at once for a closed kan, after the replacement tile's discard
+// for an open one.
+func Play(ctx context.Context, players [4]Player, cfg Config) (*Result, error) {
+	gm := &game{
+		ctx:     ctx,
+		players: players,
+		rules:   cfg.Rules,
+		timeout: cfg.Timeout,
+		onEvent: cfg.OnEvent,
+		g:       engine.NewGame(),
+		rng:     rand.New(rand.NewPCG(cfg.Seed, 0)),
+	}
+	if err := gm.emit(engine.Event{Type: engine.EventGameStart, Game: &engine.GameStart{Players: cfg.Names, Rules: cfg.Rules}}); err != nil {
+		return nil, err
+	}
+	scores := [4]int{}
+	for s := range scores {
+		scores[s] = cfg.Rules.StartingPoints
+	}
+
+	deal := engine.RoundStart{Wind: engine.WindEast, Scores: scores}
+	for {
+		deal.Scores = scores
+		out, err := gm.playRound(deal)
+		if err != nil {
+			return nil, err
+		}
+		scores = gm.g.Scores
+		next, done := gm.advance(deal, out, scores)
+		if done {
+			break
+		}
+		deal = next
+	}
+
+	// Sticks still on the table go to first place.
+	res := &Result{Scores: scores, Defaults: gm.defaults}
+	res.Scores[res.Placement()[0]] += 1000 * gm.g.Round.RiichiSticks
+	if err := gm.emit(engine.Event{Type: engine.EventGameEnd, End: &engine.GameEnd{Scores: res.Scores}}); err != nil {
+		return nil, err
+	}
+	res.Events = gm.events
+	return res, nil
+}
+
+// game is the state shared by the rounds of one game.
+type game struct {
+	ctx      context.Context
+	players  [4]Player
+	rules    engine.Rules
+	timeout  time.Duration
+	onEvent  func(engine.Event)
+	g        *engine.Game
+	events   []engine.Event
+	rng      *rand.Rand
+	defaults [4]int
+}
+
+// emit applies e to the engine's game, records it and tells the
+// observers.
+func (gm *game) emit(e engine.Event) error {
+	if err := gm.g.Apply(e); err != nil {
+		return fmt.Errorf("%s: %w", e, err)
+	}
+	gm.events = append(gm.events, e)
+	for seat, p := range gm.players {
+		if o, ok := p.(Observer); ok {
+			o.Observe(seat, e)
+		}
+	}
+	if gm.onEvent != nil {
+		gm.onEvent(e)
+	}
+	return nil
+}
+
+// roundOutcome is what the next deal depends on.
+type roundOutcome struct {
+	dealerKeeps bool // the dealer won, was tenpai at the draw, or the round was aborted
+	drawn       bool // nobody won
+}
+
+// advance works out the next deal, or reports that the game is over.
+func (gm *game) advance(deal engine.RoundStart, out roundOutcome, scores [4]int) (engine.RoundStart, bool) {
+	if gm.rules.Bust && slices.ContainsFunc(scores[:], func(s int) bool { return s < 0 }) {
+		return deal, true
+	}
+	lastWind := engine.WindEast
+	if gm.rules.Length == engine.GameEastSouth {
+		lastWind = engine.WindSouth
+	}
+	last := deal.Wind == lastWind && deal.Number == 3
+
+	next := deal
+	next.RiichiSticks = gm.g.Round.RiichiSticks
+	if out.dealerKeeps {
+		if last && !out.drawn && gm.rules.AgariYame && topSeat(scores) == deal.Dealer {
+			return deal, true
+		}
+		next.Honba++
+		return next, false
+	}
+	if last {
+		return deal, true
+	}
+	next.Honba = 0
+	if out.drawn {
+		next.Honba = deal.Honba + 1
+	}
+	next.Dealer = (deal.Dealer + 1) % 4
+	next.Number++
+	if next.Number == 4 {
+		next.Number = 0
+		next.Wind++
+	}
+	return next, false
+}
+
+// topSeat returns first place, ties going to the earlier seat.
+func topSeat(scores [4]int) int {
+	top := 0
+	for s, p := range scores {
+		if p > scores[top] {
+			top = s
+		}
+	}
+	return top
+}
@@ -0,0 +1,281 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// randomPlayer picks any legal action, wins whenever it can and calls
+// now and then, to reach every branch of the loop.
+type randomPlayer struct{ rng *rand.Rand }
+
+func (p randomPlayer) Turn(v *View, legal []Action) Action {
+	for _, a := range legal {
+		if a.Type == ActionTsumo || a.Type == ActionRiichi || a.Type == ActionKan {
+			return a
+		}
+	}
+	if v.Drawn != nil && p.rng.IntN(2) == 0 {
+		for _, a := range legal {
+			if a.Type == ActionDiscard && a.Tile == *v.Drawn {
+				return a
+			}
+		}
+	}
+	return legal[p.rng.IntN(len(legal))]
+}
+
+func (p randomPlayer) Call(v *View, legal []Action) Action {
+	for _, a := range legal {
+		if a.Type == ActionRon {
+			return a
+		}
+	}
+	if p.rng.IntN(4) == 0 {
+		return legal[p.rng.IntN(len(legal))]
+	}
+	return legal[0]
+}
+
+func randomPlayers(seed uint64) [4]Player {
+	var ps [4]Player
+	for s := range ps {
+		ps[s] = Local(randomPlayer{rand.New(rand.NewPCG(seed, uint64(s)))})
+	}
+	return ps
+}
+
+func TestPlay(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	kinds := map[engine.EventType]int{}
+	for seed := range uint64(20) {
+		res, err := Play(context.Background(), randomPlayers(seed), Config{Rules: rules, Seed: seed})
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		g, err := engine.Replay(res.Events, -1)
+		if err != nil {
+			t.Fatalf("seed %d: replay: %v", seed, err)
+		}
+		if !g.Finished || g.Scores != res.Scores {
+			t.Errorf("seed %d: finished %v, scores %v, want %v", seed, g.Finished, g.Scores, res.Scores)
+		}
+		total := 0
+		for _, s := range res.Scores {
+			total += s
+		}
+		if total != 4*rules.StartingPoints {
+			t.Errorf("seed %d: scores %v add up to %d", seed, res.Scores, total)
+		}
+		for _, e := range res.Events {
+			kinds[e.Type]++
+		}
+	}
+	for _, k := range []engine.EventType{engine.EventCall, engine.EventRiichiAccepted, engine.EventWin, engine.EventRyuukyoku, engine.EventDora} {
+		if kinds[k] == 0 {
+			t.Errorf("no %s in 20 games", k)
+		}
+	}
+}
+
+func TestPlayReproducible(t *testing.T) {
+	cfg := Config{Rules: engine.DefaultRules(), Seed: 42}
+	a, err := Play(context.Background(), randomPlayers(1), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Play(context.Background(), randomPlayers(1), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Events, b.Events) {
+		t.Error("the same seed played two different games")
+	}
+}
+
+type illegalPlayer struct{ randomPlayer }
+
+func (illegalPlayer) Turn(*View, []Action) Action {
+	return Action{Type: ActionTsumo}
+}
+
+func TestPlayIllegal(t *testing.T) {
+	ps := randomPlayers(0)
+	ps[0] = Local(illegalPlayer{})
+	if _, err := Play(context.Background(), ps, Config{Rules: engine.DefaultRules()}); err == nil {
+		t.Error("illegal action accepted")
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	r := Result{Scores: [4]int{25000, 31000, 25000, 19000}}
+	if got := r.Placement(); got != [4]int{1, 0, 2, 3} {
+		t.Errorf("Placement = %v", got)
+	}
+}
+
+// stuckPlayer never answers before its deadline.
+type stuckPlayer struct{}
+
+func (stuckPlayer) Turn(ctx context.Context, _ *View, _ []Action) (Action, error) {
+	<-ctx.Done()
+	return Action{}, ctx.Err()
+}
+
+func (stuckPlayer) Call(ctx context.Context, _ *View, _ []Action) (Action, error) {
+	<-ctx.Done()
+	return Action{}, ctx.Err()
+}
+
+func TestPlayTimeout(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Length = engine.GameEast
+	ps := randomPlayers(3)
+	ps[2] = stuckPlayer{}
+	res, err := Play(context.Background(), ps, Config{Rules: rules, Seed: 3, Timeout: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Defaults[2] == 0 {
+		t.Errorf("Defaults = %v, want seat 2 played for", res.Defaults)
+	}
+	if _, err := engine.Replay(res.Events, -1); err != nil {
+		t.Errorf("replay: %v", err)
+	}
+}
+
+func TestPlayCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ps := randomPlayers(0)
+	ps[0] = stuckPlayer{}
+	if _, err := Play(ctx, ps, Config{Rules: engine.DefaultRules()}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestDefaultAction(t *testing.T) {
+	m, _ := engine.ParseTile("1m")
+	p, _ := engine.ParseTile("5p")
+	legal := []Action{{Type: ActionTsumo}, {Type: ActionDiscard, Tile: m}, {Type: ActionDiscard, Tile: p}, {Type: ActionRiichi, Tile: p}}
+	tests := []struct {
+		name  string
+		v     View
+		legal []Action
+		want  Action
+	}{
+		{"tsumogiri", View{Drawn: &p}, legal, Action{Type: ActionDiscard, Tile: p}},
+		{"after a call", View{}, legal, Action{Type: ActionDiscard, Tile: m}},
+		{"pass", View{Offer: &p}, legal, Action{Type: ActionPass}},
+		{"no discard, pass", View{}, []Action{{Type: ActionRon}, {Type: ActionPass}}, Action{Type: ActionPass}},
+		{"no discard", View{}, []Action{{Type: ActionTsumo}}, Action{Type: ActionTsumo}},
+		{"nothing legal", View{}, nil, Action{Type: ActionPass}},
+	}
+	for _, tt := range tests {
+		if got := DefaultAction(&tt.v, tt.legal); !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// slowAgent takes longer than any deadline in the tests.
+type slowAgent struct{ randomPlayer }
+
+func (a slowAgent) Turn(v *View, legal []Action) Action {
+	time.Sleep(50 * time.Millisecond)
+	return a.randomPlayer.Turn(v, legal)
+}
+
+func TestLocalDeadline(t *testing.T) {
+	p := Local(slowAgent{randomPlayer{rand.New(rand.NewPCG(0, 0))}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	legal := []Action{{Type: ActionDiscard}}
+	if _, err := p.Turn(ctx, &View{}, legal); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want a missed deadline", err)
+	}
+	// The next decision waits for the late one to finish.
+	a, err := p.Turn(context.Background(), &View{}, legal)
+	if err != nil || !a.Equal(legal[0]) {
+		t.Errorf("got %s, %v", a, err)
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	var seen []engine.Event
+	cfg := Config{Rules: engine.DefaultRules(), Seed: 4, OnEvent: func(e engine.Event) { seen = append(seen, e) }}
+	res, err := Play(context.Background(), randomPlayers(4), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seen, res.Events) {
+		t.Errorf("OnEvent saw %d events, the game has %d", len(seen), len(res.Events))
+	}
+}
+
+// observer records what its seat is told.
+type observer struct {
+	Player
+	seen []engine.Event
+}
+
+func (o *observer) Observe(seat int, e engine.Event) { o.seen = append(o.seen, e) }
+
+func TestObserver(t *testing.T) {
+	ps := randomPlayers(5)
+	o := &observer{Player: ps[1]}
+	ps[1] = o
+	res, err := Play(context.Background(), ps, Config{Rules: engine.DefaultRules(), Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o.seen, res.Events) {
+		t.Errorf("observer saw %d events, the game has %d", len(o.seen), len(res.Events))
+	}
+}
+
+func TestKanDoraTiming(t *testing.T) {
+	openKans := 0
+	for seed := range uint64(30) {
+		res, err := Play(context.Background(), randomPlayers(seed), Config{Rules: engine.DefaultRules(), Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// want is how many indicators must follow an event, pending the
+		// open kans that wait for the next discard.
+		pending := 0
+		for i := 0; i < len(res.Events); i++ {
+			e := res.Events[i]
+			want := 0
+			switch {
+			case e.Type == engine.EventRoundStart:
+				pending = 0
+			case e.Type == engine.EventDiscard:
+				want, pending = pending, 0
+			case e.Type == engine.EventCall && e.Meld.Kind == engine.MeldAnkan:
+				want, pending = pending+1, 0
+			case e.Type == engine.EventCall && (e.Meld.Kind == engine.MeldKan || e.Meld.Kind == engine.MeldShouminkan):
+				want, pending = pending, 1
+				openKans++
+			}
+			got := 0
+			for i+1 < len(res.Events) && res.Events[i+1].Type == engine.EventDora {
+				got++
+				i++
+			}
+			if got != want {
+				t.Fatalf("seed %d: %d dora after event %d (%s), want %d", seed, got, i-got, e, want)
+			}
+		}
+	}
+	if openKans == 0 {
+		t.Error("no open kan was played")
+	}
+}
@@ -0,0 +1,108 @@
+package tenhou
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Meld code layout (the "m" attribute of <N> and <AGARI>):
+//
+//	bits 0-1: who the tile came from, relative to the caller
+//	          (0 self, 1 right, 2 across, 3 left)
+//	bit 2:    chi
+//	bit 3:    pon
+//	bit 4:    shouminkan (pon upgraded to kan)
+//	bit 5:    kita (three-player only)
+//	otherwise a kan: open when bits 0-1 are non-zero, closed otherwise
+const (
+	meldFromMask = 0x3
+	meldChi      = 0x4
+	meldPon      = 0x8
+	meldKakan    = 0x10
+	meldKita     = 0x20
+)
+
+// decodeMeld turns a Tenhou meld code into a meld for seat who.
+func decodeMeld(code, who int, aka bool) (engine.Meld, error) {
+	from := code & meldFromMask
+	var (
+		kind   engine.MeldKind
+		ids    []int
+		called int
+	)
+
+	switch {
+	case code&meldChi != 0:
+		if from != 3 {
+			return engine.Meld{}, fmt.Errorf("meld %d: chi must come from the left", code)
+		}
+		kind = engine.MeldChi
+		offsets := [3]int{(code >> 3) & 3, (code >> 5) & 3, (code >> 7) & 3}
+		base := code >> 10
+		calledIdx := base % 3
+		base /= 3
+		if base >= 3*7 {
+			return engine.Meld{}, fmt.Errorf("meld %d: invalid chi base", code)
+		}
+		start := (base/7)*kindsPerSuit + base%7
+		for i, off := range offsets {
+			ids = append(ids, 4*(start+i)+off)
+		}
+		called = ids[calledIdx]
+
+	case code&meldPon != 0, code&meldKakan != 0:
+		unused := (code >> 5) & 3
+		base := code >> 9
+		calledIdx := base % 3
+		base /= 3
+		if base >= 34 {
+			return engine.Meld{}, fmt.Errorf("meld %d: invalid pon base", code)
+		}
+		for i := 0; i < 4; i++ {
+			if i != unused {
+				ids = append(ids, 4*base+i)
+			}
+		}
+		called = ids[calledIdx]
+		kind = engine.MeldPon
+		if code&meldKakan != 0 {
+			// The added tile is the one missing from the original pon.
+			kind = engine.MeldShouminkan
+			called = 4*base + unused
+			ids = append(ids, called)
+		}
+
+	case code&meldKita != 0:
+		return engine.Meld{}, fmt.Errorf("meld %d: kita is only used in three-player games", code)
+
+	default:
+		first := code >> 8
+		if first >= engine.NumTileIDs {
+			return engine.Meld{}, fmt.Errorf("meld %d: invalid kan tile", code)
+		}
+		base := first / 4
+		for i := 0; i < 4; i++ {
+			ids = append(ids, 4*base+i)
+		}
+		called = first
+		kind = engine.MeldKan
+		if from == 0 {
+			kind = engine.MeldAnkan
+		}
+	}
+
+	m := engine.Meld{Kind: kind, From: (who + from) % 4}
+	for _, id := range ids {
+		t, err := TileFromID(id, aka)
+		if err != nil {
+			return engine.Meld{}, err
+		}
+		m.Tiles = append(m.Tiles, t)
+	}
+	var err error
+	if m.Called, err = TileFromID(called, aka); err != nil {
+		return engine.Meld{}, err
+	}
+	return m, nil
+}
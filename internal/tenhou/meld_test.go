@@ -0,0 +1,62 @@
+package tenhou
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestDecodeMeld(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		who        int
+		wantKind   engine.MeldKind
+		wantTiles  string
+		wantCalled string
+		wantFrom   int
+	}{
+		{"chi 3p0p5p", 27783, 1, engine.MeldChi, "3p4p5p", "3p", 0},
+		{"pon haku from left", 47723, 2, engine.MeldPon, "5z5z5z", "5z", 1},
+		{"shouminkan haku", 47731, 2, engine.MeldShouminkan, "5z5z5z5z", "5z", 1},
+		{"ankan 9s", 26624, 0, engine.MeldAnkan, "9s9s9s9s", "9s", 0},
+		{"daiminkan 5p from right", 13569, 3, engine.MeldKan, "0p5p5p5p", "5p", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := decodeMeld(tt.code, tt.who, true)
+			if err != nil {
+				t.Fatalf("decodeMeld(%d) failed: %v", tt.code, err)
+			}
+			var tiles strings.Builder
+			for _, tile := range m.Tiles {
+				tiles.WriteString(tile.String())
+			}
+			if m.Kind != tt.wantKind {
+				t.Errorf("Kind = %v, want %v", m.Kind, tt.wantKind)
+			}
+			if tiles.String() != tt.wantTiles {
+				t.Errorf("Tiles = %s, want %s", tiles.String(), tt.wantTiles)
+			}
+			if m.Called.String() != tt.wantCalled {
+				t.Errorf("Called = %v, want %s", m.Called, tt.wantCalled)
+			}
+			if m.From != tt.wantFrom {
+				t.Errorf("From = %d, want %d", m.From, tt.wantFrom)
+			}
+		})
+	}
+
+	t.Run("chi not from the left", func(t *testing.T) {
+		if _, err := decodeMeld(27783&^3|1, 0, true); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+	t.Run("kita", func(t *testing.T) {
+		if _, err := decodeMeld(0x20, 0, true); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
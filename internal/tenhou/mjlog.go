@@ -0,0 +1,498 @@
+package tenhou
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// Lobby type bits from the <GO type="..."> attribute.
+const (
+	goNoAka    = 0x02
+	goNoKuitan = 0x04
+	goHanchan  = 0x08
+	goSanma    = 0x10
+)
+
+// LoadMJLog reads an mjlog file. Both plain XML and the gzip-compressed
+// files Tenhou serves are accepted.
+func LoadMJLog(path string) ([]engine.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := ParseMJLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// ParseMJLog converts a Tenhou mjlog XML document into engine events.
+// Three-player logs are rejected.
+func ParseMJLog(r io.Reader) ([]engine.Event, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	p := &mjlogParser{aka: true, rules: engine.TenhouRules()}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid mjlog xml: %w", err)
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if err := p.element(el); err != nil {
+			return nil, fmt.Errorf("<%s>: %w", el.Name.Local, err)
+		}
+	}
+	if len(p.events) == 0 {
+		return nil, fmt.Errorf("mjlog contains no rounds")
+	}
+	return p.events, nil
+}
+
+type mjlogParser struct {
+	events   []engine.Event
+	aka      bool
+	rules    engine.Rules
+	players  [4]string
+	started  bool
+	lastDraw [4]int
+	hasDraw  [4]bool
+}
+
+func (p *mjlogParser) emit(e engine.Event) {
+	p.events = append(p.events, e)
+}
+
+// drawTags and discardTags map the tag letter to the seat.
+const (
+	drawTags    = "TUVW"
+	discardTags = "DEFG"
+)
+
+func (p *mjlogParser) element(el xml.StartElement) error {
+	name := el.Name.Local
+	attrs := make(map[string]string, len(el.Attr))
+	for _, a := range el.Attr {
+		attrs[a.Name.Local] = a.Value
+	}
+
+	// Draws and discards are encoded in the tag name: <T52/>, <D52/>.
+	if len(name) > 1 && strings.ContainsRune(drawTags+discardTags, rune(name[0])) {
+		if id, err := strconv.Atoi(name[1:]); err == nil {
+			return p.drawOrDiscard(name[0], id)
+		}
+	}
+
+	switch name {
+	case "GO":
+		typ, err := strconv.Atoi(attrs["type"])
+		if err != nil {
+			return fmt.Errorf("invalid type %q", attrs["type"])
+		}
+		if typ&goSanma != 0 {
+			return fmt.Errorf("three-player games are not supported")
+		}
+		p.aka = typ&goNoAka == 0
+		if !p.aka {
+			p.rules.RedFivesMan, p.rules.RedFivesPin, p.rules.RedFivesSou = 0, 0, 0
+		}
+		p.rules.OpenTanyao = typ&goNoKuitan == 0
+		p.rules.Length = engine.GameEast
+		if typ&goHanchan != 0 {
+			p.rules.Length = engine.GameEastSouth
+		}
+	case "UN":
+		// Reconnects repeat <UN> with a single name; only the first one
+		// carries the full table.
+		if p.started {
+			return nil
+		}
+		for i := range p.players {
+			if name, ok := attrs[fmt.Sprintf("n%d", i)]; ok {
+				decoded, err := url.PathUnescape(name)
+				if err != nil {
+					decoded = name
+				}
+				p.players[i] = decoded
+			}
+		}
+	case "TAIKYOKU":
+		p.startGame()
+	case "INIT":
+		return p.init(attrs)
+	case "N":
+		return p.call(attrs)
+	case "REACH":
+		return p.reach(attrs)
+	case "DORA":
+		id, err := strconv.Atoi(attrs["hai"])
+		if err != nil {
+			return fmt.Errorf("invalid hai %q", attrs["hai"])
+		}
+		t, err := TileFromID(id, p.aka)
+		if err != nil {
+			return err
+		}
+		p.emit(engine.Event{Type: engine.EventDora, Tile: t})
+	case "AGARI":
+		if err := p.agari(attrs); err != nil {
+			return err
+		}
+		return p.owari(attrs)
+	case "RYUUKYOKU":
+		if err := p.ryuukyoku(attrs); err != nil {
+			return err
+		}
+		return p.owari(attrs)
+	}
+	return nil
+}
+
+func (p *mjlogParser) startGame() {
+	if p.started {
+		return
+	}
+	p.started = true
+	p.emit(engine.Event{
+		Type: engine.EventGameStart,
+		Game: &engine.GameStart{Players: p.players, Rules: p.rules},
+	})
+}
+
+func (p *mjlogParser) init(attrs map[string]string) error {
+	p.startGame()
+
+	seed, err := ints(attrs["seed"])
+	if err != nil || len(seed) != 6 {
+		return fmt.Errorf("invalid seed %q", attrs["seed"])
+	}
+	ten, err := ints(attrs["ten"])
+	if err != nil || len(ten) != 4 {
+		return fmt.Errorf("invalid ten %q", attrs["ten"])
+	}
+	dealer, err := strconv.Atoi(attrs["oya"])
+	if err != nil || dealer < 0 || dealer > 3 {
+		return fmt.Errorf("invalid oya %q", attrs["oya"])
+	}
+	dora, err := TileFromID(seed[5], p.aka)
+	if err != nil {
+		return err
+	}
+
+	rs := &engine.RoundStart{
+		Wind:          engine.Wind(seed[0] / 4),
+		Number:        seed[0] % 4,
+		Honba:         seed[1],
+		RiichiSticks:  seed[2],
+		Dealer:        dealer,
+		DoraIndicator: dora,
+	}
+	for i := range 4 {
+		rs.Scores[i] = ten[i] * 100
+		if rs.Hands[i], err = tiles(attrs[fmt.Sprintf("hai%d", i)], p.aka); err != nil {
+			return err
+		}
+	}
+
+	p.hasDraw = [4]bool{}
+	p.emit(engine.Event{Type: engine.EventRoundStart, Round: rs})
+	return nil
+}
+
+func (p *mjlogParser) drawOrDiscard(tag byte, id int) error {
+	t, err := TileFromID(id, p.aka)
+	if err != nil {
+		return err
+	}
+	if seat := strings.IndexByte(drawTags, tag); seat >= 0 {
+		p.lastDraw[seat], p.hasDraw[seat] = id, true
+		p.emit(engine.Event{Type: engine.EventDrawTile, Seat: seat, Tile: t})
+		return nil
+	}
+	seat := strings.IndexByte(discardTags, tag)
+	tsumogiri := p.hasDraw[seat] && p.lastDraw[seat] == id
+	p.hasDraw[seat] = false
+	p.emit(engine.Event{Type: engine.EventDiscard, Seat: seat, Tile: t, Tsumogiri: tsumogiri})
+	return nil
+}
+
+func (p *mjlogParser) call(attrs map[string]string) error {
+	who, err := seatAttr(attrs, "who")
+	if err != nil {
+		return err
+	}
+	code, err := strconv.Atoi(attrs["m"])
+	if err != nil {
+		return fmt.Errorf("invalid m %q", attrs["m"])
+	}
+	m, err := decodeMeld(code, who, p.aka)
+	if err != nil {
+		return err
+	}
+	p.emit(engine.Event{Type: engine.EventCall, Seat: who, Meld: &m})
+	return nil
+}
+
+func (p *mjlogParser) reach(attrs map[string]string) error {
+	who, err := seatAttr(attrs, "who")
+	if err != nil {
+		return err
+	}
+	switch attrs["step"] {
+	case "1":
+		p.emit(engine.Event{Type: engine.EventRiichi, Seat: who})
+	case "2":
+		p.emit(engine.Event{Type: engine.EventRiichiAccepted, Seat: who})
+	default:
+		return fmt.Errorf("invalid step %q", attrs["step"])
+	}
+	return nil
+}
+
+// yakuByID maps Tenhou's yaku ids (the index) to engine yaku.
+var yakuByID = []engine.Yaku{
+	engine.YakuMenzenTsumo, engine.YakuRiichi, engine.YakuIppatsu, engine.YakuChankan,
+	engine.YakuRinshan, engine.YakuHaitei, engine.YakuHoutei, engine.YakuPinfu,
+	engine.YakuTanyao, engine.YakuIipeikou,
+	engine.YakuSeatWind, engine.YakuSeatWind, engine.YakuSeatWind, engine.YakuSeatWind,
+	engine.YakuRoundWind, engine.YakuRoundWind, engine.YakuRoundWind, engine.YakuRoundWind,
+	engine.YakuHaku, engine.YakuHatsu, engine.YakuChun, engine.YakuDoubleRiichi,
+	engine.YakuChiitoitsu, engine.YakuChanta, engine.YakuIttsu, engine.YakuSanshokuDoujun,
+	engine.YakuSanshokuDoukou, engine.YakuSankantsu, engine.YakuToitoi, engine.YakuSanankou,
+	engine.YakuShousangen, engine.YakuHonroutou, engine.YakuRyanpeikou, engine.YakuJunchan,
+	engine.YakuHonitsu, engine.YakuChinitsu, engine.YakuRenhou, engine.YakuTenhou,
+	engine.YakuChiihou, engine.YakuDaisangen, engine.YakuSuuankou, engine.YakuSuuankouTanki,
+	engine.YakuTsuuiisou, engine.YakuRyuuiisou, engine.YakuChinroutou, engine.YakuChuuren,
+	engine.YakuJunseiChuuren, engine.YakuKokushi, engine.YakuKokushi13, engine.YakuDaisuushii,
+	engine.YakuShousuushii, engine.YakuSuukantsu, engine.YakuDora, engine.YakuUraDora,
+	engine.YakuAkaDora,
+}
+
+func yakuFromID(id int) (engine.Yaku, error) {
+	if id < 0 || id >= len(yakuByID) {
+		return 0, fmt.Errorf("unknown yaku id %d", id)
+	}
+	return yakuByID[id], nil
+}
+
+func (p *mjlogParser) agari(attrs map[string]string) error {
+	who, err := seatAttr(attrs, "who")
+	if err != nil {
+		return err
+	}
+	from, err := seatAttr(attrs, "fromWho")
+	if err != nil {
+		return err
+	}
+	w := &engine.WinResult{Seat: who, From: from}
+
+	machi, err := strconv.Atoi(attrs["machi"])
+	if err != nil {
+		return fmt.Errorf("invalid machi %q", attrs["machi"])
+	}
+	if w.Tile, err = TileFromID(machi, p.aka); err != nil {
+		return err
+	}
+	if w.Hand, err = tiles(attrs["hai"], p.aka); err != nil {
+		return err
+	}
+	codes, err := ints(attrs["m"])
+	if err != nil {
+		return fmt.Errorf("invalid m %q", attrs["m"])
+	}
+	for _, code := range codes {
+		m, err := decodeMeld(code, who, p.aka)
+		if err != nil {
+			return err
+		}
+		w.Melds = append(w.Melds, m)
+	}
+
+	ten, err := ints(attrs["ten"])
+	if err != nil || len(ten) < 2 {
+		return fmt.Errorf("invalid ten %q", attrs["ten"])
+	}
+	w.Fu, w.Points = ten[0], ten[1]
+
+	yaku, err := ints(attrs["yaku"])
+	if err != nil || len(yaku)%2 != 0 {
+		return fmt.Errorf("invalid yaku %q", attrs["yaku"])
+	}
+	for i := 0; i < len(yaku); i += 2 {
+		y, err := yakuFromID(yaku[i])
+		if err != nil {
+			return err
+		}
+		w.Yaku = append(w.Yaku, engine.YakuHan{Yaku: y, Han: yaku[i+1]})
+		w.Han += yaku[i+1]
+	}
+	yakuman, err := ints(attrs["yakuman"])
+	if err != nil {
+		return fmt.Errorf("invalid yakuman %q", attrs["yakuman"])
+	}
+	for _, id := range yakuman {
+		y, err := yakuFromID(id)
+		if err != nil {
+			return err
+		}
+		w.Yaku = append(w.Yaku, engine.YakuHan{Yaku: y, Han: 13})
+		w.Han += 13
+	}
+
+	if w.DoraIndicators, err = tiles(attrs["doraHai"], p.aka); err != nil {
+		return err
+	}
+	if w.UraIndicators, err = tiles(attrs["doraHaiUra"], p.aka); err != nil {
+		return err
+	}
+	if w.Deltas, err = deltas(attrs["sc"]); err != nil {
+		return err
+	}
+
+	p.emit(engine.Event{Type: engine.EventWin, Seat: who, Tile: w.Tile, Win: w})
+	return nil
+}
+
+// ryuukyokuTypes maps the RYUUKYOKU type attribute to a reason. A missing
+// type is an exhaustive draw.
+var ryuukyokuTypes = map[string]engine.RyuukyokuReason{
+	"":       engine.RyuukyokuExhaustive,
+	"yao9":   engine.RyuukyokuKyuushu,
+	"kaze4":  engine.RyuukyokuSuufon,
+	"reach4": engine.RyuukyokuSuuchaRiichi,
+	"kan4":   engine.RyuukyokuSuukaikan,
+	"ron3":   engine.RyuukyokuSanchahou,
+	"nm":     engine.RyuukyokuNagashiMangan,
+}
+
+func (p *mjlogParser) ryuukyoku(attrs map[string]string) error {
+	reason, ok := ryuukyokuTypes[attrs["type"]]
+	if !ok {
+		return fmt.Errorf("unknown type %q", attrs["type"])
+	}
+	res := &engine.RyuukyokuResult{Reason: reason}
+	for i := range 4 {
+		// Tenpai hands are shown at an exhaustive draw.
+		_, res.Tenpai[i] = attrs[fmt.Sprintf("hai%d", i)]
+	}
+	var err error
+	if res.Deltas, err = deltas(attrs["sc"]); err != nil {
+		return err
+	}
+	p.emit(engine.Event{Type: engine.EventRyuukyoku, Ryuukyoku: res})
+	return nil
+}
+
+// owari emits the game end when the round result carries final scores.
+func (p *mjlogParser) owari(attrs map[string]string) error {
+	raw, ok := attrs["owari"]
+	if !ok {
+		return nil
+	}
+	vals, err := floats(raw)
+	if err != nil || len(vals) < 8 {
+		return fmt.Errorf("invalid owari %q", raw)
+	}
+	end := &engine.GameEnd{}
+	for i := range 4 {
+		end.Scores[i] = int(vals[2*i]) * 100
+	}
+	p.emit(engine.Event{Type: engine.EventGameEnd, End: end})
+	return nil
+}
+
+func seatAttr(attrs map[string]string, key string) (int, error) {
+	seat, err := strconv.Atoi(attrs[key])
+	if err != nil || seat < 0 || seat > 3 {
+		return 0, fmt.Errorf("invalid %s %q", key, attrs[key])
+	}
+	return seat, nil
+}
+
+// deltas reads an "sc" attribute: score,delta pairs in hundreds.
+func deltas(raw string) ([4]int, error) {
+	var out [4]int
+	vals, err := ints(raw)
+	if err != nil || len(vals) < 8 {
+		return out, fmt.Errorf("invalid sc %q", raw)
+	}
+	for i := range 4 {
+		out[i] = vals[2*i+1] * 100
+	}
+	return out, nil
+}
+
+// tiles parses a comma-separated list of tile ids.
+func tiles(raw string, aka bool) ([]engine.Tile, error) {
+	ids, err := ints(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tile list %q", raw)
+	}
+	out := make([]engine.Tile, 0, len(ids))
+	for _, id := range ids {
+		t, err := TileFromID(id, aka)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func ints(raw string) ([]int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, ",")
+	out := make([]int, 0, len(parts))
+	for _, s := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func floats(raw string) ([]float64, error) {
+	parts := strings.Split(raw, ",")
+	out := make([]float64, 0, len(parts))
+	for _, s := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
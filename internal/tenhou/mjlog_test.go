@@ -0,0 +1,210 @@
+package tenhou
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestLoadMJLog(t *testing.T) {
+	events, err := LoadMJLog("testdata/sample.xml")
+	if err != nil {
+		t.Fatalf("LoadMJLog failed: %v", err)
+	}
+
+	wantTypes := []engine.EventType{
+		engine.EventGameStart,
+		engine.EventRoundStart,
+		engine.EventDrawTile, engine.EventCall, engine.EventDora,
+		engine.EventDrawTile, engine.EventDiscard,
+		engine.EventDrawTile, engine.EventDiscard,
+		engine.EventCall, engine.EventDiscard,
+		engine.EventDrawTile, engine.EventRiichi, engine.EventDiscard, engine.EventRiichiAccepted,
+		engine.EventDrawTile, engine.EventDiscard,
+		engine.EventWin,
+		engine.EventGameEnd,
+	}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(wantTypes), events)
+	}
+	for i, e := range events {
+		if e.Type != wantTypes[i] {
+			t.Errorf("event %d: type %v, want %v", i, e.Type, wantTypes[i])
+		}
+	}
+
+	game := events[0].Game
+	if game.Players != [4]string{"Alice", "Bob", "天", "Dan"} {
+		t.Errorf("Players = %q", game.Players)
+	}
+	if game.Rules.Length != engine.GameEastSouth || game.Rules.RedFivesMan != 1 {
+		t.Errorf("unexpected rules from lobby type: %+v", game.Rules)
+	}
+
+	round := events[1].Round
+	if round.Dealer != 0 || round.Scores[0] != 25000 || round.DoraIndicator.String() != "6p" {
+		t.Errorf("unexpected round start: %+v", round)
+	}
+	for seat, hand := range round.Hands {
+		if len(hand) != 13 {
+			t.Errorf("seat %d dealt %d tiles, want 13", seat, len(hand))
+		}
+	}
+
+	if m := events[3].Meld; m.Kind != engine.MeldAnkan || m.Tiles[0].String() != "9s" {
+		t.Errorf("expected ankan of 9s, got %v", m)
+	}
+	if events[4].Tile.String() != "2s" {
+		t.Errorf("new dora indicator = %v, want 2s", events[4].Tile)
+	}
+	if !events[6].Tsumogiri || events[8].Tsumogiri {
+		t.Errorf("tsumogiri flags wrong: %v %v", events[6].Tsumogiri, events[8].Tsumogiri)
+	}
+	if m := events[9].Meld; events[9].Seat != 2 || m.Kind != engine.MeldPon || m.From != 1 {
+		t.Errorf("expected pon by seat 2 from seat 1, got seat %d %+v", events[9].Seat, m)
+	}
+	if events[12].Seat != 3 || !events[13].Tsumogiri {
+		t.Errorf("riichi discard not read correctly")
+	}
+
+	win := events[17].Win
+	if win.Seat != 3 || win.From != 0 || win.IsTsumo() {
+		t.Errorf("expected ron by seat 3 on seat 0, got %+v", win)
+	}
+	if win.Tile.String() != "0s" || len(win.Hand) != 14 {
+		t.Errorf("winning tile %v, hand size %d", win.Tile, len(win.Hand))
+	}
+	if win.Han != 3 || win.Fu != 30 || win.Points != 3900 {
+		t.Errorf("han/fu/points = %d/%d/%d, want 3/30/3900", win.Han, win.Fu, win.Points)
+	}
+	wantYaku := []engine.Yaku{engine.YakuRiichi, engine.YakuTanyao, engine.YakuAkaDora}
+	for i, y := range win.Yaku {
+		if y.Yaku != wantYaku[i] {
+			t.Errorf("yaku %d = %v, want %v", i, y.Yaku, wantYaku[i])
+		}
+	}
+	if win.Deltas != [4]int{-3900, 0, 0, 4900} {
+		t.Errorf("Deltas = %v", win.Deltas)
+	}
+	if len(win.UraIndicators) != 2 {
+		t.Errorf("expected 2 ura indicators, got %d", len(win.UraIndicators))
+	}
+
+	if end := events[18].End; end.Scores != [4]int{21100, 25000, 25000, 28900} {
+		t.Errorf("final scores = %v", end.Scores)
+	}
+}
+
+// TestLoadMJLog_Replay checks that the imported hands are consistent:
+// every discard and call uses tiles the seat holds, and the winner holds
+// the hand the log scores.
+func TestLoadMJLog_Replay(t *testing.T) {
+	events, err := LoadMJLog("testdata/sample.xml")
+	if err != nil {
+		t.Fatalf("LoadMJLog failed: %v", err)
+	}
+	last := len(events) - 2 // the win
+	g, err := engine.Replay(events, last)
+	if err != nil {
+		t.Fatalf("events do not replay: %v", err)
+	}
+	win := events[last].Win
+	held := append(slices.Clone(g.Round.Hands[win.Seat]), win.Tile)
+	for _, m := range g.Round.Melds[win.Seat] {
+		held = append(held, m.Tiles...)
+	}
+	sorted := func(tiles []engine.Tile) []string {
+		var out []string
+		for _, t := range tiles {
+			out = append(out, t.String())
+		}
+		slices.Sort(out)
+		return out
+	}
+	if !slices.Equal(sorted(held), sorted(win.Hand)) {
+		t.Errorf("seat %d holds %v, the log scores %v", win.Seat, held, win.Hand)
+	}
+	if _, err := engine.Replay(events, -1); err != nil {
+		t.Errorf("events do not replay: %v", err)
+	}
+}
+
+func TestParseMJLog_Gzip(t *testing.T) {
+	raw, err := os.ReadFile("testdata/sample.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(raw)
+	zw.Close()
+
+	events, err := ParseMJLog(&buf)
+	if err != nil {
+		t.Fatalf("ParseMJLog(gzip) failed: %v", err)
+	}
+	if len(events) != 19 {
+		t.Errorf("got %d events, want 19", len(events))
+	}
+}
+
+func TestParseMJLog_Ryuukyoku(t *testing.T) {
+	log := `<mjloggm ver="2.3"><GO type="9"/><UN n0="a" n1="b" n2="c" n3="d"/><TAIKYOKU oya="0"/>
+<INIT seed="5,1,1,1,1,0" ten="250,250,250,240" oya="1" hai0="" hai1="" hai2="" hai3=""/>
+<RYUUKYOKU ba="1,1" sc="250,15,250,-15,250,15,240,-15" hai0="1,2,3" hai2="4,5,6"/>
+<INIT seed="6,2,1,1,1,0" ten="265,235,265,225" oya="2" hai0="" hai1="" hai2="" hai3=""/>
+<RYUUKYOKU type="yao9" ba="2,1" sc="265,0,235,0,265,0,225,0"/>
+</mjloggm>`
+	events, err := ParseMJLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ParseMJLog failed: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+
+	if rules := events[0].Game.Rules; rules.RedFivesMan != 1 || rules.Length != engine.GameEastSouth {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+	round := events[1].Round
+	if round.Wind != engine.WindSouth || round.Number != 1 || round.Honba != 1 || round.RiichiSticks != 1 {
+		t.Errorf("unexpected round: %+v", round)
+	}
+	draw := events[2].Ryuukyoku
+	if draw.Reason != engine.RyuukyokuExhaustive || draw.Tenpai != [4]bool{true, false, true, false} {
+		t.Errorf("unexpected exhaustive draw: %+v", draw)
+	}
+	if draw.Deltas != [4]int{1500, -1500, 1500, -1500} {
+		t.Errorf("Deltas = %v", draw.Deltas)
+	}
+	if events[4].Ryuukyoku.Reason != engine.RyuukyokuKyuushu {
+		t.Errorf("expected kyuushu, got %v", events[4].Ryuukyoku.Reason)
+	}
+}
+
+func TestParseMJLog_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{"not xml", "<mjloggm"},
+		{"empty", "<mjloggm ver=\"2.3\"></mjloggm>"},
+		{"sanma", `<mjloggm><GO type="25"/></mjloggm>`},
+		{"bad seed", `<mjloggm><INIT seed="0,0" ten="250,250,250,250" oya="0"/></mjloggm>`},
+		{"bad tile", `<mjloggm><INIT seed="0,0,0,0,0,0" ten="250,250,250,250" oya="0"/><T140/></mjloggm>`},
+		{"bad meld", `<mjloggm><INIT seed="0,0,0,0,0,0" ten="250,250,250,250" oya="0"/><N who="0" m="32"/></mjloggm>`},
+		{"bad reach", `<mjloggm><INIT seed="0,0,0,0,0,0" ten="250,250,250,250" oya="0"/><REACH who="0" step="3"/></mjloggm>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMJLog(strings.NewReader(tt.log)); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
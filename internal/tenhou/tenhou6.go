@@ -0,0 +1,635 @@
+package tenhou
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// tenhou.net/6 JSON layout:
+//
+//	{"name": [...], "rule": {"disp": "般南喰赤", "aka": 1}, "log": [round, ...]}
+//
+// Each round is a 17-element array:
+//
+//	[kyoku, honba, riichi sticks], [scores], [dora indicators], [ura indicators],
+//	then haipai, takes, discards for seats 0–3, then the result.
+//
+// Takes are drawn tile codes or call strings; discards are tile codes, 60
+// for tsumogiri, "r"-prefixed codes for riichi, or kan strings.
+type log6 struct {
+	Ver   float64             `json:"ver,omitempty"`
+	Title []string            `json:"title"`
+	Name  []string            `json:"name"`
+	Rule  rule6               `json:"rule"`
+	Log   [][]json.RawMessage `json:"log"`
+	Sc    []float64           `json:"sc,omitempty"`
+}
+
+type rule6 struct {
+	Disp string `json:"disp"`
+	Aka  int    `json:"aka"`
+}
+
+const (
+	round6Len    = 17
+	round6Seats  = 4
+	round6Result = 16
+)
+
+// LoadJSON reads a tenhou.net/6 JSON log file.
+func LoadJSON(path string) ([]engine.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := ParseJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// ParseJSON converts a tenhou.net/6 JSON log into engine events. The
+// format does not interleave the players' actions, so the turn order is
+// rebuilt from the takes and discards.
+func ParseJSON(r io.Reader) ([]engine.Event, error) {
+	var doc log6
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid tenhou json: %w", err)
+	}
+	if len(doc.Log) == 0 {
+		return nil, fmt.Errorf("tenhou json contains no rounds")
+	}
+
+	game := &engine.GameStart{Rules: rulesFromDisp(doc.Rule)}
+	for i := 0; i < len(doc.Name) && i < 4; i++ {
+		game.Players[i] = doc.Name[i]
+	}
+	events := []engine.Event{{Type: engine.EventGameStart, Game: game}}
+
+	for i, raw := range doc.Log {
+		roundEvents, err := parseRound6(raw)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", i, err)
+		}
+		events = append(events, roundEvents...)
+	}
+
+	if len(doc.Sc) >= 8 {
+		end := &engine.GameEnd{}
+		for i := range 4 {
+			end.Scores[i] = int(doc.Sc[2*i])
+		}
+		events = append(events, engine.Event{Type: engine.EventGameEnd, End: end})
+	}
+	return events, nil
+}
+
+func rulesFromDisp(r rule6) engine.Rules {
+	rules := engine.TenhouRules()
+	if r.Aka == 0 && !strings.Contains(r.Disp, "赤") {
+		rules.RedFivesMan, rules.RedFivesPin, rules.RedFivesSou = 0, 0, 0
+	}
+	if r.Disp != "" {
+		rules.OpenTanyao = strings.Contains(r.Disp, "喰")
+		if strings.Contains(r.Disp, "東") {
+			rules.Length = engine.GameEast
+		}
+	}
+	return rules
+}
+
+// round6 is a decoded round with per-seat cursors into takes and discards.
+type round6 struct {
+	takes    [4][]any
+	discards [4][]any
+	ti, di   [4]int
+	dora     []engine.Tile
+	doraNext int
+	hands    [4][]engine.Tile
+	events   []engine.Event
+}
+
+func parseRound6(raw []json.RawMessage) ([]engine.Event, error) {
+	if len(raw) != round6Len {
+		return nil, fmt.Errorf("expected %d entries, got %d", round6Len, len(raw))
+	}
+
+	var header, scores, doraCodes, uraCodes []int
+	for i, dst := range []*[]int{&header, &scores, &doraCodes, &uraCodes} {
+		if err := json.Unmarshal(raw[i], dst); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+	if len(header) != 3 || len(scores) != 4 || len(doraCodes) == 0 {
+		return nil, fmt.Errorf("invalid round header")
+	}
+
+	rd := &round6{}
+	var err error
+	if rd.dora, err = codesToTiles(doraCodes); err != nil {
+		return nil, err
+	}
+	ura, err := codesToTiles(uraCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &engine.RoundStart{
+		Wind:          engine.Wind(header[0] / 4),
+		Number:        header[0] % 4,
+		Honba:         header[1],
+		RiichiSticks:  header[2],
+		Dealer:        header[0] % 4,
+		DoraIndicator: rd.dora[0],
+	}
+	copy(rs.Scores[:], scores)
+	for seat := range round6Seats {
+		base := 4 + 3*seat
+		var haipai []int
+		if err := json.Unmarshal(raw[base], &haipai); err != nil {
+			return nil, fmt.Errorf("seat %d haipai: %w", seat, err)
+		}
+		if rs.Hands[seat], err = codesToTiles(haipai); err != nil {
+			return nil, err
+		}
+		rd.hands[seat] = slices.Clone(rs.Hands[seat])
+		if err := json.Unmarshal(raw[base+1], &rd.takes[seat]); err != nil {
+			return nil, fmt.Errorf("seat %d takes: %w", seat, err)
+		}
+		if err := json.Unmarshal(raw[base+2], &rd.discards[seat]); err != nil {
+			return nil, fmt.Errorf("seat %d discards: %w", seat, err)
+		}
+	}
+	rd.doraNext = 1
+	rd.emit(engine.Event{Type: engine.EventRoundStart, Round: rs})
+
+	var result []any
+	if err := json.Unmarshal(raw[round6Result], &result); err != nil {
+		return nil, fmt.Errorf("result: %w", err)
+	}
+
+	lastDiscard, lastSeat, err := rd.play(rs.Dealer, isRon(result))
+	if err != nil {
+		return nil, err
+	}
+	if err := rd.result(result, rs, lastDiscard, lastSeat, ura); err != nil {
+		return nil, err
+	}
+	return rd.events, nil
+}
+
+func (rd *round6) emit(e engine.Event) {
+	rd.events = append(rd.events, e)
+}
+
+// play replays the takes and discards in turn order until a player runs
+// out of entries. It returns the last discard so a ron can refer to it.
+func (rd *round6) play(dealer int, endsInRon bool) (engine.Tile, int, error) {
+	seat := dealer
+	needDraw := true
+	pendingRiichi := -1
+	var lastDiscard engine.Tile
+	lastSeat := -1
+
+	acceptRiichi := func() {
+		if pendingRiichi >= 0 {
+			rd.emit(engine.Event{Type: engine.EventRiichiAccepted, Seat: pendingRiichi})
+			pendingRiichi = -1
+		}
+	}
+
+	for {
+		var drawn engine.Tile
+		if needDraw {
+			if rd.ti[seat] >= len(rd.takes[seat]) {
+				break
+			}
+			code, ok := asCode(rd.takes[seat][rd.ti[seat]])
+			if !ok {
+				return 0, 0, fmt.Errorf("seat %d: unexpected call %v while drawing", seat, rd.takes[seat][rd.ti[seat]])
+			}
+			rd.ti[seat]++
+			acceptRiichi()
+			t, err := TileFromCode(code)
+			if err != nil {
+				return 0, 0, err
+			}
+			drawn = t
+			rd.hands[seat] = append(rd.hands[seat], t)
+			rd.emit(engine.Event{Type: engine.EventDrawTile, Seat: seat, Tile: t})
+		}
+
+		if rd.di[seat] >= len(rd.discards[seat]) {
+			break
+		}
+		entry := rd.discards[seat][rd.di[seat]]
+		rd.di[seat]++
+
+		// Closed and added kans are written in the discard column.
+		if s, ok := entry.(string); ok && strings.ContainsAny(s, "ak") {
+			m, err := meldFromCall(s, seat)
+			if err != nil {
+				return 0, 0, err
+			}
+			rd.call(seat, m)
+			needDraw = true
+			continue
+		}
+
+		riichi := false
+		if s, ok := entry.(string); ok && strings.HasPrefix(s, "r") {
+			riichi = true
+			code, err := strconv.Atoi(s[1:])
+			if err != nil {
+				return 0, 0, fmt.Errorf("seat %d: invalid riichi discard %q", seat, s)
+			}
+			entry = float64(code)
+		}
+		code, ok := asCode(entry)
+		if !ok {
+			return 0, 0, fmt.Errorf("seat %d: invalid discard %v", seat, entry)
+		}
+
+		tsumogiri := code == codeTsumogiri
+		var t engine.Tile
+		if tsumogiri {
+			if !needDraw {
+				return 0, 0, fmt.Errorf("seat %d: tsumogiri after a call", seat)
+			}
+			t = drawn
+		} else {
+			var err error
+			if t, err = TileFromCode(code); err != nil {
+				return 0, 0, err
+			}
+		}
+		if riichi {
+			rd.emit(engine.Event{Type: engine.EventRiichi, Seat: seat})
+		}
+		rd.hands[seat] = engine.RemoveTile(rd.hands[seat], t)
+		rd.emit(engine.Event{Type: engine.EventDiscard, Seat: seat, Tile: t, Tsumogiri: tsumogiri})
+		lastDiscard, lastSeat = t, seat
+		if riichi {
+			pendingRiichi = seat
+		}
+
+		caller, m, err := rd.findCall(seat, t)
+		if err != nil {
+			return 0, 0, err
+		}
+		if caller < 0 {
+			seat = (seat + 1) % 4
+			needDraw = true
+			continue
+		}
+
+		acceptRiichi()
+		rd.ti[caller]++
+		rd.call(caller, m)
+		seat = caller
+		needDraw = false
+		if m.Kind == engine.MeldKan {
+			// The discard column has a 0 placeholder for the turn the
+			// open kan replaced; the rinshan draw follows.
+			if rd.di[caller] < len(rd.discards[caller]) {
+				if code, ok := asCode(rd.discards[caller][rd.di[caller]]); ok && code == 0 {
+					rd.di[caller]++
+				}
+			}
+			needDraw = true
+		}
+	}
+
+	if !endsInRon {
+		acceptRiichi()
+	}
+	return lastDiscard, lastSeat, nil
+}
+
+// findCall looks for a player whose next take calls the discard. Pon and
+// kan take priority over chi.
+func (rd *round6) findCall(discarder int, discard engine.Tile) (int, engine.Meld, error) {
+	chiSeat := -1
+	var chi engine.Meld
+	for off := 1; off < 4; off++ {
+		seat := (discarder + off) % 4
+		if rd.ti[seat] >= len(rd.takes[seat]) {
+			continue
+		}
+		s, ok := rd.takes[seat][rd.ti[seat]].(string)
+		if !ok {
+			continue
+		}
+		m, err := meldFromCall(s, seat)
+		if err != nil {
+			return -1, engine.Meld{}, err
+		}
+		if m.From != discarder || m.Called.Plain() != discard.Plain() {
+			continue
+		}
+		if m.Kind == engine.MeldChi {
+			chiSeat, chi = seat, m
+			continue
+		}
+		return seat, m, nil
+	}
+	return chiSeat, chi, nil
+}
+
+// call records a meld and reveals a kan dora if one is listed.
+func (rd *round6) call(seat int, m engine.Meld) {
+	own := m.Tiles
+	if m.Kind != engine.MeldAnkan {
+		own = engine.RemoveTile(slices.Clone(m.Tiles), m.Called)
+	}
+	if m.Kind == engine.MeldShouminkan {
+		own = []engine.Tile{m.Called}
+	}
+	for _, t := range own {
+		rd.hands[seat] = engine.RemoveTile(rd.hands[seat], t)
+	}
+
+	rd.emit(engine.Event{Type: engine.EventCall, Seat: seat, Meld: &m})
+	if m.Kind.IsKan() && rd.doraNext < len(rd.dora) {
+		rd.emit(engine.Event{Type: engine.EventDora, Tile: rd.dora[rd.doraNext]})
+		rd.doraNext++
+	}
+}
+
+// callMarkers maps the marker letter and its position (number of tiles
+// before it) to the seat offset of the player the tile came from.
+var callMarkers = map[byte]map[int]int{
+	'c': {0: 3},
+	'p': {0: 3, 1: 2, 2: 1},
+	'k': {0: 3, 1: 2, 2: 1},
+	'm': {0: 3, 1: 2, 3: 1},
+	'a': {3: 0},
+}
+
+var callKinds = map[byte]engine.MeldKind{
+	'c': engine.MeldChi,
+	'p': engine.MeldPon,
+	'k': engine.MeldShouminkan,
+	'm': engine.MeldKan,
+	'a': engine.MeldAnkan,
+}
+
+// meldFromCall parses a call string such as "c275226" or "47p4747". The
+// tile right after the marker is the called (or added) tile.
+func meldFromCall(s string, seat int) (engine.Meld, error) {
+	var (
+		marker byte
+		pos    = -1
+		tiles  []engine.Tile
+	)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 'a' && c <= 'z' {
+			if marker != 0 {
+				return engine.Meld{}, fmt.Errorf("invalid call %q", s)
+			}
+			marker, pos = c, len(tiles)
+			i++
+			continue
+		}
+		if i+2 > len(s) {
+			return engine.Meld{}, fmt.Errorf("invalid call %q", s)
+		}
+		code, err := strconv.Atoi(s[i : i+2])
+		if err != nil {
+			return engine.Meld{}, fmt.Errorf("invalid call %q", s)
+		}
+		t, err := TileFromCode(code)
+		if err != nil {
+			return engine.Meld{}, fmt.Errorf("invalid call %q: %w", s, err)
+		}
+		tiles = append(tiles, t)
+		i += 2
+	}
+
+	offsets, ok := callMarkers[marker]
+	if !ok {
+		return engine.Meld{}, fmt.Errorf("invalid call %q", s)
+	}
+	off, ok := offsets[pos]
+	wantLen := 3
+	if callKinds[marker].IsKan() {
+		wantLen = 4
+	}
+	if !ok || len(tiles) != wantLen {
+		return engine.Meld{}, fmt.Errorf("invalid call %q", s)
+	}
+
+	return engine.Meld{
+		Kind:   callKinds[marker],
+		Tiles:  tiles,
+		Called: tiles[pos],
+		From:   (seat + off) % 4,
+	}, nil
+}
+
+func isRon(result []any) bool {
+	if len(result) < 3 || result[0] != "和了" {
+		return false
+	}
+	info, ok := result[2].([]any)
+	if !ok || len(info) < 2 {
+		return false
+	}
+	return info[0] != info[1]
+}
+
+// ryuukyokuNames maps the result names of drawn rounds to reasons.
+var ryuukyokuNames = map[string]engine.RyuukyokuReason{
+	"流局":   engine.RyuukyokuExhaustive,
+	"全員聴牌": engine.RyuukyokuExhaustive,
+	"全員不聴": engine.RyuukyokuExhaustive,
+	"流し満貫": engine.RyuukyokuNagashiMangan,
+	"九種九牌": engine.RyuukyokuKyuushu,
+	"四風連打": engine.RyuukyokuSuufon,
+	"四家立直": engine.RyuukyokuSuuchaRiichi,
+	"四槓散了": engine.RyuukyokuSuukaikan,
+	"三家和了": engine.RyuukyokuSanchahou,
+}
+
+func (rd *round6) result(result []any, rs *engine.RoundStart, lastDiscard engine.Tile, lastSeat int, ura []engine.Tile) error {
+	if len(result) == 0 {
+		return fmt.Errorf("empty result")
+	}
+	name, _ := result[0].(string)
+
+	if name != "和了" {
+		reason, ok := ryuukyokuNames[name]
+		if !ok {
+			return fmt.Errorf("unknown result %q", name)
+		}
+		res := &engine.RyuukyokuResult{Reason: reason}
+		if len(result) > 1 {
+			d, err := deltas6(result[1])
+			if err != nil {
+				return err
+			}
+			res.Deltas = d
+		}
+		for i := range 4 {
+			res.Tenpai[i] = name == "全員聴牌" || (reason == engine.RyuukyokuExhaustive && res.Deltas[i] > 0)
+		}
+		rd.emit(engine.Event{Type: engine.EventRyuukyoku, Ryuukyoku: res})
+		return nil
+	}
+
+	if len(result) < 3 || len(result)%2 != 1 {
+		return fmt.Errorf("invalid win result")
+	}
+	for i := 1; i < len(result); i += 2 {
+		d, err := deltas6(result[i])
+		if err != nil {
+			return err
+		}
+		info, ok := result[i+1].([]any)
+		if !ok || len(info) < 4 {
+			return fmt.Errorf("invalid win info %v", result[i+1])
+		}
+		who, ok1 := asCode(info[0])
+		from, ok2 := asCode(info[1])
+		if !ok1 || !ok2 || who < 0 || who > 3 || from < 0 || from > 3 {
+			return fmt.Errorf("invalid win seats %v", info[:2])
+		}
+
+		w := &engine.WinResult{Seat: who, From: from, Deltas: d, UraIndicators: ura}
+		w.DoraIndicators = rd.dora[:rd.doraNext]
+		w.Hand = slices.Clone(rd.hands[who])
+		if w.IsTsumo() {
+			if len(w.Hand) > 0 {
+				w.Tile = w.Hand[len(w.Hand)-1]
+			}
+		} else {
+			if from != lastSeat {
+				return fmt.Errorf("ron from seat %d, but the last discard was by seat %d", from, lastSeat)
+			}
+			w.Tile = lastDiscard
+			w.Hand = append(w.Hand, lastDiscard)
+		}
+		for _, e := range rd.events {
+			if e.Type == engine.EventCall && e.Seat == who {
+				w.Melds = append(w.Melds, *e.Meld)
+			}
+		}
+
+		score, _ := info[3].(string)
+		if w.Fu, w.Points, err = parsePoints6(score); err != nil {
+			return err
+		}
+		for _, y := range info[4:] {
+			s, _ := y.(string)
+			yh, err := parseYaku6(s)
+			if err != nil {
+				return err
+			}
+			w.Yaku = append(w.Yaku, yh)
+			w.Han += yh.Han
+		}
+		rd.emit(engine.Event{Type: engine.EventWin, Seat: who, Tile: w.Tile, Win: w})
+	}
+	return nil
+}
+
+var (
+	fuRe     = regexp.MustCompile(`(\d+)符`)
+	pointsRe = regexp.MustCompile(`(\d+)(?:-(\d+))?点(∀)?`)
+	yakuRe   = regexp.MustCompile(`^(.+)\((\d+)飜\)$|^(.+)\(役満\)$`)
+)
+
+// parsePoints6 reads strings like "30符3飜3900点", "満貫2000-4000点" or
+// "40符1300点∀". Tsumo payments are added up to the total.
+func parsePoints6(s string) (fu, points int, err error) {
+	if m := fuRe.FindStringSubmatch(s); m != nil {
+		fu, _ = strconv.Atoi(m[1])
+	}
+	m := pointsRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid points %q", s)
+	}
+	first, _ := strconv.Atoi(m[1])
+	switch {
+	case m[2] != "":
+		second, _ := strconv.Atoi(m[2])
+		points = 2*first + second
+	case m[3] != "":
+		points = 3 * first
+	default:
+		points = first
+	}
+	return fu, points, nil
+}
+
+func parseYaku6(s string) (engine.YakuHan, error) {
+	m := yakuRe.FindStringSubmatch(s)
+	if m == nil {
+		return engine.YakuHan{}, fmt.Errorf("invalid yaku %q", s)
+	}
+	name, han := m[1], 13
+	if m[3] != "" {
+		name = m[3]
+	} else {
+		han, _ = strconv.Atoi(m[2])
+	}
+
+	switch {
+	case strings.HasPrefix(name, engine.YakuSeatWind.Japanese()):
+		return engine.YakuHan{Yaku: engine.YakuSeatWind, Han: han}, nil
+	case strings.HasPrefix(name, engine.YakuRoundWind.Japanese()):
+		return engine.YakuHan{Yaku: engine.YakuRoundWind, Han: han}, nil
+	}
+	for y := engine.YakuMenzenTsumo; y <= engine.YakuAkaDora; y++ {
+		if y.Japanese() == name {
+			return engine.YakuHan{Yaku: y, Han: han}, nil
+		}
+	}
+	return engine.YakuHan{}, fmt.Errorf("unknown yaku %q", name)
+}
+
+func deltas6(v any) ([4]int, error) {
+	var out [4]int
+	list, ok := v.([]any)
+	if !ok || len(list) < 4 {
+		return out, fmt.Errorf("invalid score deltas %v", v)
+	}
+	for i := range 4 {
+		d, ok := asCode(list[i])
+		if !ok {
+			return out, fmt.Errorf("invalid score deltas %v", v)
+		}
+		out[i] = d
+	}
+	return out, nil
+}
+
+// asCode reads a JSON number as an int.
+func asCode(v any) (int, bool) {
+	f, ok := v.(float64)
+	return int(f), ok
+}
+
+func codesToTiles(codes []int) ([]engine.Tile, error) {
+	out := make([]engine.Tile, 0, len(codes))
+	for _, c := range codes {
+		t, err := TileFromCode(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,222 @@
+package tenhou
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestLoadJSON(t *testing.T) {
+	events, err := LoadJSON("testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+
+	want := []string{
+		"game_start",
+		"round_start",
+		"draw seat=1 7z",
+		"discard seat=1 6z",
+		"call seat=3 kan(6z6z6z6z)",
+		"dora 3s",
+		"draw seat=3 2z",
+		"discard seat=3 2z",
+		"draw seat=0 7m",
+		"discard seat=0 7m",
+		"draw seat=1 2z",
+		"discard seat=1 2z",
+		"draw seat=2 1z",
+		"riichi seat=2",
+		"discard seat=2 1z",
+		"riichi_accepted seat=2",
+		"draw seat=3 5z",
+		"discard seat=3 7p",
+		"call seat=0 chi(7p6p8p)",
+		"discard seat=0 5p",
+		"win",
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events:\n got  %q\n want %q", got, want)
+	}
+
+	if rules := events[0].Game.Rules; rules.Length != engine.GameEast || !rules.OpenTanyao {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+	if round := events[1].Round; round.Dealer != 1 || round.Number != 1 {
+		t.Errorf("unexpected round: %+v", round)
+	}
+	if m := events[4].Meld; m.From != 1 {
+		t.Errorf("kan From = %d, want 1", m.From)
+	}
+	if m := events[18].Meld; m.From != 3 || m.Called.String() != "7p" {
+		t.Errorf("chi From = %d, Called = %v", m.From, m.Called)
+	}
+
+	win := events[20].Win
+	if win.Seat != 2 || win.From != 0 || win.Tile.String() != "5p" {
+		t.Errorf("unexpected win: %+v", win)
+	}
+	var yaku []engine.Yaku
+	for _, y := range win.Yaku {
+		yaku = append(yaku, y.Yaku)
+	}
+	wantYaku := []engine.Yaku{engine.YakuRiichi, engine.YakuPinfu, engine.YakuTanyao}
+	if win.Points != 3900 || win.Fu != 30 || win.Han != 3 || !slices.Equal(yaku, wantYaku) {
+		t.Errorf("unexpected scoring: %+v", win)
+	}
+	if len(win.Hand) != 14 {
+		t.Errorf("reconstructed hand has %d tiles, want 14", len(win.Hand))
+	}
+}
+
+func TestLoadJSON_Replay(t *testing.T) {
+	events, err := LoadJSON("testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	last := len(events) - 1 // the win
+	g, err := engine.Replay(events, last)
+	if err != nil {
+		t.Fatalf("events do not replay: %v", err)
+	}
+	win := events[last].Win
+	held := append(slices.Clone(g.Round.Hands[win.Seat]), win.Tile)
+	for _, m := range g.Round.Melds[win.Seat] {
+		held = append(held, m.Tiles...)
+	}
+	sorted := func(tiles []engine.Tile) []string {
+		var out []string
+		for _, t := range tiles {
+			out = append(out, t.String())
+		}
+		slices.Sort(out)
+		return out
+	}
+	if !slices.Equal(sorted(held), sorted(win.Hand)) {
+		t.Errorf("seat %d holds %v, the log scores %v", win.Seat, held, win.Hand)
+	}
+	if _, err := engine.Replay(events, -1); err != nil {
+		t.Errorf("events do not replay: %v", err)
+	}
+}
+
+func TestWriteJSON_RoundTripMJLog(t *testing.T) {
+	original, err := LoadMJLog("testdata/sample.xml")
+	if err != nil {
+		t.Fatalf("LoadMJLog failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, original); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	for _, want := range []string{`"393939a39"`, `"p454545"`, `"r60"`, `"立直(1飜)"`, `"30符3飜3900点"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %s:\n%s", want, buf.String())
+		}
+	}
+
+	back, err := ParseJSON(&buf)
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v\n%s", err, buf.String())
+	}
+	if len(back) != len(original) {
+		t.Fatalf("got %d events back, want %d", len(back), len(original))
+	}
+	for i := range original {
+		if back[i].String() != original[i].String() || back[i].Tsumogiri != original[i].Tsumogiri {
+			t.Errorf("event %d: got %v, want %v", i, back[i], original[i])
+		}
+	}
+
+	wantWin, gotWin := original[17].Win, back[17].Win
+	if gotWin.Points != wantWin.Points || gotWin.Han != wantWin.Han || gotWin.Deltas != wantWin.Deltas {
+		t.Errorf("win: got %+v, want %+v", gotWin, wantWin)
+	}
+	if !sameTiles(gotWin.Hand, wantWin.Hand) {
+		t.Errorf("win hand: got %v, want %v", gotWin.Hand, wantWin.Hand)
+	}
+	if back[len(back)-1].End.Scores != original[len(original)-1].End.Scores {
+		t.Errorf("final scores differ")
+	}
+}
+
+func TestWriteJSON_RoundTripSample(t *testing.T) {
+	original, err := LoadJSON("testdata/sample.json")
+	if err != nil {
+		t.Fatalf("LoadJSON failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, original); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	back, err := ParseJSON(&buf)
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v\n%s", err, buf.String())
+	}
+	for i := range original {
+		if back[i].String() != original[i].String() {
+			t.Errorf("event %d: got %v, want %v", i, back[i], original[i])
+		}
+	}
+}
+
+func TestParsePoints6(t *testing.T) {
+	tests := []struct {
+		in     string
+		fu     int
+		points int
+	}{
+		{"30符3飜3900点", 30, 3900},
+		{"40符1300-2600点", 40, 5200},
+		{"20符700点∀", 20, 2100},
+		{"満貫2000-4000点", 0, 8000},
+		{"役満48000点", 0, 48000},
+	}
+	for _, tt := range tests {
+		fu, points, err := parsePoints6(tt.in)
+		if err != nil {
+			t.Fatalf("parsePoints6(%q) failed: %v", tt.in, err)
+		}
+		if fu != tt.fu || points != tt.points {
+			t.Errorf("parsePoints6(%q) = %d, %d, want %d, %d", tt.in, fu, points, tt.fu, tt.points)
+		}
+	}
+}
+
+func TestCallString(t *testing.T) {
+	tests := []string{"c275226", "p454545", "45p4545", "4545p45", "m46464646", "46m464646", "464646m46", "393939a39", "k15151515"}
+	for _, s := range tests {
+		m, err := meldFromCall(s, 0)
+		if err != nil {
+			t.Fatalf("meldFromCall(%q) failed: %v", s, err)
+		}
+		back, err := callString(0, m)
+		if err != nil {
+			t.Fatalf("callString(%v) failed: %v", m, err)
+		}
+		if back != s {
+			t.Errorf("callString(meldFromCall(%q)) = %q", s, back)
+		}
+	}
+
+	for _, s := range []string{"", "p45", "x454545", "45454545p", "c4545"} {
+		if _, err := meldFromCall(s, 0); err == nil {
+			t.Errorf("expected error for %q, got nil", s)
+		}
+	}
+}
+
+func sameTiles(a, b []engine.Tile) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,331 @@
+package tenhou
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// SaveJSON writes events to a tenhou.net/6 JSON log file.
+func SaveJSON(path string, events []engine.Event) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteJSON(f, events); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteJSON writes the events of one game as a tenhou.net/6 JSON log that
+// replay viewers can open.
+func WriteJSON(w io.Writer, events []engine.Event) error {
+	doc := log6{
+		Ver:   2.3,
+		Title: []string{"", ""},
+		Name:  []string{"", "", "", ""},
+		Rule:  rule6{Disp: "般南喰赤", Aka: 1},
+	}
+
+	var cur *roundOut
+	flush := func() error {
+		if cur == nil {
+			return nil
+		}
+		raw, err := cur.marshal()
+		if err != nil {
+			return err
+		}
+		doc.Log = append(doc.Log, raw)
+		cur = nil
+		return nil
+	}
+
+	for i, e := range events {
+		if cur == nil && e.Type != engine.EventGameStart && e.Type != engine.EventRoundStart && e.Type != engine.EventGameEnd {
+			return fmt.Errorf("event %d (%s) before the first round", i, e.Type)
+		}
+		switch e.Type {
+		case engine.EventGameStart:
+			doc.Name = e.Game.Players[:]
+			doc.Rule = dispFromRules(e.Game.Rules)
+		case engine.EventRoundStart:
+			if err := flush(); err != nil {
+				return err
+			}
+			cur = newRoundOut(e.Round)
+		case engine.EventDrawTile:
+			cur.takes[e.Seat] = append(cur.takes[e.Seat], TileCode(e.Tile))
+		case engine.EventRiichi:
+			cur.riichi[e.Seat] = true
+		case engine.EventDiscard:
+			code := TileCode(e.Tile)
+			if e.Tsumogiri {
+				code = codeTsumogiri
+			}
+			if cur.riichi[e.Seat] {
+				cur.discards[e.Seat] = append(cur.discards[e.Seat], "r"+strconv.Itoa(code))
+				cur.riichi[e.Seat] = false
+			} else {
+				cur.discards[e.Seat] = append(cur.discards[e.Seat], code)
+			}
+		case engine.EventCall:
+			s, err := callString(e.Seat, *e.Meld)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+			switch e.Meld.Kind {
+			case engine.MeldAnkan, engine.MeldShouminkan:
+				cur.discards[e.Seat] = append(cur.discards[e.Seat], s)
+			case engine.MeldKan:
+				cur.takes[e.Seat] = append(cur.takes[e.Seat], s)
+				cur.discards[e.Seat] = append(cur.discards[e.Seat], 0)
+			default:
+				cur.takes[e.Seat] = append(cur.takes[e.Seat], s)
+			}
+		case engine.EventDora:
+			cur.dora = append(cur.dora, TileCode(e.Tile))
+		case engine.EventWin:
+			cur.addWin(e.Win)
+		case engine.EventRyuukyoku:
+			cur.result = ryuukyokuResult6(e.Ryuukyoku)
+		case engine.EventGameEnd:
+			doc.Sc = make([]float64, 0, 8)
+			for _, s := range e.End.Scores {
+				doc.Sc = append(doc.Sc, float64(s), 0)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if len(doc.Log) == 0 {
+		return fmt.Errorf("no rounds to write")
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
+
+func dispFromRules(r engine.Rules) rule6 {
+	var b strings.Builder
+	b.WriteString("般")
+	if r.Length == engine.GameEast {
+		b.WriteString("東")
+	} else {
+		b.WriteString("南")
+	}
+	if r.OpenTanyao {
+		b.WriteString("喰")
+	}
+	aka := 0
+	if r.RedFivesMan+r.RedFivesPin+r.RedFivesSou > 0 {
+		b.WriteString("赤")
+		aka = 1
+	}
+	return rule6{Disp: b.String(), Aka: aka}
+}
+
+type roundOut struct {
+	start    *engine.RoundStart
+	takes    [4][]any
+	discards [4][]any
+	riichi   [4]bool
+	dora     []int
+	ura      []int
+	result   []any
+}
+
+func newRoundOut(rs *engine.RoundStart) *roundOut {
+	r := &roundOut{start: rs, dora: []int{TileCode(rs.DoraIndicator)}}
+	for i := range 4 {
+		// Empty lists must encode as [] rather than null.
+		r.takes[i] = []any{}
+		r.discards[i] = []any{}
+	}
+	return r
+}
+
+func (r *roundOut) marshal() ([]json.RawMessage, error) {
+	rs := r.start
+	header := []int{int(rs.Wind)*4 + rs.Number, rs.Honba, rs.RiichiSticks}
+	ura := r.ura
+	if ura == nil {
+		ura = []int{}
+	}
+	result := r.result
+	if result == nil {
+		return nil, fmt.Errorf("round %s%d has no result", rs.Wind, rs.Number+1)
+	}
+
+	parts := []any{header, rs.Scores[:], r.dora, ura}
+	for seat := range 4 {
+		haipai := make([]int, 0, len(rs.Hands[seat]))
+		for _, t := range rs.Hands[seat] {
+			haipai = append(haipai, TileCode(t))
+		}
+		parts = append(parts, haipai, r.takes[seat], r.discards[seat])
+	}
+	parts = append(parts, result)
+
+	out := make([]json.RawMessage, 0, len(parts))
+	for _, p := range parts {
+		raw, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, raw)
+	}
+	return out, nil
+}
+
+func (r *roundOut) addWin(w *engine.WinResult) {
+	if r.result == nil {
+		r.result = []any{"和了"}
+	}
+	if len(r.ura) == 0 {
+		for _, t := range w.UraIndicators {
+			r.ura = append(r.ura, TileCode(t))
+		}
+	}
+
+	info := []any{w.Seat, w.From, w.Seat, pointsString6(w, r.start)}
+	for _, y := range w.Yaku {
+		info = append(info, yakuString6(y, w.Seat, r.start))
+	}
+	r.result = append(r.result, w.Deltas[:], info)
+}
+
+func ryuukyokuResult6(res *engine.RyuukyokuResult) []any {
+	name := "流局"
+	for n, reason := range ryuukyokuNames {
+		if reason == res.Reason && reason != engine.RyuukyokuExhaustive {
+			name = n
+		}
+	}
+	if res.Reason == engine.RyuukyokuExhaustive {
+		tenpai := 0
+		for _, t := range res.Tenpai {
+			if t {
+				tenpai++
+			}
+		}
+		switch tenpai {
+		case 0:
+			name = "全員不聴"
+		case 4:
+			name = "全員聴牌"
+		}
+	}
+	if res.Deltas == [4]int{} && res.Reason != engine.RyuukyokuExhaustive {
+		return []any{name}
+	}
+	return []any{name, res.Deltas[:]}
+}
+
+// limitName returns the limit hand label, or "" below mangan.
+func limitName(han, fu int) string {
+	switch {
+	case han >= 13:
+		return "役満"
+	case han >= 11:
+		return "三倍満"
+	case han >= 8:
+		return "倍満"
+	case han >= 6:
+		return "跳満"
+	case han >= 5, han == 4 && fu >= 40, han == 3 && fu >= 70:
+		return "満貫"
+	default:
+		return ""
+	}
+}
+
+func pointsString6(w *engine.WinResult, rs *engine.RoundStart) string {
+	prefix := limitName(w.Han, w.Fu)
+	if prefix == "" {
+		prefix = fmt.Sprintf("%d符%d飜", w.Fu, w.Han)
+	}
+	switch {
+	case !w.IsTsumo():
+		return fmt.Sprintf("%s%d点", prefix, w.Points)
+	case w.Seat == rs.Dealer:
+		return fmt.Sprintf("%s%d点∀", prefix, w.Points/3)
+	default:
+		dealerPays := -w.Deltas[rs.Dealer] - rs.Honba*100
+		return fmt.Sprintf("%s%d-%d点", prefix, (w.Points-dealerPays)/2, dealerPays)
+	}
+}
+
+var windKanji = [4]string{"東", "南", "西", "北"}
+
+func yakuString6(y engine.YakuHan, seat int, rs *engine.RoundStart) string {
+	name := y.Yaku.Japanese()
+	switch y.Yaku {
+	case engine.YakuSeatWind:
+		name += " " + windKanji[engine.SeatWind(seat, rs.Dealer)]
+	case engine.YakuRoundWind:
+		name += " " + windKanji[rs.Wind%4]
+	}
+	if y.Yaku.IsYakuman() {
+		return name + "(役満)"
+	}
+	return fmt.Sprintf("%s(%d飜)", name, y.Han)
+}
+
+// callString encodes a meld in the tenhou.net/6 call notation; see
+// meldFromCall for the inverse.
+func callString(seat int, m engine.Meld) (string, error) {
+	wantLen := 3
+	if m.Kind.IsKan() {
+		wantLen = 4
+	}
+	if len(m.Tiles) != wantLen {
+		return "", fmt.Errorf("%s with %d tiles", m.Kind, len(m.Tiles))
+	}
+
+	var marker byte
+	for k, kind := range callKinds {
+		if kind == m.Kind {
+			marker = k
+		}
+	}
+	off := (m.From - seat + 4) % 4
+	pos := -1
+	for p, o := range callMarkers[marker] {
+		if o == off {
+			pos = p
+		}
+	}
+	if pos < 0 {
+		return "", fmt.Errorf("%s from seat %d cannot be called by seat %d", m.Kind, m.From, seat)
+	}
+
+	others := slices.Clone(m.Tiles)
+	if i := slices.Index(others, m.Called); i >= 0 {
+		others = slices.Delete(others, i, i+1)
+	} else {
+		others = others[1:]
+	}
+
+	var b strings.Builder
+	for i := 0; i <= len(others); i++ {
+		if i == pos {
+			b.WriteByte(marker)
+			b.WriteString(strconv.Itoa(TileCode(m.Called)))
+		}
+		if i < len(others) {
+			b.WriteString(strconv.Itoa(TileCode(others[i])))
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,55 @@
+package tenhou
+
+import (
+	"fmt"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+// kindsPerSuit is the number of ranks in a numbered suit.
+const kindsPerSuit = 9
+
+// akaFives returns the red fives of a Tenhou game with or without aka.
+func akaFives(aka bool) engine.RedFives {
+	if aka {
+		return engine.RedFives{1, 1, 1}
+	}
+	return engine.RedFives{}
+}
+
+// TileFromID converts a Tenhou tile id to a tile kind. Tenhou numbers the
+// 136 physical tiles 0–135 exactly like engine.TileID. With red fives
+// enabled the first copy of each five (16, 52, 88) is red.
+func TileFromID(id int, aka bool) (engine.Tile, error) {
+	if id < 0 || id >= engine.NumTileIDs {
+		return 0, fmt.Errorf("invalid tile id %d", id)
+	}
+	return engine.TileID(id).Tile(akaFives(aka)), nil
+}
+
+// tenhou.net/6 JSON logs use two-digit tile codes: 11–19 man, 21–29 pin,
+// 31–39 sou, 41–47 honors, and 51/52/53 for the red fives.
+const (
+	codeRedFiveBase = 51
+	codeTsumogiri   = 60
+)
+
+// TileFromCode converts a tenhou.net/6 tile code to a tile kind.
+func TileFromCode(code int) (engine.Tile, error) {
+	if code >= codeRedFiveBase && code <= codeRedFiveBase+2 {
+		return engine.NewRedFive(engine.Suit(code - codeRedFiveBase))
+	}
+	suit, rank := code/10-1, code%10
+	if suit < 0 || suit > 3 || rank == 0 {
+		return 0, fmt.Errorf("invalid tile code %d", code)
+	}
+	return engine.NewTile(engine.Suit(suit), rank)
+}
+
+// TileCode converts a tile kind to its tenhou.net/6 code.
+func TileCode(t engine.Tile) int {
+	if t.IsRed() {
+		return codeRedFiveBase + int(t.Suit())
+	}
+	return (int(t.Suit())+1)*10 + t.Rank()
+}
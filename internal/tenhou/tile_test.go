@@ -0,0 +1,87 @@
+package tenhou
+
+import (
+	"testing"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+)
+
+func TestTileFromID(t *testing.T) {
+	tests := []struct {
+		id   int
+		aka  bool
+		want string
+	}{
+		{0, true, "1m"},
+		{3, true, "1m"},
+		{16, true, "0m"},
+		{16, false, "5m"},
+		{17, true, "5m"},
+		{52, true, "0p"},
+		{88, true, "0s"},
+		{107, true, "9s"},
+		{108, true, "1z"},
+		{124, true, "5z"},
+		{135, true, "7z"},
+	}
+	for _, tt := range tests {
+		got, err := TileFromID(tt.id, tt.aka)
+		if err != nil {
+			t.Fatalf("TileFromID(%d) failed: %v", tt.id, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("TileFromID(%d, %v) = %v, want %v", tt.id, tt.aka, got, tt.want)
+		}
+	}
+
+	for _, id := range []int{-1, 136} {
+		if _, err := TileFromID(id, true); err == nil {
+			t.Errorf("expected error for id %d, got nil", id)
+		}
+	}
+
+	// Every kind has exactly four ids.
+	counts := make(map[engine.Tile]int)
+	for id := 0; id < engine.NumTileIDs; id++ {
+		tile, _ := TileFromID(id, false)
+		counts[tile]++
+	}
+	if len(counts) != 34 {
+		t.Errorf("expected 34 kinds, got %d", len(counts))
+	}
+}
+
+func TestTileCode(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{11, "1m"},
+		{19, "9m"},
+		{25, "5p"},
+		{39, "9s"},
+		{41, "1z"},
+		{47, "7z"},
+		{51, "0m"},
+		{52, "0p"},
+		{53, "0s"},
+	}
+	for _, tt := range tests {
+		got, err := TileFromCode(tt.code)
+		if err != nil {
+			t.Fatalf("TileFromCode(%d) failed: %v", tt.code, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("TileFromCode(%d) = %v, want %s", tt.code, got, tt.want)
+		}
+		if back := TileCode(got); back != tt.code {
+			t.Errorf("TileCode(%v) = %d, want %d", got, back, tt.code)
+		}
+	}
+
+	for _, code := range []int{0, 10, 20, 48, 54, 60} {
+		if _, err := TileFromCode(code); err == nil {
+			t.Errorf("expected error for code %d, got nil", code)
+		}
+	}
+}
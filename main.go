@@ -1,14 +1,54 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/analysis"
 	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/render"
 )
 
+// wallRow is the number of tiles printed per line: one side of the wall,
+// 17 stacks of two.
+const wallRow = 34
+
 func main() {
-	debugTiles()
+	if len(os.Args) > 1 {
+		commands := map[string]func([]string) error{"simulate": simulate, "export": export}
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	wide := flag.Bool("wide", false, "put a space after each tile glyph")
+	color := flag.Bool("color", false, "highlight red fives with ANSI colors")
+	hand := flag.String("hand", "", "list the discards of a hand in extended notation, best first")
+	danger := flag.String("danger", "", "rank the tiles in a seat's hand by deal-in danger, from this event log")
+	at := flag.Int("at", -1, "with -danger, replay only this many events")
+	seat := flag.Int("seat", 0, "with -danger, the seat to rank tiles for")
+	flag.Parse()
+	opts := render.Options{Wide: *wide, Color: *color}
+
+	if *hand != "" {
+		if err := printDiscards(*hand); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *danger != "" {
+		if err := printDanger(*danger, *at, *seat); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	debugTiles(opts)
 	rules := engine.DefaultRules()
 	wall, err := engine.BuildWall(rules)
 	if err != nil {
@@ -16,26 +56,83 @@ func main() {
 	}
 
 	fmt.Println("Unshuffled wall size:", len(wall))
+	printWall(wall, opts)
 
-	for i := 0; i < len(wall); i++ {
-		fmt.Printf("%d: %s\n", i, wall[i].String())
-	}
-
-	fmt.Println("Shuffled wall size:", len(wall))
 	wall, err = engine.ShuffleWall(wall)
 	if err != nil {
 		log.Fatal(err)
 	}
-	for i := 0; i < len(wall); i++ {
-		fmt.Printf("%d: %s\n", i, wall[i].String())
-	}
+	fmt.Println("Shuffled wall size:", len(wall))
+	printWall(wall, opts)
+}
 
+func printWall(wall []engine.Tile, opts render.Options) {
+	for i := 0; i < len(wall); i += wallRow {
+		row := wall[i:min(i+wallRow, len(wall))]
+		fmt.Printf("%3d: %s\n", i, opts.Tiles(row))
+	}
 }
 
-func debugTiles() {
+func debugTiles(opts render.Options) {
 	t, _ := engine.ParseTile("1m")
-	fmt.Println(t, t.Suit(), t.Rank(), t.IsTerminal(), t.IsHonor(), t.String())
+	fmt.Println(opts.Tile(t), t.Suit(), t.Rank(), t.IsTerminal(), t.IsHonor(), t.String())
 
 	h, _ := engine.ParseTile("E")
-	fmt.Println(h, h.Suit(), h.Rank(), h.IsHonor(), h.IsWind(), h.String())
+	fmt.Println(opts.Tile(h), h.Suit(), h.Rank(), h.IsHonor(), h.IsWind(), h.String())
+}
+
+// printDiscards lists every discard of a hand with the tiles it accepts
+// and what the next draw is likely to bring.
+// Melds and dora indicators count as seen tiles.
+func printDiscards(notation string) error {
+	h, err := engine.ParseHand(notation)
+	if err != nil {
+		return err
+	}
+	if h.Size() != 14 {
+		return fmt.Errorf("hand has %d tiles, want 14", h.Size())
+	}
+	hand, err := engine.CountTiles(h.Tiles())
+	if err != nil {
+		return err
+	}
+	shown := h.Dora
+	for _, m := range h.Melds {
+		shown = append(shown, m.Tiles...)
+	}
+	seen, err := engine.CountTiles(shown)
+	if err != nil {
+		return err
+	}
+	for _, o := range analysis.LookaheadDiscards(&hand, &seen) {
+		fmt.Println(o)
+	}
+	return nil
+}
+
+// printDanger replays an event log and lists the tiles in seat's hand,
+// most dangerous first, with the reasons for each rating.
+func printDanger(path string, n, seat int) error {
+	if seat < 0 || seat > 3 {
+		return fmt.Errorf("invalid seat %d in -seat", seat)
+	}
+	events, err := engine.LoadEventLog(path)
+	if err != nil {
+		return err
+	}
+	g, err := engine.Replay(events, n)
+	if err != nil {
+		return err
+	}
+	if g.Round == nil {
+		return fmt.Errorf("no round in progress after %d events", n)
+	}
+	ranking, err := analysis.DangerRanking(g.Round, seat)
+	if err != nil {
+		return err
+	}
+	for _, d := range ranking {
+		fmt.Println(d)
+	}
+	return nil
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/bot"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/engine"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/mjai"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/sim"
+	"github.com/KevinHaeusler/go-mahjong-engine/internal/table"
+)
+
+// simulate runs the simulate command: self-play between four entrants,
+// reporting their statistics.
+func simulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := fs.Int("games", 1000, "number of games to play")
+	workers := fs.Int("workers", 0, "games played at once; 0 uses every CPU")
+	seed := fs.Uint64("seed", 1, "seed the walls of every game are drawn from")
+	players := fs.String("players", "bot,bot,bot,bot", "four comma-separated entrants: bot, exec:COMMAND for a subprocess player, or mjai:COMMAND for an mjai bot")
+	rulesPath := fs.String("rules", "", "rules file (JSON, YAML or TOML); default rules if empty")
+	timeout := fs.Duration("timeout", 0, "time limit per decision; 0 for none")
+	format := fs.String("format", "text", "output format: text, csv or json")
+	out := fs.String("o", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	write, ok := map[string]func(io.Writer, *sim.Report) error{
+		"text": sim.WriteText,
+		"csv":  sim.WriteCSV,
+		"json": sim.WriteJSON,
+	}[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	rules := engine.DefaultRules()
+	if *rulesPath != "" {
+		var err error
+		if rules, err = engine.LoadRules(*rulesPath); err != nil {
+			return err
+		}
+	}
+	specs := strings.Split(*players, ",")
+	if len(specs) != 4 {
+		return fmt.Errorf("want 4 players, got %d", len(specs))
+	}
+	var entrants [4]sim.Entrant
+	for i, spec := range specs {
+		e, err := entrant(strings.TrimSpace(spec))
+		if err != nil {
+			return err
+		}
+		entrants[i] = e
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	rep, err := sim.Run(ctx, entrants, sim.Config{
+		Games:   *games,
+		Workers: *workers,
+		Seed:    *seed,
+		Rules:   rules,
+		Timeout: *timeout,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d games in %s\n", rep.Games, time.Since(start).Round(time.Millisecond))
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return write(w, rep)
+}
+
+// entrant makes the entrant a -players spec names.
+func entrant(spec string) (sim.Entrant, error) {
+	switch {
+	case spec == "bot":
+		return sim.Entrant{Name: spec, New: func() (table.Player, error) {
+			return table.Local(bot.Bot{}), nil
+		}}, nil
+	case strings.HasPrefix(spec, "exec:"):
+		argv := strings.Fields(strings.TrimPrefix(spec, "exec:"))
+		if len(argv) == 0 {
+			return sim.Entrant{}, fmt.Errorf("no command in %q", spec)
+		}
+		return sim.Entrant{Name: spec, New: func() (table.Player, error) {
+			return table.StartProcess(argv[0], argv[1:]...)
+		}}, nil
+	case strings.HasPrefix(spec, "mjai:"):
+		argv := strings.Fields(strings.TrimPrefix(spec, "mjai:"))
+		if len(argv) == 0 {
+			return sim.Entrant{}, fmt.Errorf("no command in %q", spec)
+		}
+		return sim.Entrant{Name: spec, New: func() (table.Player, error) {
+			return mjai.StartPlayer(argv[0], argv[1:]...)
+		}}, nil
+	default:
+		return sim.Entrant{}, fmt.Errorf("unknown player %q", spec)
+	}
+}